@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Attach
+// =============================================================================
+
+// detachGrace is how long a closed attach window waits for the daemon to
+// detach before dropping the connection.
+const detachGrace = 2 * time.Second
+
+// showAttachWindow attaches to the main process of the container. TTY
+// containers get a full terminal; without a TTY nothing echoes or edits
+// input, so lines are typed into an entry and sent whole.
+func showAttachWindow(id string) {
+	if id == "" {
+		return
+	}
+	info, err := dockerService.InspectContainer(context.Background(), id)
+	if err != nil {
+		showActionError("Error attaching to container:", err)
+		return
+	}
+	name := trimName(info.Name)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sess, err := dockerService.Attach(ctx, id, dashboard.DefaultDetachKeys)
+	if err != nil {
+		cancel()
+		log.Println("Error attaching to container:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+
+	win := appInstance.NewWindow("Attach: " + name)
+	term := newTerminal()
+	status := widget.NewLabel("Attached")
+	if sess.Stdin() {
+		status.SetText("Attached. Detach with " + dashboard.DefaultDetachKeys + " or the Detach button.")
+	}
+	detachBtn := widget.NewButton("Detach", func() {
+		if err := sess.Detach(); err != nil {
+			// Without stdin there is nothing to detach; just disconnect.
+			sess.Close()
+		}
+	})
+	statusRow := container.NewBorder(nil, nil, nil, detachBtn, status)
+
+	stdout, stderr := io.Writer(term), io.Writer(term)
+	var bottom fyne.CanvasObject = statusRow
+	if sess.TTY() {
+		if sess.Stdin() {
+			term.SetInput(sess)
+		}
+		term.SetOnResize(func(rows, cols int) {
+			if err := sess.Resize(ctx, uint(rows), uint(cols)); err != nil && ctx.Err() == nil {
+				log.Println("Error resizing attached terminal:", err)
+			}
+		})
+	} else {
+		stdout = &crlfWriter{w: term}
+		stderr = &crlfWriter{w: term, prefix: "\x1b[31m", suffix: "\x1b[0m"}
+		input := widget.NewEntry()
+		input.SetPlaceHolder("Type a line and press Enter to send it")
+		input.OnSubmitted = func(line string) {
+			if _, err := sess.Write([]byte(line + "\n")); err != nil {
+				status.SetText("Error sending input: " + err.Error())
+				return
+			}
+			input.SetText("")
+		}
+		eofBtn := widget.NewButton("Send EOF", func() {
+			if err := sess.CloseInput(); err != nil {
+				status.SetText("Error closing input: " + err.Error())
+			}
+		})
+		if !sess.Stdin() {
+			input.SetPlaceHolder("stdin is not open (the container was not started with -i)")
+			input.Disable()
+			eofBtn.Disable()
+		}
+		bottom = container.NewVBox(container.NewBorder(nil, nil, nil, eofBtn, input), statusRow)
+	}
+
+	win.SetContent(container.NewBorder(nil, bottom, nil, nil, term))
+	win.Resize(fyne.NewSize(900, 550))
+	win.SetOnClosed(func() {
+		term.Close()
+		if sess.Detach() == nil {
+			time.AfterFunc(detachGrace, func() {
+				cancel()
+				sess.Close()
+			})
+			return
+		}
+		cancel()
+		sess.Close()
+	})
+	win.Show()
+	win.Canvas().Focus(term)
+	if sess.TTY() {
+		rows, cols := term.Dimensions()
+		sess.Resize(ctx, uint(rows), uint(cols))
+	}
+
+	go func() {
+		err := sess.Copy(stdout, stderr)
+		if ctx.Err() != nil {
+			return
+		}
+		detachBtn.Disable()
+		if err != nil {
+			status.SetText("Disconnected: " + err.Error())
+			return
+		}
+		info, err := dockerService.InspectContainer(context.Background(), id)
+		switch {
+		case err != nil:
+			status.SetText("Session ended")
+		case info.State != nil && info.State.Running:
+			status.SetText("Detached; the container is still running")
+		case info.State != nil:
+			status.SetText(fmt.Sprintf("Container exited with code %d", info.State.ExitCode))
+		}
+	}()
+}
+
+// crlfWriter turns bare line feeds into CR LF for output that did not pass
+// through a TTY, optionally wrapping each write in escape sequences.
+type crlfWriter struct {
+	w              io.Writer
+	prefix, suffix string
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	out := bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))
+	if c.prefix != "" {
+		out = append(append([]byte(c.prefix), out...), c.suffix...)
+	}
+	if _, err := c.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Image Build
+// =============================================================================
+
+// buildOutputLines is how much build output the window keeps.
+const buildOutputLines = 500
+
+// showBuildImageDialog builds an image from a local context directory,
+// streaming the output, and refreshes the images list when done.
+func showBuildImageDialog(list *resourceTable[dashboard.Image]) {
+	win := appInstance.NewWindow("Build Image")
+	contextEntry := widget.NewEntry()
+	contextEntry.SetPlaceHolder("Directory sent to the daemon")
+	dockerfileEntry := widget.NewEntry()
+	dockerfileEntry.SetPlaceHolder("Dockerfile (relative to the context, or absolute)")
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("e.g. myapp:latest, registry.example.com/myapp:1.0")
+	targetEntry := widget.NewEntry()
+	targetEntry.SetPlaceHolder("Optional: stage to stop at")
+	buildArgs := newRowList("name", "value")
+	labels := newRowList("key", "value")
+	noCacheCheck := widget.NewCheck("No cache", nil)
+	pullCheck := widget.NewCheck("Always pull base images", nil)
+
+	contextBrowse := widget.NewButton("Browse...", func() {
+		dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if dir != nil {
+				contextEntry.SetText(dir.Path())
+			}
+		}, win).Show()
+	})
+	dockerfileBrowse := widget.NewButton("Browse...", func() {
+		dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if r == nil {
+				return
+			}
+			r.Close()
+			path := r.URI().Path()
+			// Keep paths inside the context relative, as docker build -f does.
+			if rel, err := filepath.Rel(contextEntry.Text, path); err == nil && contextEntry.Text != "" && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+			dockerfileEntry.SetText(path)
+		}, win).Show()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Context", container.NewBorder(nil, nil, nil, contextBrowse, contextEntry)),
+		widget.NewFormItem("Dockerfile", container.NewBorder(nil, nil, nil, dockerfileBrowse, dockerfileEntry)),
+		widget.NewFormItem("Tags", tagsEntry),
+		widget.NewFormItem("Target", targetEntry),
+		widget.NewFormItem("", container.NewHBox(noCacheCheck, pullCheck)),
+	)
+	options := widget.NewAccordion(
+		widget.NewAccordionItem("Build Args", container.NewVBox(
+			widget.NewButton("Add Build Arg", func() { buildArgs.add() }), buildArgs.box)),
+		widget.NewAccordionItem("Labels", container.NewVBox(
+			widget.NewButton("Add Label", func() { labels.add() }), labels.box)),
+	)
+
+	stepBar := widget.NewProgressBar()
+	stepLabel := widget.NewLabel("")
+	stepLabel.Truncation = fyne.TextTruncateEllipsis
+	output := widget.NewTextGrid()
+	outputScroll := container.NewScroll(output)
+	var lines []string
+	addOutput := func(line string) {
+		lines = append(lines, line)
+		if len(lines) > buildOutputLines {
+			lines = lines[len(lines)-buildOutputLines:]
+		}
+		output.SetText(strings.Join(lines, "\n"))
+		outputScroll.ScrollToBottom()
+	}
+	summary := widget.NewLabel("")
+	summary.Wrapping = fyne.TextWrapWord
+	summary.Hide()
+
+	buildBtn := widget.NewButton("Build", nil)
+	buildBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("Cancel", nil)
+	cancelBtn.Disable()
+	closeBtn := widget.NewButton("Close", func() { win.Close() })
+
+	var cancel context.CancelFunc
+	win.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	cancelBtn.OnTapped = func() {
+		if cancel != nil {
+			cancel()
+		}
+	}
+	buildBtn.OnTapped = func() {
+		spec := dashboard.BuildSpec{
+			ContextDir: strings.TrimSpace(contextEntry.Text),
+			Dockerfile: strings.TrimSpace(dockerfileEntry.Text),
+			Tags:       splitList(tagsEntry.Text),
+			BuildArgs:  gatherLabels(buildArgs),
+			Target:     strings.TrimSpace(targetEntry.Text),
+			Labels:     gatherLabels(labels),
+			NoCache:    noCacheCheck.Checked,
+			Pull:       pullCheck.Checked,
+		}
+		if spec.ContextDir == "" {
+			dialog.ShowError(errors.New("choose a context directory"), win)
+			return
+		}
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		buildBtn.Disable()
+		cancelBtn.Enable()
+		lines = nil
+		output.SetText("")
+		summary.Hide()
+		stepBar.SetValue(0)
+		stepLabel.SetText("Sending build context...")
+
+		go func() {
+			defer cancel()
+			id, err := dockerService.BuildImage(ctx, spec, func(p dashboard.BuildProgress) {
+				addOutput(p.Line)
+				if p.Steps > 0 {
+					stepBar.SetValue(float64(p.Step-1) / float64(p.Steps))
+					stepLabel.SetText(fmt.Sprintf("Step %d/%d: %s", p.Step, p.Steps, p.Instruction))
+				}
+			})
+			buildBtn.Enable()
+			cancelBtn.Disable()
+			if err != nil {
+				showBuildFailure(win, err, stepLabel, summary)
+				return
+			}
+			stepBar.SetValue(1)
+			text := "Built " + dashboard.Image{ID: id}.ShortID()
+			if len(spec.Tags) > 0 {
+				text += " as " + strings.Join(spec.Tags, ", ")
+			}
+			stepLabel.SetText(text)
+			updateImagesList(dockerService, list)
+		}()
+	}
+
+	buttons := container.NewHBox(buildBtn, cancelBtn, closeBtn)
+	top := container.NewVBox(form, options, buttons, stepBar, stepLabel)
+	win.SetContent(container.NewBorder(top, summary, nil, nil, outputScroll))
+	win.Resize(fyne.NewSize(800, 750))
+	win.Show()
+}
+
+// showBuildFailure reports a failed build. For a failing instruction the
+// summary names the step and repeats the end of its output.
+func showBuildFailure(win fyne.Window, err error, stepLabel, summary *widget.Label) {
+	if errors.Is(err, context.Canceled) {
+		stepLabel.SetText("Build cancelled")
+		return
+	}
+	log.Println("Error building image:", err)
+	stepLabel.SetText("Build failed")
+	var buildErr *dashboard.BuildError
+	if !errors.As(err, &buildErr) {
+		dialog.ShowError(err, win)
+		return
+	}
+	text := "Error: " + buildErr.Message
+	if buildErr.Step > 0 {
+		text = fmt.Sprintf("Failed at step %d/%d: %s\n%s", buildErr.Step, buildErr.Steps, buildErr.Instruction, text)
+	}
+	if len(buildErr.Output) > 0 {
+		text += "\n\nLast output of the step:\n" + strings.Join(buildErr.Output, "\n")
+	}
+	summary.SetText(text)
+	summary.Show()
+}
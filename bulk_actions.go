@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Multi-select and Bulk Actions
+// =============================================================================
+
+// bulkTarget is one resource of a bulk action: the ID the action takes and
+// the name failures are reported under.
+type bulkTarget struct {
+	id   string
+	name string
+}
+
+// runBulkAction applies run to the targets on a bounded worker pool, then
+// reports every failure, by name, in one dialog. done, if not nil, runs
+// afterwards to refresh the list.
+func runBulkAction(label string, targets []bulkTarget, run func(ctx context.Context, id string) error, done func()) {
+	errs := dashboard.RunBulk(context.Background(), len(targets), dashboard.BulkWorkers, func(ctx context.Context, i int) error {
+		return run(ctx, targets[i].id)
+	})
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Error running %s on %s: %v", strings.ToLower(label), targets[i].name, err)
+			failures = append(failures, targets[i].name+": "+err.Error())
+		}
+	}
+	if done != nil {
+		done()
+	}
+	if len(failures) > 0 {
+		showBulkFailures(label, len(targets), failures)
+	}
+}
+
+func showBulkFailures(label string, total int, failures []string) {
+	text := widget.NewLabel(strings.Join(failures, "\n"))
+	text.Wrapping = fyne.TextWrapWord
+	title := fmt.Sprintf("%s failed for %d of %d", label, len(failures), total)
+	d := dialog.NewCustom(title, "Close", container.NewVScroll(text), mainWindow)
+	d.Resize(fyne.NewSize(550, 350))
+	d.Show()
+}
+
+// confirmBulkRemove asks message before applying a bulk removal.
+func confirmBulkRemove(message string, apply func()) {
+	dialog.ShowConfirm("Remove", message, func(ok bool) {
+		if ok {
+			apply()
+		}
+	}, mainWindow)
+}
+
+// newBulkRemoveButton returns a button removing the checked rows of t.
+func newBulkRemoveButton[T any](t *resourceTable[T], what string, target func(T) bulkTarget, remove func(ctx context.Context, id string) error, done func()) *widget.Button {
+	return widget.NewButton("Remove Checked", func() {
+		items := t.selected()
+		if len(items) == 0 {
+			return
+		}
+		targets := make([]bulkTarget, len(items))
+		for i, item := range items {
+			targets[i] = target(item)
+		}
+		confirmBulkRemove(fmt.Sprintf("Remove %d %s?", len(targets), what), func() {
+			go runBulkAction("Remove", targets, remove, done)
+		})
+	})
+}
+
+// containerActions are the actions offered for several containers at once.
+// skip leaves out containers already in the target state.
+var containerActions = []struct {
+	label string
+	run   func(ctx context.Context, id string) error
+	skip  func(c dashboard.Container) bool
+}{
+	{"Start", func(ctx context.Context, id string) error { return dockerService.StartContainer(ctx, id) },
+		func(c dashboard.Container) bool { return c.State == "running" }},
+	{"Stop", func(ctx context.Context, id string) error { return dockerService.StopContainer(ctx, id) },
+		func(c dashboard.Container) bool { return c.State != "running" }},
+	{"Restart", func(ctx context.Context, id string) error { return dockerService.RestartContainer(ctx, id) }, nil},
+	{"Remove", func(ctx context.Context, id string) error { return dockerService.RemoveContainer(ctx, id) }, nil},
+}
+
+// newContainerActionButtons returns a Start/Stop/Restart/Remove button for
+// the containers returned by targets, labelled "<action> suffix". describe
+// names the n containers in the removal confirmation.
+func newContainerActionButtons(suffix string, targets func() []dashboard.Container, describe func(n int) string, done func()) []fyne.CanvasObject {
+	var buttons []fyne.CanvasObject
+	for _, action := range containerActions {
+		buttons = append(buttons, widget.NewButton(action.label+" "+suffix, func() {
+			var chosen []bulkTarget
+			for _, c := range targets() {
+				if action.skip == nil || !action.skip(c) {
+					chosen = append(chosen, bulkTarget{id: c.ID, name: c.Name})
+				}
+			}
+			if len(chosen) == 0 {
+				return
+			}
+			apply := func() {
+				go runBulkAction(action.label, chosen, action.run, done)
+			}
+			if action.label != "Remove" {
+				apply()
+				return
+			}
+			confirmBulkRemove(fmt.Sprintf("Remove %s, stopping them first?", describe(len(chosen))), apply)
+		}))
+	}
+	return buttons
+}
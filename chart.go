@@ -0,0 +1,171 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Time-Series Chart Widget
+// =============================================================================
+
+// maxChartSegments caps the number of line segments drawn per series; longer
+// histories are thinned out to keep redraws cheap.
+const maxChartSegments = 300
+
+// chartSeries is one line of a chart, reading its value from each sample.
+type chartSeries struct {
+	name  string
+	color color.Color
+	value func(dashboard.StatsSample) float64
+}
+
+// timeSeriesChart draws stats samples of the last `window` as lines, scaled
+// to the largest value in view. The newest sample sits at the right edge.
+//
+// The stats stream sets the data from its goroutine while the UI lays the
+// chart out, so samples and window are only touched with mu held.
+type timeSeriesChart struct {
+	widget.BaseWidget
+
+	series []chartSeries
+	format func(float64) string
+
+	mu      sync.Mutex
+	samples []dashboard.StatsSample
+	window  time.Duration
+}
+
+func newTimeSeriesChart(format func(float64) string, series ...chartSeries) *timeSeriesChart {
+	c := &timeSeriesChart{series: series, format: format, window: time.Minute}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetData replaces the samples (oldest first) and visible window, then redraws.
+// The chart keeps samples, so the caller must not change them afterwards.
+func (c *timeSeriesChart) SetData(samples []dashboard.StatsSample, window time.Duration) {
+	c.mu.Lock()
+	c.samples = samples
+	c.window = window
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+// data returns the samples and window to draw.
+func (c *timeSeriesChart) data() ([]dashboard.StatsSample, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.samples, c.window
+}
+
+func (c *timeSeriesChart) CreateRenderer() fyne.WidgetRenderer {
+	bg := canvas.NewRectangle(color.RGBA{245, 245, 247, 255})
+	r := &chartRenderer{chart: c, bg: bg}
+	r.rebuild()
+	return r
+}
+
+// chartRenderer is rebuilt both on layout and on refreshes from the stats
+// stream. Each rebuild makes new objects and swaps them in under mu, so
+// objects being drawn are never changed.
+type chartRenderer struct {
+	chart *timeSeriesChart
+	bg    *canvas.Rectangle
+
+	mu      sync.Mutex
+	lines   []fyne.CanvasObject
+	maxText *canvas.Text
+}
+
+func (r *chartRenderer) Destroy() {}
+
+func (r *chartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 100)
+}
+
+func (r *chartRenderer) Layout(size fyne.Size) {
+	r.bg.Resize(size)
+	r.rebuild()
+}
+
+func (r *chartRenderer) Refresh() {
+	r.rebuild()
+	canvas.Refresh(r.chart)
+}
+
+func (r *chartRenderer) Objects() []fyne.CanvasObject {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	objects := []fyne.CanvasObject{r.bg}
+	objects = append(objects, r.lines...)
+	return append(objects, r.maxText)
+}
+
+// rebuild recreates the line segments for the current size and data.
+func (r *chartRenderer) rebuild() {
+	c := r.chart
+	size := c.Size()
+	samples, window := c.data()
+	var lines []fyne.CanvasObject
+	maxText := canvas.NewText("", theme.Color(theme.ColorNameDisabled))
+	maxText.TextSize = 11
+	defer func() {
+		r.mu.Lock()
+		r.lines, r.maxText = lines, maxText
+		r.mu.Unlock()
+	}()
+	if len(samples) == 0 || size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+
+	end := samples[len(samples)-1].Time
+	start := end.Add(-window)
+	first := 0
+	for first < len(samples) && samples[first].Time.Before(start) {
+		first++
+	}
+	visible := samples[first:]
+
+	maxVal := 0.0
+	for _, s := range c.series {
+		for _, sample := range visible {
+			maxVal = math.Max(maxVal, s.value(sample))
+		}
+	}
+	if maxVal <= 0 {
+		maxVal = 1
+	}
+	maxVal *= 1.1
+	maxText.Text = c.format(maxVal)
+	maxText.Move(fyne.NewPos(4, 2))
+
+	step := 1
+	if len(visible) > maxChartSegments {
+		step = (len(visible) + maxChartSegments - 1) / maxChartSegments
+	}
+	point := func(sample dashboard.StatsSample, v float64) fyne.Position {
+		x := float32(sample.Time.Sub(start).Seconds()/window.Seconds()) * size.Width
+		y := size.Height - float32(v/maxVal)*size.Height
+		return fyne.NewPos(x, y)
+	}
+	for _, s := range c.series {
+		for i := step; i < len(visible); i += step {
+			prev, cur := visible[i-step], visible[i]
+			line := canvas.NewLine(s.color)
+			line.StrokeWidth = 1.5
+			line.Position1 = point(prev, s.value(prev))
+			line.Position2 = point(cur, s.value(cur))
+			lines = append(lines, line)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/test"
+
+	"sprint/dashboard"
+)
+
+func chartSamples(n int, end time.Time) []dashboard.StatsSample {
+	samples := make([]dashboard.StatsSample, n)
+	for i := range samples {
+		samples[i] = dashboard.StatsSample{Time: end.Add(time.Duration(i-n+1) * time.Second), MemoryUsage: uint64(i)}
+	}
+	return samples
+}
+
+func countLines(objects []fyne.CanvasObject) int {
+	n := 0
+	for _, o := range objects {
+		if _, ok := o.(*canvas.Line); ok {
+			n++
+		}
+	}
+	return n
+}
+
+func TestTimeSeriesChartWindow(t *testing.T) {
+	chart := newTimeSeriesChart(formatBytes,
+		chartSeries{"usage", chartBlue, func(s dashboard.StatsSample) float64 { return float64(s.MemoryUsage) }},
+		chartSeries{"limit", chartOrange, func(dashboard.StatsSample) float64 { return 100 }})
+	chart.Resize(fyne.NewSize(300, 100))
+	r := test.WidgetRenderer(chart)
+
+	// 2 minutes of samples, a minute in view: 61 samples, 60 segments per
+	// series.
+	chart.SetData(chartSamples(121, time.Now()), time.Minute)
+	if got := countLines(r.Objects()); got != 120 {
+		t.Errorf("%d segments, want 120", got)
+	}
+	// Long histories are thinned out.
+	chart.SetData(chartSamples(3601, time.Now()), time.Hour)
+	if got := countLines(r.Objects()); got > 2*maxChartSegments {
+		t.Errorf("%d segments, want at most %d", got, 2*maxChartSegments)
+	}
+	chart.SetData(nil, time.Minute)
+	if got := countLines(r.Objects()); got != 0 {
+		t.Errorf("%d segments without data", got)
+	}
+}
+
+func TestTimeSeriesChartConcurrentUpdates(t *testing.T) {
+	chart := newTimeSeriesChart(formatPercent,
+		chartSeries{"cpu", chartBlue, func(s dashboard.StatsSample) float64 { return s.CPUHostPercent }})
+	r := test.WidgetRenderer(chart)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	// The stats stream sets data while the UI lays the chart out.
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			chart.SetData(chartSamples(10+i, time.Now()), time.Minute)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		r.Layout(fyne.NewSize(float32(200+i), 100))
+		r.Objects()
+	}
+	wg.Wait()
+}
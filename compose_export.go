@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+// =============================================================================
+// Compose Export
+// =============================================================================
+
+// showComposeExportPicker asks which containers to export as a compose
+// file, starting with the ids checked.
+func showComposeExportPicker(ids []string) {
+	containers, err := dockerService.ListContainers(context.Background(), filters.NewArgs())
+	if err != nil {
+		log.Println("Error fetching containers:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	labels := make([]string, len(containers))
+	var checked []string
+	for i, c := range containers {
+		labels[i] = fmt.Sprintf("%s (%s, %s)", c.Name, c.Image, c.State)
+		if slices.Contains(ids, c.ID) {
+			checked = append(checked, labels[i])
+		}
+	}
+	group := widget.NewCheckGroup(labels, nil)
+	group.SetSelected(checked)
+
+	d := dialog.NewCustomConfirm("Export as compose.yaml", "Export", "Cancel", container.NewVScroll(group), func(ok bool) {
+		if !ok || len(group.Selected) == 0 {
+			return
+		}
+		var selected []string
+		for i, c := range containers {
+			if slices.Contains(group.Selected, labels[i]) {
+				selected = append(selected, c.ID)
+			}
+		}
+		data, err := dockerService.ExportCompose(context.Background(), selected)
+		if err != nil {
+			showActionError("Error exporting compose file:", err)
+			return
+		}
+		showComposeExport(data)
+	}, mainWindow)
+	d.Resize(fyne.NewSize(500, 450))
+	d.Show()
+}
+
+// showComposeExport shows an exported compose file with buttons to save or
+// copy it.
+func showComposeExport(data []byte) {
+	win := appInstance.NewWindow("compose.yaml")
+	saveBtn := widget.NewButton("Save...", func() {
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if w == nil {
+				return
+			}
+			_, err = w.Write(data)
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName("compose.yaml")
+		save.Show()
+	})
+	copyBtn := widget.NewButton("Copy All", func() {
+		win.Clipboard().SetContent(string(data))
+	})
+	grid := widget.NewTextGridFromString(string(data))
+	win.SetContent(container.NewBorder(container.NewHBox(saveBtn, copyBtn), nil, nil, nil, container.NewScroll(grid)))
+	win.Resize(fyne.NewSize(700, 600))
+	win.Show()
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Grouped Containers View
+// =============================================================================
+
+// Choices of the Containers tab's view selector.
+const (
+	viewFlat    = "List"
+	viewCompose = "Group by compose project"
+	viewLabel   = "Group by label"
+)
+
+// groupNode is a row of the grouped view: a group or a container.
+type groupNode struct {
+	group     *dashboard.ContainerGroup
+	container dashboard.Container
+}
+
+// containerGroupView shows containers as a tree grouped by label values.
+// Like the resource tables it is rebuilt from the event watcher while the
+// UI draws it, so its state is guarded by mu.
+type containerGroupView struct {
+	tree *widget.Tree
+
+	mu       sync.Mutex
+	keys     []string // label keys grouped by; nil while the flat list shows
+	nodes    map[string]groupNode
+	children map[string][]string
+	selected string
+}
+
+func newContainerGroupView() *containerGroupView {
+	v := &containerGroupView{
+		nodes:    map[string]groupNode{},
+		children: map[string][]string{},
+	}
+	v.tree = widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return v.children[uid]
+		},
+		func(uid widget.TreeNodeID) bool {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return uid == "" || v.nodes[uid].group != nil
+		},
+		func(branch bool) fyne.CanvasObject { return widget.NewLabel("") },
+		func(uid widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			v.mu.Lock()
+			n := v.nodes[uid]
+			v.mu.Unlock()
+			obj.(*widget.Label).SetText(formatGroupNode(n))
+		},
+	)
+	v.tree.OnSelected = func(uid widget.TreeNodeID) {
+		v.mu.Lock()
+		v.selected = uid
+		n := v.nodes[uid]
+		v.mu.Unlock()
+		if n.group == nil {
+			selectedContainerID = n.container.ID
+		}
+	}
+	return v
+}
+
+// setKeys sets the label keys to group by, nil for none.
+func (v *containerGroupView) setKeys(keys []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+}
+
+// grouped reports whether the view is grouping containers.
+func (v *containerGroupView) grouped() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.keys != nil
+}
+
+// setContainers rebuilds the tree from containers grouped by the view's
+// keys, keeping open branches and the selection where they still exist. It
+// does nothing while the view is not grouping.
+func (v *containerGroupView) setContainers(containers []dashboard.Container) {
+	v.mu.Lock()
+	keys := v.keys
+	v.mu.Unlock()
+	if keys == nil {
+		return
+	}
+	nodes := map[string]groupNode{}
+	children := map[string][]string{}
+	var add func(parent string, groups []*dashboard.ContainerGroup)
+	add = func(parent string, groups []*dashboard.ContainerGroup) {
+		for _, g := range groups {
+			uid := parent + "/" + url.PathEscape(g.Label) + "=" + url.PathEscape(g.Value)
+			nodes[uid] = groupNode{group: g}
+			children[parent] = append(children[parent], uid)
+			add(uid, g.Groups)
+			for _, c := range g.Containers {
+				cid := uid + "/" + c.ID
+				nodes[cid] = groupNode{container: c}
+				children[uid] = append(children[uid], cid)
+			}
+		}
+	}
+	add("", dashboard.GroupContainers(containers, keys...))
+
+	v.mu.Lock()
+	v.nodes, v.children = nodes, children
+	_, ok := v.nodes[v.selected]
+	if !ok {
+		v.selected = ""
+	}
+	v.mu.Unlock()
+	v.tree.Refresh()
+	if !ok {
+		v.tree.UnselectAll()
+	}
+}
+
+// selectedGroup returns the selected group, or nil when a container or
+// nothing is selected.
+func (v *containerGroupView) selectedGroup() *dashboard.ContainerGroup {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.nodes[v.selected].group
+}
+
+func formatGroupNode(n groupNode) string {
+	if n.group == nil {
+		return formatContainerRow(n.container)
+	}
+	g := n.group
+	name := g.Value
+	if name == "" {
+		name = "(no " + g.Label + ")"
+	}
+	prefix := g.Label
+	switch g.Label {
+	case dashboard.ComposeProjectLabel:
+		prefix = "project"
+	case dashboard.ComposeServiceLabel:
+		prefix = "service"
+	}
+	return fmt.Sprintf("%s: %s (%s)", prefix, name, g.Summary())
+}
+
+// newGroupActionButtons returns the Start/Stop/Restart/Remove Group buttons
+// acting on the group selected in view. The list follows through Docker
+// events.
+func newGroupActionButtons(view *containerGroupView) []fyne.CanvasObject {
+	return newContainerActionButtons("Group",
+		func() []dashboard.Container {
+			if g := view.selectedGroup(); g != nil {
+				return g.All()
+			}
+			return nil
+		},
+		func(n int) string {
+			return fmt.Sprintf("%d containers of %s", n, formatGroupNode(groupNode{group: view.selectedGroup()}))
+		}, nil)
+}
+
+// newContainerViewSelector returns the view selector and the label key
+// picker, calling onChange with the label keys to group by, or nil for the
+// flat list.
+func newContainerViewSelector(onChange func(keys []string)) (*widget.Select, *widget.SelectEntry) {
+	labelEntry := widget.NewSelectEntry(nil)
+	labelEntry.SetPlaceHolder("label key")
+	labelEntry.Disable()
+	viewSelect := widget.NewSelect([]string{viewFlat, viewCompose, viewLabel}, nil)
+	changed := func() {
+		switch viewSelect.Selected {
+		case viewCompose:
+			labelEntry.Disable()
+			onChange([]string{dashboard.ComposeProjectLabel, dashboard.ComposeServiceLabel})
+		case viewLabel:
+			labelEntry.Enable()
+			if key := strings.TrimSpace(labelEntry.Text); key != "" {
+				onChange([]string{key})
+			}
+		default:
+			labelEntry.Disable()
+			onChange(nil)
+		}
+	}
+	viewSelect.OnChanged = func(string) { changed() }
+	labelEntry.OnChanged = func(string) {
+		if viewSelect.Selected == viewLabel {
+			changed()
+		}
+	}
+	viewSelect.SetSelected(viewFlat)
+	return viewSelect, labelEntry
+}
+
+// groupViewToolbar lays out the selector and the group buttons.
+func groupViewToolbar(viewSelect *widget.Select, labelEntry *widget.SelectEntry, groupButtons []fyne.CanvasObject) fyne.CanvasObject {
+	groupRow := container.NewHBox(groupButtons...)
+	return container.NewBorder(nil, nil, container.NewHBox(widget.NewLabel("View"), viewSelect), groupRow, labelEntry)
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Advanced Container Creation (Accordion Form)
+// =============================================================================
+
+// restartPolicies are the restart policy choices, as docker run spells them.
+var restartPolicies = []string{"no", "always", "unless-stopped", "on-failure"}
+
+// defaultNetworkLabel selects the daemon's default network.
+const defaultNetworkLabel = "(default)"
+
+// containerForm holds the widgets of the create form.
+type containerForm struct {
+	name       *widget.Entry
+	image      *widget.Entry
+	cmd        *widget.Entry
+	entrypoint *widget.Entry
+	workingDir *widget.Entry
+	user       *widget.Entry
+
+	env    *rowList
+	ports  *rowList
+	mounts *rowList
+	labels *rowList
+
+	network    *widget.Select
+	restart    *widget.Select
+	maxRetries *widget.Entry
+	memory     *widget.Entry
+	cpuShares  *widget.Entry
+	cpus       *widget.Entry
+	capAdd     *widget.Entry
+	privileged *widget.Check
+	accordion  *widget.Accordion
+
+	// base carries the settings of an imported command that have no field
+	// in the form; extraMounts tells the user about its --mount entries.
+	base        dashboard.ContainerSpec
+	extraMounts *widget.Label
+}
+
+// showAdvancedContainerForm opens a window for creating and starting a
+// container. onCreated runs after the container has started.
+func showAdvancedContainerForm(onCreated func()) {
+	win := appInstance.NewWindow("Run Custom Container")
+	f := newContainerForm()
+
+	status := widget.NewLabel("")
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Create Container", func() {
+		spec, err := f.spec()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		submitBtn.Disable()
+		status.SetText("Pulling " + spec.Config.Image + " and starting...")
+		go func() {
+			_, err := dockerService.RunContainer(context.Background(), spec)
+			submitBtn.Enable()
+			status.SetText("")
+			if err != nil {
+				log.Println("Error creating container:", err)
+				dialog.ShowError(err, win)
+				return
+			}
+			if onCreated != nil {
+				onCreated()
+			}
+			win.Close()
+		}()
+	})
+
+	importBtn := widget.NewButton("Import docker run command...", func() {
+		f.showImportRunDialog(win)
+	})
+
+	content := container.NewBorder(importBtn, container.NewVBox(status, submitBtn), nil, nil,
+		container.NewVScroll(f.accordion))
+	win.SetContent(content)
+	win.Resize(fyne.NewSize(700, 750))
+	win.Show()
+}
+
+func newContainerForm() *containerForm {
+	f := &containerForm{
+		name:       widget.NewEntry(),
+		image:      widget.NewEntry(),
+		cmd:        widget.NewEntry(),
+		entrypoint: widget.NewEntry(),
+		workingDir: widget.NewEntry(),
+		user:       widget.NewEntry(),
+		env:        newRowList("KEY", "value"),
+		ports:      newRowList("host port (or ip:port)", "container port (e.g. 80 or 53/udp)"),
+		mounts:     newRowList("volume name or /host/path", "container path", "options (e.g. ro)"),
+		labels:     newRowList("key", "value"),
+		maxRetries: widget.NewEntry(),
+		memory:     widget.NewEntry(),
+		cpuShares:  widget.NewEntry(),
+		cpus:       widget.NewEntry(),
+		capAdd:     widget.NewEntry(),
+		privileged: widget.NewCheck("Privileged Mode", func(bool) {}),
+
+		extraMounts: widget.NewLabel(""),
+	}
+
+	// 1. Basic Section
+	f.image.SetText("alpine")
+	imageHelp := widget.NewLabel("The Docker image name, e.g. 'alpine:latest'")
+	f.name.SetPlaceHolder("generated by Docker")
+	f.cmd.SetText("echo hello world")
+	cmdHelp := widget.NewLabel("Command to run in the container. Quote arguments containing spaces.")
+
+	basicForm := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Image", f.image),
+			widget.NewFormItem("Name", f.name),
+			widget.NewFormItem("Command", f.cmd),
+		),
+		imageHelp,
+		cmdHelp,
+	)
+
+	// 2. Environment & Ports Section
+	envHelp := widget.NewLabel("Set environment variables (key=value). Add rows as needed.")
+	portsHelp := widget.NewLabel("Map host ports to container ports (hostPort -> containerPort).")
+	envPortsSection := container.NewVBox(
+		envHelp,
+		widget.NewButton("Add Env", func() { f.env.add() }),
+		f.env.box,
+		widget.NewSeparator(),
+		portsHelp,
+		widget.NewButton("Add Port", func() { f.ports.add() }),
+		f.ports.box,
+	)
+
+	// 3. Storage & Network Section
+	mountsHelp := widget.NewLabel("Mount a named volume or a host path. A source starting with / is a bind mount;\n" +
+		"leave the source blank for an anonymous volume.")
+	f.network = widget.NewSelect([]string{defaultNetworkLabel}, nil)
+	f.network.SetSelected(defaultNetworkLabel)
+	f.loadNetworks()
+	networkHelp := widget.NewLabel("Network to connect the container to.")
+	storageSection := container.NewVBox(
+		mountsHelp,
+		widget.NewButton("Add Mount", func() { f.mounts.add() }),
+		f.mounts.box,
+		f.extraMounts,
+		widget.NewSeparator(),
+		widget.NewForm(widget.NewFormItem("Network", f.network)),
+		networkHelp,
+	)
+
+	// 4. Advanced Section
+	f.entrypoint.SetPlaceHolder("image default")
+	f.workingDir.SetPlaceHolder("image default")
+	f.user.SetPlaceHolder("image default, e.g. 1000:1000")
+	f.maxRetries.SetPlaceHolder("unlimited")
+	f.maxRetries.Disable()
+	f.restart = widget.NewSelect(restartPolicies, func(policy string) {
+		if policy == "on-failure" {
+			f.maxRetries.Enable()
+		} else {
+			f.maxRetries.Disable()
+		}
+	})
+	f.restart.SetSelected("no")
+	f.memory.SetPlaceHolder("e.g. 256 (MB) or 1g")
+	memoryHelp := widget.NewLabel("Memory limit in MB, or with a unit (k, m, g). Leave blank for unlimited.")
+	f.cpuShares.SetPlaceHolder("e.g. 1024")
+	cpuHelp := widget.NewLabel("Relative CPU weight. 1024 is default for one CPU.")
+	f.cpus.SetPlaceHolder("e.g. 1.5")
+	f.capAdd.SetPlaceHolder("e.g. NET_ADMIN,SYS_TIME")
+	privilegedHelp := widget.NewLabel("Grants extended privileges to this container.")
+
+	advancedForm := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Entrypoint", f.entrypoint),
+			widget.NewFormItem("Working dir", f.workingDir),
+			widget.NewFormItem("User", f.user),
+			widget.NewFormItem("Restart policy", f.restart),
+			widget.NewFormItem("Max retries", f.maxRetries),
+			widget.NewFormItem("Memory (MB)", f.memory),
+			widget.NewFormItem("CPU Shares", f.cpuShares),
+			widget.NewFormItem("CPUs", f.cpus),
+			widget.NewFormItem("Add capabilities", f.capAdd),
+		),
+		memoryHelp,
+		cpuHelp,
+		widget.NewSeparator(),
+		f.privileged,
+		privilegedHelp,
+	)
+
+	// 5. Labels Section
+	labelsSection := container.NewVBox(
+		widget.NewButton("Add Label", func() { f.labels.add() }),
+		f.labels.box,
+	)
+
+	f.accordion = widget.NewAccordion(
+		widget.NewAccordionItem("Basic", basicForm),
+		widget.NewAccordionItem("Environment & Ports", envPortsSection),
+		widget.NewAccordionItem("Storage & Network", storageSection),
+		widget.NewAccordionItem("Advanced", advancedForm),
+		widget.NewAccordionItem("Labels", labelsSection),
+	)
+	f.accordion.MultiOpen = true
+	for _, item := range f.accordion.Items {
+		item.Open = true
+	}
+	return f
+}
+
+// loadNetworks fills the network choices in the background.
+func (f *containerForm) loadNetworks() {
+	svc := dockerService
+	go func() {
+		networks, err := svc.ListNetworks(context.Background(), filters.NewArgs())
+		if err != nil {
+			log.Println("Error fetching networks:", err)
+			return
+		}
+		options := []string{defaultNetworkLabel}
+		for _, n := range networks {
+			options = append(options, n.Name)
+		}
+		// Keep a network chosen by an import even if it does not exist yet.
+		selected := f.network.Selected
+		if !slices.Contains(options, selected) {
+			options = append(options, selected)
+		}
+		f.network.Options = options
+		f.network.Refresh()
+		f.network.SetSelected(selected)
+	}()
+}
+
+// spec builds the container described by the form, rejecting values Docker
+// would not accept. Settings an import carried that the form does not show
+// are kept.
+func (f *containerForm) spec() (dashboard.ContainerSpec, error) {
+	image := strings.TrimSpace(f.image.Text)
+	if image == "" {
+		return dashboard.ContainerSpec{}, fmt.Errorf("enter an image to run")
+	}
+	cmd, err := dashboard.SplitCommandLine(f.cmd.Text)
+	if err != nil {
+		return dashboard.ContainerSpec{}, fmt.Errorf("command: %w", err)
+	}
+	entrypoint, err := dashboard.SplitCommandLine(f.entrypoint.Text)
+	if err != nil {
+		return dashboard.ContainerSpec{}, fmt.Errorf("entrypoint: %w", err)
+	}
+	exposed, bindings, err := gatherPortBindings(f.ports)
+	if err != nil {
+		return dashboard.ContainerSpec{}, err
+	}
+	binds, volumes, err := gatherBinds(f.mounts)
+	if err != nil {
+		return dashboard.ContainerSpec{}, err
+	}
+	memory, err := parseMemory(f.memory.Text)
+	if err != nil {
+		return dashboard.ContainerSpec{}, err
+	}
+	cpuShares, err := parseNonNegative("CPU Shares", f.cpuShares.Text)
+	if err != nil {
+		return dashboard.ContainerSpec{}, err
+	}
+	var nanoCPUs int64
+	if text := strings.TrimSpace(f.cpus.Text); text != "" {
+		cpus, err := strconv.ParseFloat(text, 64)
+		if err != nil || cpus < 0 || math.IsInf(cpus, 0) || math.IsNaN(cpus) {
+			return dashboard.ContainerSpec{}, fmt.Errorf("CPUs must be a non-negative number, not %q", text)
+		}
+		nanoCPUs = int64(cpus * 1e9)
+	}
+	restart := dockerContainer.RestartPolicy{Name: dockerContainer.RestartPolicyMode(f.restart.Selected)}
+	if restart.Name == dockerContainer.RestartPolicyOnFailure {
+		retries, err := parseNonNegative("Max retries", f.maxRetries.Text)
+		if err != nil {
+			return dashboard.ContainerSpec{}, err
+		}
+		restart.MaximumRetryCount = int(retries)
+	}
+	var networkMode dockerContainer.NetworkMode
+	if f.network.Selected != defaultNetworkLabel {
+		networkMode = dockerContainer.NetworkMode(f.network.Selected)
+	}
+
+	var config dockerContainer.Config
+	var hostConfig dockerContainer.HostConfig
+	if f.base.Config != nil {
+		config = *f.base.Config
+	}
+	if f.base.HostConfig != nil {
+		hostConfig = *f.base.HostConfig
+	}
+	config.Image = image
+	config.Cmd = cmd
+	config.Entrypoint = entrypoint
+	config.Env = gatherEnvVars(f.env)
+	config.Labels = gatherLabels(f.labels)
+	config.WorkingDir = strings.TrimSpace(f.workingDir.Text)
+	config.User = strings.TrimSpace(f.user.Text)
+	config.ExposedPorts = mergePorts(config.ExposedPorts, exposed)
+	config.Volumes = volumes
+	hostConfig.Binds = binds
+	hostConfig.PortBindings = bindings
+	hostConfig.NetworkMode = networkMode
+	hostConfig.RestartPolicy = restart
+	hostConfig.Privileged = f.privileged.Checked
+	hostConfig.CapAdd = splitList(f.capAdd.Text)
+	hostConfig.Memory = memory
+	hostConfig.CPUShares = cpuShares
+	hostConfig.NanoCPUs = nanoCPUs
+
+	return dashboard.ContainerSpec{
+		Name:             strings.TrimSpace(f.name.Text),
+		Config:           &config,
+		HostConfig:       &hostConfig,
+		NetworkingConfig: f.base.NetworkingConfig,
+	}, nil
+}
+
+// fill sets the form from spec, as parsed from a docker run command.
+func (f *containerForm) fill(spec dashboard.ContainerSpec) {
+	config, hostConfig := spec.Config, spec.HostConfig
+	if config == nil {
+		config = &dockerContainer.Config{}
+	}
+	if hostConfig == nil {
+		hostConfig = &dockerContainer.HostConfig{}
+	}
+
+	f.name.SetText(spec.Name)
+	f.image.SetText(config.Image)
+	f.cmd.SetText(dashboard.QuoteCommandLine(config.Cmd))
+	f.entrypoint.SetText(dashboard.QuoteCommandLine(config.Entrypoint))
+	f.workingDir.SetText(config.WorkingDir)
+	f.user.SetText(config.User)
+
+	f.env.clear()
+	for _, kv := range config.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		f.env.add(k, v)
+	}
+	f.labels.clear()
+	for _, k := range dashboard.SortedKeys(config.Labels) {
+		f.labels.add(k, config.Labels[k])
+	}
+
+	// Ports that are only exposed stay in the spec; published ones become
+	// rows.
+	f.ports.clear()
+	exposedOnly := nat.PortSet{}
+	for port := range config.ExposedPorts {
+		if _, ok := hostConfig.PortBindings[port]; !ok {
+			exposedOnly[port] = struct{}{}
+		}
+	}
+	ports := make([]nat.Port, 0, len(hostConfig.PortBindings))
+	for port := range hostConfig.PortBindings {
+		ports = append(ports, port)
+	}
+	nat.Sort(ports, func(a, b nat.Port) bool { return a.Int() < b.Int() })
+	for _, port := range ports {
+		for _, b := range hostConfig.PortBindings[port] {
+			// The container port, the last part of the spec, has no colon.
+			spec := dashboard.FormatPortSpec(port, b)
+			host, containerPort := "", spec
+			if i := strings.LastIndexByte(spec, ':'); i >= 0 {
+				host, containerPort = spec[:i], spec[i+1:]
+			}
+			f.ports.add(host, containerPort)
+		}
+	}
+
+	f.mounts.clear()
+	for _, bind := range hostConfig.Binds {
+		parts := strings.SplitN(bind, ":", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		f.mounts.add(parts...)
+	}
+	for _, path := range dashboard.SortedKeys(config.Volumes) {
+		f.mounts.add("", path)
+	}
+
+	network := string(hostConfig.NetworkMode)
+	if network == "" {
+		network = defaultNetworkLabel
+	}
+	if !slices.Contains(f.network.Options, network) {
+		f.network.Options = append(f.network.Options, network)
+	}
+	f.network.SetSelected(network)
+
+	restart := string(hostConfig.RestartPolicy.Name)
+	if restart == "" {
+		restart = "no"
+	}
+	f.restart.SetSelected(restart)
+	f.maxRetries.SetText("")
+	if hostConfig.RestartPolicy.MaximumRetryCount > 0 {
+		f.maxRetries.SetText(strconv.Itoa(hostConfig.RestartPolicy.MaximumRetryCount))
+	}
+	f.memory.SetText(formatMemory(hostConfig.Memory))
+	f.cpuShares.SetText("")
+	if hostConfig.CPUShares > 0 {
+		f.cpuShares.SetText(strconv.FormatInt(hostConfig.CPUShares, 10))
+	}
+	f.cpus.SetText("")
+	if hostConfig.NanoCPUs > 0 {
+		f.cpus.SetText(strconv.FormatFloat(float64(hostConfig.NanoCPUs)/1e9, 'f', -1, 64))
+	}
+	f.capAdd.SetText(strings.Join(hostConfig.CapAdd, ","))
+	f.privileged.SetChecked(hostConfig.Privileged)
+
+	base := spec
+	baseConfig := *config
+	baseConfig.ExposedPorts = exposedOnly
+	base.Config = &baseConfig
+	base.HostConfig = hostConfig
+	f.base = base
+	f.extraMounts.SetText("")
+	if n := len(hostConfig.Mounts); n > 0 {
+		f.extraMounts.SetText(fmt.Sprintf("%d --mount entries from the imported command are also applied.", n))
+	}
+}
+
+// showImportRunDialog asks for a docker run command and fills the form from
+// it, listing the flags that could not be imported.
+func (f *containerForm) showImportRunDialog(win fyne.Window) {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("docker run -d --name web -p 8080:80 nginx")
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetMinRowsVisible(6)
+	d := dialog.NewForm("Import docker run command", "Import", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Command", entry)}, func(ok bool) {
+			if !ok {
+				return
+			}
+			run, err := dashboard.ParseRunCommand(entry.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			f.fill(run.Spec)
+			if len(run.Unsupported) > 0 {
+				dialog.ShowInformation("Import",
+					"These flags are not supported and were not imported:\n\n"+strings.Join(run.Unsupported, "\n"), win)
+			}
+		}, win)
+	d.Resize(fyne.NewSize(600, 350))
+	d.Show()
+}
+
+// parseNonNegative parses an optional whole number field; blank means zero.
+func parseNonNegative(field, text string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative whole number, not %q", field, text)
+	}
+	return n, nil
+}
+
+// parseMemory parses the memory limit: a plain number is in MB, otherwise
+// it takes a unit as in docker run -m.
+func parseMemory(text string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	if mb, err := strconv.ParseInt(text, 10, 64); err == nil && mb >= 0 {
+		return mb * 1024 * 1024, nil
+	}
+	n, err := units.RAMInBytes(text)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("memory must be a number of MB or a size such as 1g, not %q", text)
+	}
+	return n, nil
+}
+
+// formatMemory is the inverse of parseMemory.
+func formatMemory(bytes int64) string {
+	switch {
+	case bytes <= 0:
+		return ""
+	case bytes%(1024*1024*1024) == 0:
+		return strconv.FormatInt(bytes/(1024*1024*1024), 10) + "g"
+	case bytes%(1024*1024) == 0:
+		return strconv.FormatInt(bytes/(1024*1024), 10)
+	}
+	return strconv.FormatInt(bytes, 10) + "b"
+}
+
+// =============================================================================
+// Helper Functions for Advanced Form
+// =============================================================================
+
+// rowList is a growable list of rows of entries, such as key/value pairs.
+type rowList struct {
+	hints []string
+	box   *fyne.Container
+	rows  [][]*widget.Entry
+}
+
+func newRowList(hints ...string) *rowList {
+	return &rowList{hints: hints, box: container.NewVBox()}
+}
+
+// add appends a row, filling its entries from values.
+func (l *rowList) add(values ...string) {
+	entries := make([]*widget.Entry, len(l.hints))
+	cells := make([]fyne.CanvasObject, len(l.hints))
+	for i, hint := range l.hints {
+		entries[i] = widget.NewEntry()
+		entries[i].SetPlaceHolder(hint)
+		if i < len(values) {
+			entries[i].SetText(values[i])
+		}
+		cells[i] = entries[i]
+	}
+	var row fyne.CanvasObject
+	removeBtn := widget.NewButton("Remove", func() {
+		for i, r := range l.rows {
+			if r[0] == entries[0] {
+				l.rows = append(l.rows[:i], l.rows[i+1:]...)
+				break
+			}
+		}
+		l.box.Remove(row)
+	})
+	row = container.NewBorder(nil, nil, nil, removeBtn, container.NewGridWithColumns(len(cells), cells...))
+	l.rows = append(l.rows, entries)
+	l.box.Add(row)
+}
+
+// clear removes every row.
+func (l *rowList) clear() {
+	l.rows = nil
+	l.box.RemoveAll()
+}
+
+// values returns the trimmed text of each row that is not blank.
+func (l *rowList) values() [][]string {
+	var result [][]string
+	for _, row := range l.rows {
+		texts := make([]string, len(row))
+		blank := true
+		for i, e := range row {
+			texts[i] = strings.TrimSpace(e.Text)
+			blank = blank && texts[i] == ""
+		}
+		if !blank {
+			result = append(result, texts)
+		}
+	}
+	return result
+}
+
+func gatherEnvVars(env *rowList) []string {
+	var result []string
+	for _, row := range env.values() {
+		if row[0] == "" {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s=%s", row[0], row[1]))
+	}
+	return result
+}
+
+func gatherLabels(labels *rowList) map[string]string {
+	rows := labels.values()
+	if len(rows) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if row[0] != "" {
+			result[row[0]] = row[1]
+		}
+	}
+	return result
+}
+
+// gatherPortBindings reads rows of host and container ports, which may
+// carry a host IP, a protocol or a range as in docker run -p.
+func gatherPortBindings(ports *rowList) (nat.PortSet, nat.PortMap, error) {
+	var specs []string
+	for _, row := range ports.values() {
+		switch {
+		case row[1] == "":
+			return nil, nil, fmt.Errorf("port %s: enter the container port", row[0])
+		case row[0] == "":
+			// Publish on a port the daemon picks.
+			specs = append(specs, row[1])
+		default:
+			specs = append(specs, row[0]+":"+row[1])
+		}
+	}
+	if len(specs) == 0 {
+		return nil, nil, nil
+	}
+	exposed, bindings, err := nat.ParsePortSpecs(specs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ports: %w", err)
+	}
+	return exposed, bindings, nil
+}
+
+// gatherBinds reads rows of mount source, container path and options into
+// docker run -v strings. Rows without a source are anonymous volumes.
+func gatherBinds(mounts *rowList) ([]string, map[string]struct{}, error) {
+	var binds []string
+	var volumes map[string]struct{}
+	for _, row := range mounts.values() {
+		if !strings.HasPrefix(row[1], "/") {
+			return nil, nil, fmt.Errorf("mount %q: the container path must be absolute", row[1])
+		}
+		if row[0] == "" {
+			if volumes == nil {
+				volumes = map[string]struct{}{}
+			}
+			volumes[row[1]] = struct{}{}
+			continue
+		}
+		bind := row[0] + ":" + row[1]
+		if row[2] != "" {
+			bind += ":" + row[2]
+		}
+		binds = append(binds, bind)
+	}
+	return binds, volumes, nil
+}
+
+// mergePorts returns the union of two port sets.
+func mergePorts(a, b nat.PortSet) nat.PortSet {
+	if len(a) == 0 {
+		return b
+	}
+	result := nat.PortSet{}
+	for p := range a {
+		result[p] = struct{}{}
+	}
+	for p := range b {
+		result[p] = struct{}{}
+	}
+	return result
+}
+
+// splitList splits a comma-separated list, dropping blank items.
+func splitList(text string) []string {
+	var result []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
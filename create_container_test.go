@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/go-connections/nat"
+
+	"sprint/dashboard"
+)
+
+func TestParseMemory(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		text string
+		want int64
+		ok   bool
+	}{
+		{"", 0, true},
+		{"  ", 0, true},
+		{"0", 0, true},
+		{"256", 256 * mb, true},
+		{" 512 ", 512 * mb, true},
+		{"512m", 512 * mb, true},
+		{"1g", 1024 * mb, true},
+		{"1.5G", 1536 * mb, true},
+		{"64k", 64 * 1024, true},
+		{"1000b", 1000, true},
+		{"-1", 0, false},
+		{"-1g", 0, false},
+		{"lots", 0, false},
+		{"1x", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseMemory(tt.text)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseMemory(%q) = %d, %v; want %d, ok %v", tt.text, got, err, tt.want, tt.ok)
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "memory must be") {
+			t.Errorf("parseMemory(%q) error = %q", tt.text, err)
+		}
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, ""},
+		{-1, ""},
+		{256 * mb, "256"},
+		{1024 * mb, "1g"},
+		{3 * 1024 * mb, "3g"},
+		{1536 * mb, "1536"},
+		{1000, "1000b"},
+		{mb + 1, "1048577b"},
+	}
+	for _, tt := range tests {
+		got := formatMemory(tt.bytes)
+		if got != tt.want {
+			t.Errorf("formatMemory(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+		// What the form shows parses back to the same limit.
+		if back, err := parseMemory(got); err != nil || back != max(tt.bytes, 0) {
+			t.Errorf("parseMemory(formatMemory(%d)) = %d, %v", tt.bytes, back, err)
+		}
+	}
+}
+
+func TestParseNonNegative(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+		ok   bool
+	}{
+		{"", 0, true},
+		{"0", 0, true},
+		{" 1024 ", 1024, true},
+		{"-1", 0, false},
+		{"1.5", 0, false},
+		{"ten", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseNonNegative("CPU Shares", tt.text)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseNonNegative(%q) = %d, %v; want %d, ok %v", tt.text, got, err, tt.want, tt.ok)
+		}
+		if err != nil && !strings.Contains(err.Error(), "CPU Shares must be a non-negative whole number") {
+			t.Errorf("parseNonNegative(%q) error = %q", tt.text, err)
+		}
+	}
+}
+
+// newTestRows returns a row list holding rows.
+func newTestRows(rows ...[]string) *rowList {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	l := newRowList(make([]string, width)...)
+	for _, row := range rows {
+		l.add(row...)
+	}
+	return l
+}
+
+func TestGatherBinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]string
+		binds   []string
+		volumes map[string]struct{}
+		ok      bool
+	}{
+		{"none", nil, nil, nil, true},
+		{"named volume", [][]string{{"data", "/var/lib/data", ""}}, []string{"data:/var/lib/data"}, nil, true},
+		{"bind with options", [][]string{{"/srv/www", "/usr/share/nginx/html", "ro"}}, []string{"/srv/www:/usr/share/nginx/html:ro"}, nil, true},
+		{"anonymous volume", [][]string{{"", "/cache", ""}}, nil, map[string]struct{}{"/cache": {}}, true},
+		{"blank rows are skipped", [][]string{{" ", "", " "}, {"data", " /data ", ""}}, []string{"data:/data"}, nil, true},
+		{"mixed", [][]string{{"data", "/data", "rw"}, {"", "/tmp/a", ""}, {"", "/tmp/b", ""}},
+			[]string{"data:/data:rw"}, map[string]struct{}{"/tmp/a": {}, "/tmp/b": {}}, true},
+		{"relative path", [][]string{{"data", "data", ""}}, nil, nil, false},
+		{"missing path", [][]string{{"data", "", "ro"}}, nil, nil, false},
+	}
+	for _, tt := range tests {
+		binds, volumes, err := gatherBinds(newTestRows(tt.rows...))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error = %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if !reflect.DeepEqual(binds, tt.binds) || !reflect.DeepEqual(volumes, tt.volumes) {
+			t.Errorf("%s: got %q, %v; want %q, %v", tt.name, binds, volumes, tt.binds, tt.volumes)
+		}
+	}
+}
+
+func TestGatherPortBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		bindings nat.PortMap
+		ok       bool
+	}{
+		{"none", nil, nil, true},
+		{"host port", [][]string{{"8080", "80"}},
+			nat.PortMap{"80/tcp": {{HostPort: "8080"}}}, true},
+		{"host ip", [][]string{{"127.0.0.1:8080", "80"}},
+			nat.PortMap{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}}, true},
+		{"ipv6 host ip", [][]string{{"[::1]:8080", "80"}},
+			nat.PortMap{"80/tcp": {{HostIP: "::1", HostPort: "8080"}}}, true},
+		{"udp and a daemon-picked port", [][]string{{"", "53/udp"}},
+			nat.PortMap{"53/udp": {{}}}, true},
+		{"range", [][]string{{"8000-8001", "80-81"}},
+			nat.PortMap{"80/tcp": {{HostPort: "8000"}}, "81/tcp": {{HostPort: "8001"}}}, true},
+		{"one port on two host ports", [][]string{{"8080", "80"}, {"8081", "80"}},
+			nat.PortMap{"80/tcp": {{HostPort: "8080"}, {HostPort: "8081"}}}, true},
+		{"missing container port", [][]string{{"8080", ""}}, nil, false},
+		{"bad port", [][]string{{"8080", "http"}}, nil, false},
+	}
+	for _, tt := range tests {
+		exposed, bindings, err := gatherPortBindings(newTestRows(tt.rows...))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error = %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if !reflect.DeepEqual(bindings, tt.bindings) {
+			t.Errorf("%s: bindings = %v, want %v", tt.name, bindings, tt.bindings)
+		}
+		// Every published port is exposed.
+		if len(exposed) != len(tt.bindings) {
+			t.Errorf("%s: exposed = %v", tt.name, exposed)
+		}
+		for port := range tt.bindings {
+			if _, ok := exposed[port]; !ok {
+				t.Errorf("%s: %s is not exposed", tt.name, port)
+			}
+		}
+	}
+}
+
+// noNetworksService cannot list networks, so a form's network loader gives
+// up without touching the form.
+type noNetworksService struct {
+	fakeService
+}
+
+func (*noNetworksService) ListNetworks(context.Context, filters.Args) ([]dashboard.Network, error) {
+	return nil, errors.New("no daemon")
+}
+
+func newTestContainerForm(t *testing.T) *containerForm {
+	t.Helper()
+	old := dockerService
+	dockerService = &noNetworksService{}
+	t.Cleanup(func() { dockerService = old })
+	return newContainerForm()
+}
+
+func TestContainerFormSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(f *containerForm)
+		check func(t *testing.T, spec dashboard.ContainerSpec)
+		err   string // a part of the expected error
+	}{
+		{"defaults", func(f *containerForm) {}, func(t *testing.T, spec dashboard.ContainerSpec) {
+			if spec.Config.Image != "alpine" || !reflect.DeepEqual([]string(spec.Config.Cmd), []string{"echo", "hello", "world"}) {
+				t.Errorf("config = %+v", spec.Config)
+			}
+			if spec.HostConfig.NetworkMode != "" || spec.HostConfig.RestartPolicy.Name != "no" || spec.HostConfig.Memory != 0 {
+				t.Errorf("host config = %+v", spec.HostConfig)
+			}
+		}, ""},
+		{"fields", func(f *containerForm) {
+			f.name.SetText(" web ")
+			f.image.SetText("nginx:1.27")
+			f.cmd.SetText(`sh -c "echo 'hi there'"`)
+			f.entrypoint.SetText("/docker-entrypoint.sh")
+			f.env.add("MODE", "prod")
+			f.env.add("", "ignored")
+			f.labels.add("team", "web")
+			f.ports.add("8080", "80")
+			f.mounts.add("html", "/usr/share/nginx/html", "ro")
+			f.restart.SetSelected("on-failure")
+			f.maxRetries.SetText("3")
+			f.memory.SetText("512")
+			f.cpuShares.SetText("512")
+			f.cpus.SetText("1.5")
+			f.capAdd.SetText("NET_ADMIN, SYS_TIME,")
+			f.privileged.SetChecked(true)
+		}, func(t *testing.T, spec dashboard.ContainerSpec) {
+			c, h := spec.Config, spec.HostConfig
+			if spec.Name != "web" || c.Image != "nginx:1.27" {
+				t.Errorf("name %q, image %q", spec.Name, c.Image)
+			}
+			if !reflect.DeepEqual([]string(c.Cmd), []string{"sh", "-c", "echo 'hi there'"}) || !reflect.DeepEqual([]string(c.Entrypoint), []string{"/docker-entrypoint.sh"}) {
+				t.Errorf("cmd %q, entrypoint %q", c.Cmd, c.Entrypoint)
+			}
+			if !reflect.DeepEqual(c.Env, []string{"MODE=prod"}) || !reflect.DeepEqual(c.Labels, map[string]string{"team": "web"}) {
+				t.Errorf("env %q, labels %v", c.Env, c.Labels)
+			}
+			if _, ok := c.ExposedPorts["80/tcp"]; !ok || h.PortBindings["80/tcp"][0].HostPort != "8080" {
+				t.Errorf("ports %v, %v", c.ExposedPorts, h.PortBindings)
+			}
+			if !reflect.DeepEqual(h.Binds, []string{"html:/usr/share/nginx/html:ro"}) {
+				t.Errorf("binds %q", h.Binds)
+			}
+			want := dockerContainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}
+			if h.RestartPolicy != want {
+				t.Errorf("restart policy %+v", h.RestartPolicy)
+			}
+			if h.Memory != 512*1024*1024 || h.CPUShares != 512 || h.NanoCPUs != 1_500_000_000 {
+				t.Errorf("memory %d, shares %d, nano CPUs %d", h.Memory, h.CPUShares, h.NanoCPUs)
+			}
+			if !reflect.DeepEqual([]string(h.CapAdd), []string{"NET_ADMIN", "SYS_TIME"}) || !h.Privileged {
+				t.Errorf("cap add %q, privileged %v", h.CapAdd, h.Privileged)
+			}
+		}, ""},
+		// Zero is accepted and means no limit.
+		{"zero limits", func(f *containerForm) {
+			f.memory.SetText("0")
+			f.cpus.SetText("0")
+			f.cpuShares.SetText("0")
+		}, func(t *testing.T, spec dashboard.ContainerSpec) {
+			if h := spec.HostConfig; h.Memory != 0 || h.NanoCPUs != 0 || h.CPUShares != 0 {
+				t.Errorf("limits %d, %d, %d", h.Memory, h.NanoCPUs, h.CPUShares)
+			}
+		}, ""},
+		// Max retries only apply to the on-failure policy.
+		{"retries ignored", func(f *containerForm) {
+			f.restart.SetSelected("always")
+			f.maxRetries.SetText("many")
+		}, func(t *testing.T, spec dashboard.ContainerSpec) {
+			if p := spec.HostConfig.RestartPolicy; p.Name != "always" || p.MaximumRetryCount != 0 {
+				t.Errorf("restart policy %+v", p)
+			}
+		}, ""},
+		{"no image", func(f *containerForm) { f.image.SetText("  ") }, nil, "enter an image"},
+		{"bad command", func(f *containerForm) { f.cmd.SetText(`echo "open`) }, nil, "command:"},
+		{"bad entrypoint", func(f *containerForm) { f.entrypoint.SetText(`'open`) }, nil, "entrypoint:"},
+		{"bad memory", func(f *containerForm) { f.memory.SetText("-5") }, nil, "memory must be"},
+		{"negative cpus", func(f *containerForm) { f.cpus.SetText("-1") }, nil, "CPUs must be a non-negative number"},
+		{"infinite cpus", func(f *containerForm) { f.cpus.SetText("Inf") }, nil, "CPUs must be a non-negative number"},
+		{"bad cpu shares", func(f *containerForm) { f.cpuShares.SetText("-2") }, nil, "CPU Shares must be"},
+		{"bad retries", func(f *containerForm) {
+			f.restart.SetSelected("on-failure")
+			f.maxRetries.SetText("-1")
+		}, nil, "Max retries must be"},
+		{"bad port", func(f *containerForm) { f.ports.add("8080", "") }, nil, "enter the container port"},
+		{"bad mount", func(f *containerForm) { f.mounts.add("data", "relative", "") }, nil, "must be absolute"},
+	}
+	for _, tt := range tests {
+		f := newTestContainerForm(t)
+		tt.setup(f)
+		spec, err := f.spec()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		tt.check(t, spec)
+	}
+}
+
+func TestContainerFormImport(t *testing.T) {
+	run, err := dashboard.ParseRunCommand(`docker run -d --name web -p 127.0.0.1:8080:80 -p 53:53/udp -v html:/usr/share/nginx/html:ro -v /cache -e MODE=prod -m 1g --cpus 0.5 --restart on-failure:2 --network backend --expose 9000 nginx`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newTestContainerForm(t)
+	f.fill(run.Spec)
+	if got := f.ports.values(); !reflect.DeepEqual(got, [][]string{{"53", "53/udp"}, {"127.0.0.1:8080", "80"}}) {
+		t.Errorf("port rows = %q", got)
+	}
+	if got := f.mounts.values(); !reflect.DeepEqual(got, [][]string{{"html", "/usr/share/nginx/html", "ro"}, {"", "/cache", ""}}) {
+		t.Errorf("mount rows = %q", got)
+	}
+	if f.memory.Text != "1g" || f.cpus.Text != "0.5" || f.maxRetries.Text != "2" || f.network.Selected != "backend" {
+		t.Errorf("memory %q, cpus %q, retries %q, network %q", f.memory.Text, f.cpus.Text, f.maxRetries.Text, f.network.Selected)
+	}
+
+	// The form gives back what was imported, including ports that were
+	// only exposed.
+	spec, err := f.spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(spec.HostConfig.PortBindings, run.Spec.HostConfig.PortBindings) {
+		t.Errorf("port bindings = %v, want %v", spec.HostConfig.PortBindings, run.Spec.HostConfig.PortBindings)
+	}
+	if !reflect.DeepEqual(spec.Config.ExposedPorts, run.Spec.Config.ExposedPorts) {
+		t.Errorf("exposed ports = %v, want %v", spec.Config.ExposedPorts, run.Spec.Config.ExposedPorts)
+	}
+	if !reflect.DeepEqual(spec.HostConfig.Binds, run.Spec.HostConfig.Binds) || !reflect.DeepEqual(spec.Config.Volumes, run.Spec.Config.Volumes) {
+		t.Errorf("binds %q, volumes %v", spec.HostConfig.Binds, spec.Config.Volumes)
+	}
+	if spec.HostConfig.Memory != run.Spec.HostConfig.Memory || spec.HostConfig.NanoCPUs != run.Spec.HostConfig.NanoCPUs ||
+		spec.HostConfig.RestartPolicy != run.Spec.HostConfig.RestartPolicy || spec.HostConfig.NetworkMode != "backend" {
+		t.Errorf("host config = %+v", spec.HostConfig)
+	}
+}
@@ -0,0 +1,105 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+)
+
+// DefaultDetachKeys is Docker's default sequence for detaching from a
+// container without stopping it.
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// ParseDetachKeys converts a sequence such as "ctrl-p,ctrl-q" to the bytes
+// a terminal sends for it. Keys are single characters or "ctrl-" followed by
+// a letter or one of @[\]^_. An empty sequence means DefaultDetachKeys, as
+// it does for the daemon.
+func ParseDetachKeys(keys string) ([]byte, error) {
+	if strings.TrimSpace(keys) == "" {
+		keys = DefaultDetachKeys
+	}
+	var seq []byte
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		switch {
+		case len(key) == 1:
+			seq = append(seq, key[0])
+		case len(key) == 6 && strings.HasPrefix(strings.ToLower(key), "ctrl-"):
+			c := strings.ToLower(key)[5]
+			switch {
+			case c >= 'a' && c <= 'z':
+				seq = append(seq, c-'a'+1)
+			case strings.IndexByte("@[\\]^_", c) >= 0:
+				seq = append(seq, c-'@')
+			default:
+				return nil, fmt.Errorf("invalid detach key %q", key)
+			}
+		default:
+			return nil, fmt.Errorf("invalid detach key %q", key)
+		}
+	}
+	return seq, nil
+}
+
+// Attach connects to the main process of a running container. Output is
+// always streamed; stdin is attached only if the container was created with
+// it open (docker run -i). The daemon ends the session, leaving the
+// container running, when the detach keys are typed.
+func (s *Service) Attach(ctx context.Context, id, detachKeys string) (*Session, error) {
+	seq, err := ParseDetachKeys(detachKeys)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, resourceError("attach to", "container", id, err)
+	}
+	if info.State == nil || !info.State.Running {
+		return nil, fmt.Errorf("attach to container %s: container is not running", shortID(id))
+	}
+	stdin := info.Config != nil && info.Config.OpenStdin
+	tty := info.Config != nil && info.Config.Tty
+
+	conn, err := s.cli.ContainerAttach(ctx, id, dockerContainer.AttachOptions{
+		Stream:     true,
+		Stdin:      stdin,
+		Stdout:     true,
+		Stderr:     true,
+		DetachKeys: detachKeys,
+	})
+	if err != nil {
+		return nil, resourceError("attach to", "container", id, err)
+	}
+	return &Session{
+		conn:   conn,
+		tty:    tty,
+		stdin:  stdin,
+		detach: seq,
+		resize: func(ctx context.Context, rows, cols uint) error {
+			return s.cli.ContainerResize(ctx, id, dockerContainer.ResizeOptions{Height: rows, Width: cols})
+		},
+	}, nil
+}
+
+// Stdin reports whether input written to the session reaches the process.
+func (sess *Session) Stdin() bool {
+	return sess.stdin
+}
+
+// Detach asks the daemon to end an attach session while leaving the
+// container running.
+func (sess *Session) Detach() error {
+	if !sess.stdin || len(sess.detach) == 0 {
+		return errors.New("detaching needs stdin to be attached")
+	}
+	_, err := sess.conn.Conn.Write(sess.detach)
+	return err
+}
+
+// CloseInput sends end-of-file to the process's stdin.
+func (sess *Session) CloseInput() error {
+	return sess.conn.CloseWrite()
+}
@@ -0,0 +1,82 @@
+package dashboard
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestParseDetachKeys(t *testing.T) {
+	tests := []struct {
+		keys string
+		want []byte
+		ok   bool
+	}{
+		{DefaultDetachKeys, []byte{0x10, 0x11}, true},
+		{"", []byte{0x10, 0x11}, true},
+		{"  ", []byte{0x10, 0x11}, true},
+		{"CTRL-P, Ctrl-Q", []byte{0x10, 0x11}, true},
+		{"a", []byte{'a'}, true},
+		{"ctrl-a,x,ctrl-z", []byte{0x01, 'x', 0x1a}, true},
+		{"ctrl-@", []byte{0x00}, true},
+		{"ctrl-[", []byte{0x1b}, true},
+		{`ctrl-\,ctrl-],ctrl-^,ctrl-_`, []byte{0x1c, 0x1d, 0x1e, 0x1f}, true},
+		{",", nil, false},
+		{"ctrl-p,", nil, false},
+		{"ab", nil, false},
+		{"ctrl-", nil, false},
+		{"ctrl-1", nil, false},
+		{"ctrl-pq", nil, false},
+		{"alt-p", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDetachKeys(tt.keys)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseDetachKeys(%q) error = %v, want ok %v", tt.keys, err, tt.ok)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("ParseDetachKeys(%q) = %v, want %v", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestSessionDetach(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdin  bool
+		detach []byte
+		ok     bool
+	}{
+		{"stdin", true, []byte{0x10, 0x11}, true},
+		{"no stdin", false, []byte{0x10, 0x11}, false},
+		// Exec sessions have no detach keys.
+		{"no keys", true, nil, false},
+	}
+	for _, tt := range tests {
+		client, server := net.Pipe()
+		sess := &Session{conn: types.HijackedResponse{Conn: client}, stdin: tt.stdin, detach: tt.detach}
+		sent := make(chan []byte)
+		go func() {
+			data, _ := io.ReadAll(server)
+			sent <- data
+		}()
+		err := sess.Detach()
+		client.Close()
+		data := <-sent
+		server.Close()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: Detach() error = %v, want ok %v", tt.name, err, tt.ok)
+		}
+		want := tt.detach
+		if !tt.ok {
+			want = nil
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("%s: sent %v, want %v", tt.name, data, want)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package dashboard
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// buildErrorLines is how many lines of the failing step's output a
+// BuildError keeps.
+const buildErrorLines = 15
+
+// BuildSpec describes an image build.
+type BuildSpec struct {
+	// ContextDir is the directory sent to the daemon as the build context.
+	ContextDir string
+	// Dockerfile is the Dockerfile's path, relative to ContextDir or
+	// absolute; it may lie outside the context. Empty means "Dockerfile".
+	Dockerfile string
+	Tags       []string
+	BuildArgs  map[string]string
+	Target     string
+	Labels     map[string]string
+	NoCache    bool
+	// Pull always pulls newer versions of the base images.
+	Pull bool
+}
+
+// BuildProgress is the state of a running build.
+type BuildProgress struct {
+	// Step and Steps count the Dockerfile instructions, e.g. 3 of 7; both
+	// are 0 before the first step.
+	Step, Steps int
+	Instruction string // e.g. "RUN make"
+	// Line is the output line just received.
+	Line string
+	// ImageID is set once the image has been written.
+	ImageID string
+}
+
+// BuildError is a failed build, pointing at the step that failed.
+type BuildError struct {
+	Step, Steps int
+	Instruction string
+	Message     string
+	// Output holds the last lines the failing step printed.
+	Output []string
+}
+
+func (e *BuildError) Error() string {
+	if e.Step == 0 {
+		return "build failed: " + e.Message
+	}
+	return fmt.Sprintf("build failed at step %d/%d (%s): %s", e.Step, e.Steps, e.Instruction, e.Message)
+}
+
+// stepPattern matches the classic builder's step headers, e.g.
+// "Step 3/7 : RUN make".
+var stepPattern = regexp.MustCompile(`^Step (\d+)/(\d+) : (.*)$`)
+
+// BuildImage builds an image from spec, calling handle for every line of
+// output. Credentials for the base images' registries are attached. It
+// returns the new image's ID; a failing instruction is reported as a
+// *BuildError.
+func (s *Service) BuildImage(ctx context.Context, spec BuildSpec, handle func(BuildProgress)) (string, error) {
+	dockerfile, content, err := readDockerfile(spec)
+	if err != nil {
+		return "", err
+	}
+	buildContext, dockerfileName, err := ContextTar(spec.ContextDir, dockerfile, content)
+	if err != nil {
+		return "", err
+	}
+	defer buildContext.Close()
+
+	args := make(map[string]*string, len(spec.BuildArgs))
+	for k, v := range spec.BuildArgs {
+		args[k] = &v
+	}
+	resp, err := s.cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        spec.Tags,
+		Dockerfile:  dockerfileName,
+		BuildArgs:   args,
+		Target:      spec.Target,
+		Labels:      spec.Labels,
+		NoCache:     spec.NoCache,
+		PullParent:  spec.Pull,
+		Remove:      true,
+		AuthConfigs: s.baseImageAuth(ctx, content),
+	})
+	if err != nil {
+		return "", fmt.Errorf("build image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var progress BuildProgress
+	var stepOutput []string
+	emit := func(line string) {
+		if m := stepPattern.FindStringSubmatch(line); m != nil {
+			progress.Step, _ = strconv.Atoi(m[1])
+			progress.Steps, _ = strconv.Atoi(m[2])
+			progress.Instruction = m[3]
+			stepOutput = nil
+		} else {
+			stepOutput = append(stepOutput, line)
+			if len(stepOutput) > buildErrorLines {
+				stepOutput = stepOutput[1:]
+			}
+		}
+		if id, ok := strings.CutPrefix(line, "Successfully built "); ok {
+			progress.ImageID = id
+		}
+		progress.Line = line
+		if handle != nil {
+			handle(progress)
+		}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	var partial string
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return "", fmt.Errorf("build image: %w", err)
+		}
+		if msg.Error != nil || msg.ErrorMessage != "" {
+			message := msg.ErrorMessage
+			if msg.Error != nil {
+				message = msg.Error.Message
+			}
+			return "", &BuildError{
+				Step: progress.Step, Steps: progress.Steps, Instruction: progress.Instruction,
+				Message: message, Output: stepOutput,
+			}
+		}
+		if msg.Aux != nil {
+			var aux struct{ ID string }
+			if json.Unmarshal(*msg.Aux, &aux) == nil && aux.ID != "" {
+				progress.ImageID = aux.ID
+			}
+		}
+		// Byte counts of base image downloads would flood the output.
+		if msg.Progress != nil && msg.Progress.Total > 0 {
+			continue
+		}
+		// Output arrives in chunks that need not end at a line break.
+		text := partial + msg.Stream
+		if msg.Stream == "" && msg.Status != "" {
+			text = partial + strings.TrimSpace(msg.ID+" "+msg.Status) + "\n"
+		}
+		lines := strings.Split(text, "\n")
+		partial = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			emit(strings.TrimRight(line, "\r"))
+		}
+	}
+	if partial != "" {
+		emit(partial)
+	}
+	return progress.ImageID, nil
+}
+
+// readDockerfile resolves spec's Dockerfile path and reads it.
+func readDockerfile(spec BuildSpec) (path string, content []byte, err error) {
+	if spec.ContextDir == "" {
+		return "", nil, errors.New("build image: no context directory given")
+	}
+	path = spec.Dockerfile
+	if path == "" {
+		path = "Dockerfile"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(spec.ContextDir, path)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("build image: %w", err)
+	}
+	return path, content, nil
+}
+
+// baseImageAuth returns credentials for the registries the Dockerfile's
+// FROM lines pull from. Images named through build args are skipped.
+func (s *Service) baseImageAuth(ctx context.Context, dockerfile []byte) map[string]registry.AuthConfig {
+	if s.auth == nil {
+		return nil
+	}
+	configs := map[string]registry.AuthConfig{}
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		image := ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "--") {
+				image = f
+				break
+			}
+		}
+		if image == "" || strings.Contains(image, "$") {
+			continue
+		}
+		cred, ok, err := s.auth.Lookup(ctx, ReferenceRegistry(image))
+		if err != nil || !ok {
+			continue
+		}
+		auth := cred.authConfig()
+		configs[auth.ServerAddress] = auth
+	}
+	return configs
+}
+
+// =============================================================================
+// Build context
+// =============================================================================
+
+// ContextTar streams dir as a build context, leaving out the paths
+// .dockerignore excludes. The Dockerfile is always sent; when it lies
+// outside dir, content is added under a generated name. It returns the
+// Dockerfile's name within the context.
+func ContextTar(dir, dockerfile string, content []byte) (io.ReadCloser, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	ignore, err := readDockerignore(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	dockerfileName := ""
+	if abs, err := filepath.Abs(dockerfile); err == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			dockerfileName = filepath.ToSlash(rel)
+		}
+	}
+	// The daemon needs the Dockerfile and .dockerignore even if ignored.
+	keep := map[string]bool{".dockerignore": true}
+	var extra []byte
+	if dockerfileName != "" {
+		keep[dockerfileName] = true
+	} else {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, "", err
+		}
+		dockerfileName = ".dockerfile." + hex.EncodeToString(suffix)
+		extra = content
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := writeContext(tw, dir, ignore, keep)
+		if err == nil && extra != nil {
+			err = tw.WriteHeader(&tar.Header{Name: dockerfileName, Mode: 0o644, Size: int64(len(extra)), Typeflag: tar.TypeReg})
+			if err == nil {
+				_, err = tw.Write(extra)
+			}
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, dockerfileName, nil
+}
+
+func writeContext(tw *tar.Writer, dir string, ignore *ignoreMatcher, keep map[string]bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !keep[name] && ignore.excludes(name) {
+			// An exception or a kept file may still bring back something
+			// inside.
+			if d.IsDir() && !ignore.hasExceptions() && !keepsInside(keep, name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			// Sockets, devices and pipes cannot be sent.
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		// Files belong to root in the image, as with the Docker CLI.
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+func keepsInside(keep map[string]bool, dir string) bool {
+	for name := range keep {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ignorePattern is one line of a .dockerignore file.
+type ignorePattern struct {
+	re        *regexp.Regexp
+	exception bool // the line started with "!"
+}
+
+// ignoreMatcher applies .dockerignore patterns: the last pattern matching a
+// path, or one of its parent directories, decides whether it is excluded.
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// readDockerignore reads dir/.dockerignore; a missing file excludes
+// nothing.
+func readDockerignore(dir string) (*ignoreMatcher, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".dockerignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &ignoreMatcher{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read .dockerignore: %w", err)
+	}
+	return parseDockerignore(data)
+}
+
+func parseDockerignore(data []byte) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			p.exception, line = true, strings.TrimSpace(rest)
+		}
+		line = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(line)), "/")
+		if line == "." || line == "" {
+			continue
+		}
+		re, err := ignoreRegexp(line)
+		if err != nil {
+			return nil, fmt.Errorf(".dockerignore pattern %q: %w", line, err)
+		}
+		p.re = re
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// ignoreRegexp translates a pattern as Docker does: * and ? stay within a
+// path element, ** spans any number of them, and [...] and \ work as in
+// filepath.Match.
+func ignoreRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// excludes reports whether the slash-separated path is left out of the
+// context.
+func (m *ignoreMatcher) excludes(path string) bool {
+	excluded := false
+	for _, p := range m.patterns {
+		if p.matches(path) {
+			excluded = !p.exception
+		}
+	}
+	return excluded
+}
+
+func (m *ignoreMatcher) hasExceptions() bool {
+	for _, p := range m.patterns {
+		if p.exception {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the pattern matches path or one of its parent
+// directories.
+func (p ignorePattern) matches(path string) bool {
+	for {
+		if p.re.MatchString(path) {
+			return true
+		}
+		i := strings.LastIndexByte(path, '/')
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
@@ -0,0 +1,229 @@
+package dashboard
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		match         bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "logs/app.log", false},
+		{"*/*.log", "logs/app.log", true},
+		{"**/*.log", "app.log", true},
+		{"**/*.log", "a/b/c/app.log", true},
+		{"**", "a/b", true},
+		{"docs/**", "docs/a/b.md", true},
+		{"docs/**/*.md", "docs/b.md", true},
+		{"docs/**/*.md", "docs/a/b/c.md", true},
+		{"docs/**/*.md", "src/b.md", false},
+		{"?.txt", "a.txt", true},
+		{"?.txt", "ab.txt", false},
+		{"?", "/", false},
+		{"file[0-9].txt", "file7.txt", true},
+		{"file[0-9].txt", "filex.txt", false},
+		{"file[!0-9].txt", "filex.txt", true},
+		{"file[^0-9].txt", "file1.txt", false},
+		{`\*.txt`, "*.txt", true},
+		{`\*.txt`, "a.txt", false},
+		{"a.b", "axb", false},
+		{"a+b(c)", "a+b(c)", true},
+	}
+	for _, tt := range tests {
+		re, err := ignoreRegexp(tt.pattern)
+		if err != nil {
+			t.Errorf("ignoreRegexp(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.path, got, tt.match)
+		}
+	}
+	if _, err := ignoreRegexp("file[0-9"); err == nil {
+		t.Error("an unterminated class is accepted")
+	}
+}
+
+func TestDockerignoreExcludes(t *testing.T) {
+	m, err := parseDockerignore([]byte(`
+# comment
+  node_modules
+/build
+*.log
+!important.log
+**/tmp
+docs/*
+!docs/README.md
+./cache/
+!
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path     string
+		excluded bool
+	}{
+		{"node_modules", true},
+		{"node_modules/x/index.js", true},
+		{"src/node_modules", false},
+		{"build", true},
+		{"build/out.bin", true},
+		{"debug.log", true},
+		{"important.log", false},
+		{"logs/debug.log", false},
+		{"tmp", true},
+		{"a/b/tmp/file", true},
+		{"docs/guide.md", true},
+		{"docs/README.md", false},
+		{"cache/x", true},
+		{"src/main.go", false},
+		{"# comment", false},
+	}
+	for _, tt := range tests {
+		if got := m.excludes(tt.path); got != tt.excluded {
+			t.Errorf("excludes(%q) = %v, want %v", tt.path, got, tt.excluded)
+		}
+	}
+	if !m.hasExceptions() {
+		t.Error("hasExceptions = false")
+	}
+	if _, err := parseDockerignore([]byte("[oops")); err == nil {
+		t.Error("a bad pattern is accepted")
+	}
+}
+
+// writeTree creates files (ending in / for directories) under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readContext returns the entries of a build context with their content.
+func readContext(t *testing.T, r io.ReadCloser) map[string]string {
+	t.Helper()
+	defer r.Close()
+	entries := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[hdr.Name] = string(data)
+	}
+}
+
+func TestContextTar(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".dockerignore":        "Dockerfile\n.dockerignore\nnode_modules\ndist\n!dist/app.js\n*.log\n",
+		"Dockerfile":           "FROM alpine\n",
+		"main.go":              "package main\n",
+		"debug.log":            "noise",
+		"node_modules/x/a.js":  "x",
+		"dist/app.js":          "app",
+		"dist/app.js.map":      "map",
+		"dist/assets/logo.svg": "<svg/>",
+		"empty/":               "",
+	})
+
+	r, name, err := ContextTar(dir, filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Dockerfile" {
+		t.Errorf("Dockerfile name = %q", name)
+	}
+	entries := readContext(t, r)
+	var names []string
+	for n := range entries {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	// The Dockerfile and .dockerignore are sent although ignored; the dist
+	// directory is searched for the exception although it is excluded.
+	want := []string{".dockerignore", "Dockerfile", "dist/app.js", "empty/", "main.go"}
+	if !slices.Equal(names, want) {
+		t.Errorf("context = %q, want %q", names, want)
+	}
+	if entries["dist/app.js"] != "app" {
+		t.Errorf("dist/app.js = %q", entries["dist/app.js"])
+	}
+}
+
+func TestContextTarKeepsNestedDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".dockerignore":           "build\n",
+		"build/docker/Dockerfile": "FROM alpine\n",
+		"build/other":             "x",
+		"src/main.go":             "package main\n",
+	})
+	r, name, err := ContextTar(dir, filepath.Join(dir, "build", "docker", "Dockerfile"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "build/docker/Dockerfile" {
+		t.Errorf("Dockerfile name = %q", name)
+	}
+	entries := readContext(t, r)
+	if _, ok := entries["build/docker/Dockerfile"]; !ok {
+		t.Errorf("the ignored Dockerfile is missing from %v", entries)
+	}
+	if _, ok := entries["build/other"]; ok {
+		t.Error("build/other is sent")
+	}
+}
+
+func TestContextTarDockerfileOutside(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"main.go": "package main\n"})
+	outside := filepath.Join(t.TempDir(), "Dockerfile")
+	content := "FROM golang\nCOPY . .\n"
+
+	r, name, err := ContextTar(dir, outside, []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, ".dockerfile.") {
+		t.Errorf("Dockerfile name = %q", name)
+	}
+	entries := readContext(t, r)
+	if entries[name] != content {
+		t.Errorf("%s = %q, want the Dockerfile", name, entries[name])
+	}
+	if _, ok := entries["main.go"]; !ok {
+		t.Errorf("main.go is missing from %v", entries)
+	}
+}
@@ -0,0 +1,37 @@
+package dashboard
+
+import (
+	"context"
+	"sync"
+)
+
+// BulkWorkers bounds how many actions of a bulk operation run at once, so
+// removing a hundred images does not open a hundred requests to the daemon.
+const BulkWorkers = 4
+
+// RunBulk calls fn for items 0 to n-1 on at most workers goroutines and
+// returns each item's error by index. Items not yet started when ctx is
+// cancelled fail with its error.
+func RunBulk(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBulk(t *testing.T) {
+	tests := []struct {
+		n, workers int
+		limit      int // the most items allowed to run at once
+	}{
+		{0, 4, 0},
+		{1, 4, 1},
+		{10, 4, 4},
+		{10, 1, 1},
+		// A bound below one runs the items one at a time.
+		{5, 0, 1},
+		{5, -2, 1},
+	}
+	for _, tt := range tests {
+		var active, peak atomic.Int32
+		var mu sync.Mutex
+		called := map[int]int{}
+		errs := RunBulk(context.Background(), tt.n, tt.workers, func(ctx context.Context, i int) error {
+			now := active.Add(1)
+			defer active.Add(-1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			called[i]++
+			mu.Unlock()
+			if i%3 == 1 {
+				return fmt.Errorf("item %d", i)
+			}
+			return nil
+		})
+		if len(errs) != tt.n {
+			t.Errorf("n=%d: %d errors returned", tt.n, len(errs))
+			continue
+		}
+		if p := int(peak.Load()); p > tt.limit {
+			t.Errorf("n=%d workers=%d: %d items ran at once, want at most %d", tt.n, tt.workers, p, tt.limit)
+		}
+		for i, err := range errs {
+			if called[i] != 1 {
+				t.Errorf("n=%d: item %d called %d times", tt.n, i, called[i])
+			}
+			// Each error lands at its item's index.
+			want := "<nil>"
+			if i%3 == 1 {
+				want = fmt.Sprintf("item %d", i)
+			}
+			if fmt.Sprint(err) != want {
+				t.Errorf("n=%d: errs[%d] = %v, want %q", tt.n, i, err, want)
+			}
+		}
+	}
+}
+
+func TestRunBulkCancelled(t *testing.T) {
+	// Nothing runs when the context is cancelled before the start.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int32
+	errs := RunBulk(ctx, 5, 2, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	if calls.Load() != 0 {
+		t.Errorf("%d items ran after cancellation", calls.Load())
+	}
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("errs[%d] = %v, want context.Canceled", i, err)
+		}
+	}
+
+	// Cancelling midway fails the items not started yet; one worker runs
+	// them in order.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	errs = RunBulk(ctx, 5, 1, func(ctx context.Context, i int) error {
+		if i == 1 {
+			cancel()
+		}
+		return nil
+	})
+	for i, err := range errs {
+		if wantCancelled := i > 1; errors.Is(err, context.Canceled) != wantCancelled {
+			t.Errorf("cancelled after item 1: errs[%d] = %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,849 @@
+package dashboard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Labels the docker compose CLI puts on the resources of a project. The
+// dashboard uses the same ones so that either tool can manage a stack.
+const (
+	ComposeProjectLabel    = "com.docker.compose.project"
+	ComposeServiceLabel    = "com.docker.compose.service"
+	ComposeNumberLabel     = "com.docker.compose.container-number"
+	ComposeOneoffLabel     = "com.docker.compose.oneoff"
+	ComposeConfigHashLabel = "com.docker.compose.config-hash"
+	ComposeFilesLabel      = "com.docker.compose.project.config_files"
+	ComposeWorkingDirLabel = "com.docker.compose.project.working_dir"
+	ComposeNetworkLabel    = "com.docker.compose.network"
+	ComposeVolumeLabel     = "com.docker.compose.volume"
+)
+
+// ComposeProject is a loaded compose file.
+type ComposeProject struct {
+	Name string
+	// File is the compose file and Dir its directory, against which
+	// relative paths resolve.
+	File     string
+	Dir      string
+	Services map[string]*ComposeService
+	Networks map[string]*ComposeNetwork
+	Volumes  map[string]*ComposeVolume
+
+	// env holds the variables available for interpolation and for
+	// environment entries without a value: the .env file overlaid with this
+	// process's environment.
+	env map[string]string
+}
+
+// ComposeService is one entry under services.
+type ComposeService struct {
+	Name          string                 `yaml:"-"`
+	Image         string                 `yaml:"image"`
+	Build         any                    `yaml:"build"`
+	ContainerName string                 `yaml:"container_name"`
+	Command       composeCommand         `yaml:"command"`
+	Entrypoint    composeCommand         `yaml:"entrypoint"`
+	Environment   composeMapping         `yaml:"environment"`
+	EnvFile       composeEnvFiles        `yaml:"env_file"`
+	Ports         []composePort          `yaml:"ports"`
+	Expose        []string               `yaml:"expose"`
+	Volumes       []composeServiceVolume `yaml:"volumes"`
+	Networks      composeServiceNetworks `yaml:"networks"`
+	NetworkMode   string                 `yaml:"network_mode"`
+	DependsOn     composeDependsOn       `yaml:"depends_on"`
+	Restart       string                 `yaml:"restart"`
+	Healthcheck   *ComposeHealthcheck    `yaml:"healthcheck"`
+	Labels        composeMapping         `yaml:"labels"`
+	WorkingDir    string                 `yaml:"working_dir"`
+	User          string                 `yaml:"user"`
+	Hostname      string                 `yaml:"hostname"`
+	Privileged    bool                   `yaml:"privileged"`
+	ReadOnly      bool                   `yaml:"read_only"`
+	Init          *bool                  `yaml:"init"`
+	CapAdd        []string               `yaml:"cap_add"`
+	CapDrop       []string               `yaml:"cap_drop"`
+	MemLimit      string                 `yaml:"mem_limit"`
+	MemswapLimit  string                 `yaml:"memswap_limit"`
+	Cpus          string                 `yaml:"cpus"`
+	CPUShares     int64                  `yaml:"cpu_shares"`
+	PidsLimit     int64                  `yaml:"pids_limit"`
+	Tty           bool                   `yaml:"tty"`
+	StdinOpen     bool                   `yaml:"stdin_open"`
+	ExtraHosts    composeList            `yaml:"extra_hosts"`
+	DNS           composeList            `yaml:"dns"`
+}
+
+// ComposeHealthcheck is a service's healthcheck section.
+type ComposeHealthcheck struct {
+	Test        composeHealthTest `yaml:"test"`
+	Interval    string            `yaml:"interval"`
+	Timeout     string            `yaml:"timeout"`
+	StartPeriod string            `yaml:"start_period"`
+	Retries     int               `yaml:"retries"`
+	Disable     bool              `yaml:"disable"`
+}
+
+// ComposeNetwork is one entry under networks.
+type ComposeNetwork struct {
+	Name     string          `yaml:"name"`
+	Driver   string          `yaml:"driver"`
+	Internal bool            `yaml:"internal"`
+	External composeExternal `yaml:"external"`
+	Labels   composeMapping  `yaml:"labels"`
+}
+
+// ComposeVolume is one entry under volumes.
+type ComposeVolume struct {
+	Name     string          `yaml:"name"`
+	Driver   string          `yaml:"driver"`
+	External composeExternal `yaml:"external"`
+	Labels   composeMapping  `yaml:"labels"`
+}
+
+// ComposeDependency is a depends_on entry.
+type ComposeDependency struct {
+	Service string
+	// Condition is service_started, service_healthy or
+	// service_completed_successfully.
+	Condition string
+}
+
+type composeFile struct {
+	Name     string                     `yaml:"name"`
+	Services map[string]*ComposeService `yaml:"services"`
+	Networks map[string]*ComposeNetwork `yaml:"networks"`
+	Volumes  map[string]*ComposeVolume  `yaml:"volumes"`
+}
+
+// LoadComposeFile reads a compose file, interpolating ${VAR} references
+// from the environment and the .env file next to it.
+func LoadComposeFile(path string) (*ComposeProject, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load compose file: %w", err)
+	}
+	dir := filepath.Dir(path)
+	env := map[string]string{}
+	if dotenv, err := os.ReadFile(filepath.Join(dir, ".env")); err == nil {
+		vars, err := parseDotEnv(dotenv)
+		if err != nil {
+			return nil, fmt.Errorf("load .env: %w", err)
+		}
+		for _, kv := range vars {
+			k, v, _ := strings.Cut(kv, "=")
+			env[k] = v
+		}
+	}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	p, err := ParseCompose(data, dir, env)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", filepath.Base(path), err)
+	}
+	p.File = path
+	return p, nil
+}
+
+// ParseCompose parses compose YAML. dir is the project directory and env
+// the variables available for interpolation.
+func ParseCompose(data []byte, dir string, env map[string]string) (*ComposeProject, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	if err := interpolateNode(&root, lookup); err != nil {
+		return nil, err
+	}
+	var file composeFile
+	if err := root.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	p := &ComposeProject{
+		Name:     file.Name,
+		Dir:      dir,
+		Services: file.Services,
+		Networks: file.Networks,
+		Volumes:  file.Volumes,
+		env:      env,
+	}
+	if name := env["COMPOSE_PROJECT_NAME"]; name != "" {
+		p.Name = name
+	}
+	if p.Name == "" {
+		p.Name = filepath.Base(dir)
+	}
+	p.Name = normalizeProjectName(p.Name)
+	if p.Name == "" {
+		return nil, fmt.Errorf("cannot derive a project name from %q; set name: in the file", dir)
+	}
+	if len(p.Services) == 0 {
+		return nil, fmt.Errorf("no services defined")
+	}
+	// Entries written as "name:" with no body decode to nil.
+	if p.Networks == nil {
+		p.Networks = map[string]*ComposeNetwork{}
+	}
+	for k, n := range p.Networks {
+		if n == nil {
+			p.Networks[k] = &ComposeNetwork{}
+		}
+	}
+	if p.Volumes == nil {
+		p.Volumes = map[string]*ComposeVolume{}
+	}
+	for k, v := range p.Volumes {
+		if v == nil {
+			p.Volumes[k] = &ComposeVolume{}
+		}
+	}
+	for name, svc := range p.Services {
+		if svc == nil {
+			return nil, fmt.Errorf("service %s is empty", name)
+		}
+		svc.Name = name
+		if svc.Image == "" {
+			if svc.Build != nil {
+				return nil, fmt.Errorf("service %s: building images is not supported; give it an image", name)
+			}
+			return nil, fmt.Errorf("service %s has no image", name)
+		}
+		for _, net := range svc.Networks {
+			if _, ok := p.Networks[net.Name]; !ok && net.Name != "default" {
+				return nil, fmt.Errorf("service %s uses undefined network %s", name, net.Name)
+			}
+		}
+	}
+	if _, err := p.ServiceOrder(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+var projectNameInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// normalizeProjectName lowercases name and drops the characters compose does
+// not allow in project names.
+func normalizeProjectName(name string) string {
+	name = projectNameInvalid.ReplaceAllString(strings.ToLower(name), "")
+	return strings.TrimLeft(name, "_-")
+}
+
+// ServiceNames returns the service names, sorted.
+func (p *ComposeProject) ServiceNames() []string {
+	return SortedKeys(p.Services)
+}
+
+// ServiceOrder returns the services sorted so that each comes after the
+// services it depends on. Independent services keep name order.
+func (p *ComposeProject) ServiceOrder() ([]*ComposeService, error) {
+	var order []*ComposeService
+	state := map[string]int{} // 1 visiting, 2 done
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		svc := p.Services[name]
+		state[name] = 1
+		for _, dep := range svc.DependsOn {
+			if _, ok := p.Services[dep.Service]; !ok {
+				return fmt.Errorf("service %s depends on undefined service %s", name, dep.Service)
+			}
+			if err := visit(dep.Service, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, svc)
+		return nil
+	}
+	for _, name := range p.ServiceNames() {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// ContainerName returns the name of the service's container.
+func (p *ComposeProject) ContainerName(svc *ComposeService) string {
+	if svc.ContainerName != "" {
+		return svc.ContainerName
+	}
+	return p.Name + "-" + svc.Name + "-1"
+}
+
+// NetworkName returns the Docker name of the network declared as key.
+func (p *ComposeProject) NetworkName(key string) string {
+	n := p.Networks[key]
+	switch {
+	case n != nil && n.Name != "":
+		return n.Name
+	case n != nil && n.External.External:
+		if n.External.Name != "" {
+			return n.External.Name
+		}
+		return key
+	}
+	return p.Name + "_" + key
+}
+
+// VolumeName returns the Docker name of the volume declared as key.
+func (p *ComposeProject) VolumeName(key string) string {
+	v := p.Volumes[key]
+	switch {
+	case v != nil && v.Name != "":
+		return v.Name
+	case v != nil && v.External.External:
+		if v.External.Name != "" {
+			return v.External.Name
+		}
+		return key
+	}
+	return p.Name + "_" + key
+}
+
+// serviceNetworks returns the network keys a service joins, with the
+// project's default network standing in when none are listed.
+func (p *ComposeProject) serviceNetworks(svc *ComposeService) composeServiceNetworks {
+	if svc.NetworkMode != "" {
+		return nil
+	}
+	if len(svc.Networks) == 0 {
+		return composeServiceNetworks{{Name: "default"}}
+	}
+	return svc.Networks
+}
+
+// UsedNetworks returns the network keys joined by at least one service,
+// sorted.
+func (p *ComposeProject) UsedNetworks() []string {
+	used := map[string]bool{}
+	for _, svc := range p.Services {
+		for _, n := range p.serviceNetworks(svc) {
+			used[n.Name] = true
+		}
+	}
+	return SortedKeys(used)
+}
+
+// =============================================================================
+// YAML forms
+// =============================================================================
+
+// composeCommand is a command given either as a string, split like a shell
+// would, or as a list.
+type composeCommand []string
+
+func (c *composeCommand) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		words, err := SplitCommandLine(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		*c = words
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// composeHealthTest is a healthcheck test: a string run by the shell, or a
+// list starting with CMD, CMD-SHELL or NONE as in a Dockerfile.
+type composeHealthTest []string
+
+func (t *composeHealthTest) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = []string{"CMD-SHELL", n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// composeList is a list that may also be written as a single string.
+type composeList []string
+
+func (l *composeList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = []string{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// composeMapping is a map written either as a mapping or as a list of
+// KEY=value items. A key without a value maps to nil.
+type composeMapping map[string]*string
+
+func (m *composeMapping) UnmarshalYAML(n *yaml.Node) error {
+	result := composeMapping{}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if v.Tag == "!!null" {
+				result[k.Value] = nil
+				continue
+			}
+			if v.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: value of %s must be a string", v.Line, k.Value)
+			}
+			value := v.Value
+			result[k.Value] = &value
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			k, v, ok := strings.Cut(item.Value, "=")
+			if !ok {
+				result[k] = nil
+				continue
+			}
+			result[k] = &v
+		}
+	default:
+		return fmt.Errorf("line %d: expected a mapping or a list", n.Line)
+	}
+	*m = result
+	return nil
+}
+
+// composeEnvFiles is env_file: one path, a list of paths, or a list of
+// {path, required} entries.
+type composeEnvFiles []composeEnvFile
+
+type composeEnvFile struct {
+	Path     string
+	Required bool
+}
+
+func (f *composeEnvFiles) UnmarshalYAML(n *yaml.Node) error {
+	items := []*yaml.Node{n}
+	if n.Kind == yaml.SequenceNode {
+		items = n.Content
+	}
+	var result composeEnvFiles
+	for _, item := range items {
+		if item.Kind == yaml.ScalarNode {
+			result = append(result, composeEnvFile{Path: item.Value, Required: true})
+			continue
+		}
+		entry := struct {
+			Path     string `yaml:"path"`
+			Required *bool  `yaml:"required"`
+		}{}
+		if err := item.Decode(&entry); err != nil {
+			return err
+		}
+		result = append(result, composeEnvFile{Path: entry.Path, Required: entry.Required == nil || *entry.Required})
+	}
+	*f = result
+	return nil
+}
+
+// composePort is a ports entry in short ("8080:80/udp") or long syntax,
+// kept in short syntax.
+type composePort string
+
+func (p *composePort) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*p = composePort(n.Value)
+		return nil
+	}
+	var long struct {
+		Target    string `yaml:"target"`
+		Published string `yaml:"published"`
+		HostIP    string `yaml:"host_ip"`
+		Protocol  string `yaml:"protocol"`
+	}
+	if err := n.Decode(&long); err != nil {
+		return err
+	}
+	if long.Target == "" {
+		return fmt.Errorf("line %d: port has no target", n.Line)
+	}
+	spec := long.Target
+	if long.Protocol != "" {
+		spec += "/" + long.Protocol
+	}
+	if long.Published != "" {
+		spec = long.Published + ":" + spec
+	}
+	if long.HostIP != "" {
+		spec = long.HostIP + ":" + spec
+	}
+	*p = composePort(spec)
+	return nil
+}
+
+// composeServiceVolume is a service volumes entry in short or long syntax.
+type composeServiceVolume struct {
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only"`
+	// Mode holds the options of the short syntax, such as "ro".
+	Mode  string `yaml:"-"`
+	short bool
+}
+
+func (v *composeServiceVolume) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		type plain composeServiceVolume
+		if err := n.Decode((*plain)(v)); err != nil {
+			return err
+		}
+		if v.Type == "" {
+			v.Type = "volume"
+		}
+		if v.Target == "" {
+			return fmt.Errorf("line %d: volume has no target", n.Line)
+		}
+		return nil
+	}
+	parts := splitShortVolume(n.Value)
+	switch len(parts) {
+	case 1:
+		*v = composeServiceVolume{Type: "volume", Target: parts[0], short: true}
+		return nil
+	case 2, 3:
+		*v = composeServiceVolume{Source: parts[0], Target: parts[1], short: true}
+		if len(parts) == 3 {
+			v.Mode = parts[2]
+		}
+	default:
+		return fmt.Errorf("line %d: bad volume %q", n.Line, n.Value)
+	}
+	v.Type = "volume"
+	if isPathSource(v.Source) {
+		v.Type = "bind"
+	}
+	return nil
+}
+
+// windowsDrivePath matches an absolute Windows path such as C:\data or C:/data.
+var windowsDrivePath = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+
+// splitShortVolume splits a short volume entry on its colons, keeping the
+// drive letter of a Windows path with the rest of the path.
+func splitShortVolume(s string) []string {
+	var parts []string
+	for {
+		skip := 0
+		if windowsDrivePath.MatchString(s) {
+			skip = 2
+		}
+		i := strings.IndexByte(s[skip:], ':')
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:skip+i])
+		s = s[skip+i+1:]
+	}
+}
+
+// isPathSource reports whether a short volume source is a host path rather
+// than a volume name.
+func isPathSource(src string) bool {
+	return strings.HasPrefix(src, "/") || strings.HasPrefix(src, ".") || strings.HasPrefix(src, "~") ||
+		windowsDrivePath.MatchString(src)
+}
+
+// composeServiceNetworks is a service networks entry: a list of names or a
+// mapping of names to options.
+type composeServiceNetworks []composeServiceNetwork
+
+type composeServiceNetwork struct {
+	Name    string
+	Aliases []string
+}
+
+func (s *composeServiceNetworks) UnmarshalYAML(n *yaml.Node) error {
+	var result composeServiceNetworks
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			result = append(result, composeServiceNetwork{Name: item.Value})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			net := composeServiceNetwork{Name: n.Content[i].Value}
+			var opts struct {
+				Aliases []string `yaml:"aliases"`
+			}
+			if err := n.Content[i+1].Decode(&opts); err != nil {
+				return err
+			}
+			net.Aliases = opts.Aliases
+			result = append(result, net)
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	default:
+		return fmt.Errorf("line %d: expected a list or mapping of networks", n.Line)
+	}
+	*s = result
+	return nil
+}
+
+// composeDependsOn is depends_on: a list of services or a mapping of
+// services to conditions.
+type composeDependsOn []ComposeDependency
+
+func (d *composeDependsOn) UnmarshalYAML(n *yaml.Node) error {
+	var result composeDependsOn
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			result = append(result, ComposeDependency{Service: item.Value, Condition: "service_started"})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			var opts struct {
+				Condition string `yaml:"condition"`
+			}
+			if err := n.Content[i+1].Decode(&opts); err != nil {
+				return err
+			}
+			switch opts.Condition {
+			case "":
+				opts.Condition = "service_started"
+			case "service_started", "service_healthy", "service_completed_successfully":
+			default:
+				return fmt.Errorf("line %d: unknown depends_on condition %q", n.Content[i+1].Line, opts.Condition)
+			}
+			result = append(result, ComposeDependency{Service: n.Content[i].Value, Condition: opts.Condition})
+		}
+	default:
+		return fmt.Errorf("line %d: expected a list or mapping of services", n.Line)
+	}
+	*d = result
+	return nil
+}
+
+// composeExternal is external: true, or the older external: {name: x}.
+type composeExternal struct {
+	External bool
+	Name     string
+}
+
+func (e *composeExternal) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		return n.Decode(&e.External)
+	}
+	var opts struct {
+		Name string `yaml:"name"`
+	}
+	if err := n.Decode(&opts); err != nil {
+		return err
+	}
+	*e = composeExternal{External: true, Name: opts.Name}
+	return nil
+}
+
+// =============================================================================
+// Interpolation and .env files
+// =============================================================================
+
+// interpolateNode replaces variable references in every scalar value of the
+// document. Mapping keys are left alone, as compose does.
+func interpolateNode(n *yaml.Node, lookup func(string) (string, bool)) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		value, err := Interpolate(n.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		n.Value = value
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := interpolateNode(n.Content[i], lookup); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range n.Content {
+			if err := interpolateNode(child, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// Interpolate expands $VAR and ${VAR} references in s the way compose does,
+// including the ${VAR:-default}, ${VAR-default}, ${VAR:?error},
+// ${VAR?error}, ${VAR:+replacement} and ${VAR+replacement} forms. $$ is a
+// literal dollar sign. Unset variables expand to the empty string.
+func Interpolate(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := matchingBrace(s, i+1)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable in %q", s)
+			}
+			value, err := expandBraced(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		default:
+			name := variableName.FindString(s[i+1:])
+			if name == "" {
+				b.WriteByte('$')
+				continue
+			}
+			value, _ := lookup(name)
+			b.WriteString(value)
+			i += len(name)
+		}
+	}
+	return b.String(), nil
+}
+
+// matchingBrace returns the index of the } closing the { at open.
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func expandBraced(expr string, lookup func(string) (string, bool)) (string, error) {
+	name := variableName.FindString(expr)
+	if name == "" {
+		return "", fmt.Errorf("bad variable ${%s}", expr)
+	}
+	value, set := lookup(name)
+	rest := expr[len(name):]
+	if rest == "" {
+		return value, nil
+	}
+	// A colon makes the empty string count as unset.
+	unset := !set
+	if strings.HasPrefix(rest, ":") {
+		unset = unset || value == ""
+		rest = rest[1:]
+	}
+	if rest == "" {
+		return "", fmt.Errorf("bad variable ${%s}", expr)
+	}
+	arg, err := Interpolate(rest[1:], lookup)
+	if err != nil {
+		return "", err
+	}
+	switch rest[0] {
+	case '-':
+		if unset {
+			return arg, nil
+		}
+		return value, nil
+	case '?':
+		if unset {
+			if arg == "" {
+				arg = "is not set"
+			}
+			return "", fmt.Errorf("required variable %s: %s", name, arg)
+		}
+		return value, nil
+	case '+':
+		if unset {
+			return "", nil
+		}
+		return arg, nil
+	}
+	return "", fmt.Errorf("bad variable ${%s}", expr)
+}
+
+// parseDotEnv parses a .env or env_file file into KEY=value items: one
+// per line, # comments, an optional "export " prefix and optional quotes
+// around the value.
+func parseDotEnv(data []byte) ([]string, error) {
+	var vars []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			if v[0] == '"' {
+				if unquoted, err := strconv.Unquote(v); err == nil {
+					v = unquoted
+				} else {
+					v = v[1 : len(v)-1]
+				}
+			} else {
+				v = v[1 : len(v)-1]
+			}
+		} else if i := strings.Index(v, " #"); i >= 0 {
+			v = strings.TrimSpace(v[:i])
+		}
+		vars = append(vars, k+"="+v)
+	}
+	return vars, scanner.Err()
+}
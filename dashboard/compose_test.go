@@ -0,0 +1,376 @@
+package dashboard
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"SET": "v", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	tests := []struct {
+		in, want string
+		err      string // part of the error, if one is expected
+	}{
+		{in: "plain", want: "plain"},
+		{in: "$SET/x", want: "v/x"},
+		{in: "${SET}x", want: "vx"},
+		{in: "$UNSET.", want: "."},
+		{in: "${SET:-d}", want: "v"},
+		{in: "${UNSET:-d}", want: "d"},
+		{in: "${EMPTY:-d}", want: "d"},
+		{in: "${EMPTY-d}", want: ""},
+		{in: "${UNSET-d}", want: "d"},
+		{in: "${UNSET:-${SET}}", want: "v"},
+		{in: "${SET:+r}", want: "r"},
+		{in: "${EMPTY:+r}", want: ""},
+		{in: "${EMPTY+r}", want: "r"},
+		{in: "${SET:?must be set}", want: "v"},
+		{in: "${UNSET:?must be set}", err: "required variable UNSET: must be set"},
+		{in: "${EMPTY:?}", err: "required variable EMPTY: is not set"},
+		{in: "${EMPTY?}", want: ""},
+		{in: "$$SET", want: "$SET"},
+		{in: "cost: 5$", want: "cost: 5$"},
+		{in: "$1", want: "$1"},
+		{in: "${SET", err: "unterminated"},
+		{in: "${1X}", err: "bad variable"},
+		{in: "${SET:}", err: "bad variable"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.in, lookup)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Interpolate(%q) error = %v, want %q", tt.in, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("Interpolate(%q): %v", tt.in, err)
+		case tt.err == "" && got != tt.want:
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+		err  bool
+	}{
+		{"plain", "A=1\nB=two words", []string{"A=1", "B=two words"}, false},
+		{"comments and blanks", "# top\n\nA=1 # note\n  # indented", []string{"A=1"}, false},
+		{"export", "export A=1", []string{"A=1"}, false},
+		{"spaces", " A = 1 ", []string{"A=1"}, false},
+		{"double quotes", `A="x # y\n"`, []string{"A=x # y\n"}, false},
+		{"single quotes", `A='x\n'`, []string{`A=x\n`}, false},
+		{"empty", "A=", []string{"A="}, false},
+		{"equals in value", "A=b=c", []string{"A=b=c"}, false},
+		{"no equals", "A", nil, true},
+		{"no key", "=1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDotEnv([]byte(tt.in))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.err)
+			continue
+		}
+		if !tt.err && !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseComposeErrors(t *testing.T) {
+	tests := []struct {
+		name, yaml, err string
+	}{
+		{"no services", "name: x\n", "no services"},
+		{"no image", "services:\n  web: {}\n", "has no image"},
+		{"build", "services:\n  web:\n    build: .\n", "building images is not supported"},
+		{"undefined network", "services:\n  web:\n    image: nginx\n    networks: [back]\n", "undefined network back"},
+		{"undefined dependency", "services:\n  web:\n    image: nginx\n    depends_on: [db]\n", "undefined service db"},
+		{"cycle", `
+services:
+  a: {image: x, depends_on: [b]}
+  b: {image: x, depends_on: [c]}
+  c: {image: x, depends_on: [a]}
+`, "dependency cycle: a -> b -> c -> a"},
+		{"self dependency", "services:\n  a: {image: x, depends_on: [a]}\n", "dependency cycle: a -> a"},
+		{"bad condition", "services:\n  a:\n    image: x\n    depends_on: {b: {condition: later}}\n  b: {image: x}\n", "unknown depends_on condition"},
+		{"missing variable", "services:\n  web:\n    image: ${IMAGE:?set IMAGE}\n", "set IMAGE"},
+		{"bad volume", "services:\n  web:\n    image: x\n    volumes: ['a:b:c:d']\n", "bad volume"},
+	}
+	for _, tt := range tests {
+		_, err := ParseCompose([]byte(tt.yaml), "/srv/app", nil)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestComposeServiceOrder(t *testing.T) {
+	p, err := ParseCompose([]byte(`
+services:
+  web: {image: x, depends_on: {api: {condition: service_healthy}}}
+  api: {image: x, depends_on: [db, cache]}
+  db: {image: x}
+  cache: {image: x}
+`), "/srv/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := p.ServiceOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, svc := range order {
+		names = append(names, svc.Name)
+	}
+	if want := []string{"db", "cache", "api", "web"}; !slices.Equal(names, want) {
+		t.Errorf("order = %v, want %v", names, want)
+	}
+	if dep := p.Services["web"].DependsOn[0]; dep.Condition != "service_healthy" {
+		t.Errorf("web depends on %+v", dep)
+	}
+}
+
+// composeSpec parses a file with a single service named web and returns its
+// container spec.
+func composeSpec(t *testing.T, dir, data string, env map[string]string) ContainerSpec {
+	t.Helper()
+	p, err := ParseCompose([]byte(data), dir, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := p.ServiceSpec(p.Services["web"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func TestServiceSpecVolumes(t *testing.T) {
+	spec := composeSpec(t, "/srv/app", `
+services:
+  web:
+    image: nginx
+    volumes:
+      - /cache
+      - data:/var/lib/data
+      - ./html:/usr/share/nginx/html:ro
+      - /etc/localtime:/etc/localtime:ro
+      - C:\data:/win
+      - D:/logs:/logs:rw
+      - type: bind
+        source: ./conf
+        target: /etc/nginx/conf.d
+        read_only: true
+      - type: volume
+        source: data
+        target: /backup
+      - type: tmpfs
+        target: /tmp
+volumes:
+  data:
+`, nil)
+
+	if _, ok := spec.Config.Volumes["/cache"]; !ok || len(spec.Config.Volumes) != 1 {
+		t.Errorf("anonymous volumes = %v", spec.Config.Volumes)
+	}
+	wantBinds := []string{
+		"app_data:/var/lib/data",
+		"/srv/app/html:/usr/share/nginx/html:ro",
+		"/etc/localtime:/etc/localtime:ro",
+		`C:\data:/win`,
+		"D:/logs:/logs:rw",
+	}
+	if !slices.Equal(spec.HostConfig.Binds, wantBinds) {
+		t.Errorf("binds = %q, want %q", spec.HostConfig.Binds, wantBinds)
+	}
+	wantMounts := []mount.Mount{
+		{Type: mount.TypeBind, Source: "/srv/app/conf", Target: "/etc/nginx/conf.d", ReadOnly: true},
+		{Type: mount.TypeVolume, Source: "app_data", Target: "/backup"},
+		{Type: mount.TypeTmpfs, Target: "/tmp"},
+	}
+	if !slices.EqualFunc(spec.HostConfig.Mounts, wantMounts, func(a, b mount.Mount) bool {
+		return a.Type == b.Type && a.Source == b.Source && a.Target == b.Target && a.ReadOnly == b.ReadOnly
+	}) {
+		t.Errorf("mounts = %+v, want %+v", spec.HostConfig.Mounts, wantMounts)
+	}
+}
+
+func TestServiceSpecUndeclaredVolume(t *testing.T) {
+	p, err := ParseCompose([]byte("services:\n  web:\n    image: x\n    volumes: ['data:/data']\n"), "/srv/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ServiceSpec(p.Services["web"]); err == nil || !strings.Contains(err.Error(), "not declared") {
+		t.Errorf("error = %v, want undeclared volume", err)
+	}
+}
+
+func TestSplitShortVolume(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"/data", []string{"/data"}},
+		{"data:/data", []string{"data", "/data"}},
+		{"./a:/b:ro", []string{"./a", "/b", "ro"}},
+		{`C:\data:/x`, []string{`C:\data`, "/x"}},
+		{`c:/data:/x:ro`, []string{"c:/data", "/x", "ro"}},
+		{`C:\data:C:\x`, []string{`C:\data`, `C:\x`}},
+		{"a:", []string{"a", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitShortVolume(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitShortVolume(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestServiceSpecPorts(t *testing.T) {
+	spec := composeSpec(t, "/srv/app", `
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+      - "127.0.0.1:5353:53/udp"
+      - "9000"
+      - target: 443
+        published: "8443"
+        host_ip: 0.0.0.0
+        protocol: tcp
+    expose: ["7000-7001"]
+`, nil)
+
+	bindings := spec.HostConfig.PortBindings
+	check := func(port, ip, host string) {
+		t.Helper()
+		b := bindings[nat.Port(port)]
+		if len(b) != 1 || b[0].HostIP != ip || b[0].HostPort != host {
+			t.Errorf("%s bound to %+v, want %s:%s", port, b, ip, host)
+		}
+	}
+	check("80/tcp", "", "8080")
+	check("53/udp", "127.0.0.1", "5353")
+	check("9000/tcp", "", "")
+	check("443/tcp", "0.0.0.0", "8443")
+	for _, port := range []string{"80/tcp", "53/udp", "9000/tcp", "443/tcp", "7000/tcp", "7001/tcp"} {
+		if _, ok := spec.Config.ExposedPorts[nat.Port(port)]; !ok {
+			t.Errorf("%s not exposed", port)
+		}
+	}
+}
+
+func TestServiceSpecEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("base.env", "A=base\nB=base\nC=base\n")
+	write("local.env", "B=local\n")
+
+	// Later env files override earlier ones, and environment overrides
+	// both. Keys without a value come from the project's variables.
+	spec := composeSpec(t, dir, `
+services:
+  web:
+    image: nginx
+    env_file:
+      - base.env
+      - path: local.env
+      - path: missing.env
+        required: false
+    environment:
+      C: service
+      FROM_HOST:
+      NOT_SET:
+      TAG: ${TAG:-latest}
+`, map[string]string{"FROM_HOST": "host"})
+
+	want := []string{"A=base", "B=local", "C=service", "FROM_HOST=host", "TAG=latest"}
+	if !slices.Equal(spec.Config.Env, want) {
+		t.Errorf("env = %q, want %q", spec.Config.Env, want)
+	}
+}
+
+func TestServiceSpecMissingEnvFile(t *testing.T) {
+	p, err := ParseCompose([]byte("services:\n  web:\n    image: x\n    env_file: missing.env\n"), t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ServiceSpec(p.Services["web"]); err == nil || !strings.Contains(err.Error(), "env_file") {
+		t.Errorf("error = %v, want a missing env_file", err)
+	}
+}
+
+func TestServiceSpecHealthcheck(t *testing.T) {
+	tests := []struct {
+		name, healthcheck string
+		want              dockerContainer.HealthConfig
+	}{
+		{"shell string", `{test: "curl -f http://localhost", interval: 30s, timeout: 5s, start_period: 1m, retries: 3}`,
+			dockerContainer.HealthConfig{Test: []string{"CMD-SHELL", "curl -f http://localhost"},
+				Interval: 30 * time.Second, Timeout: 5 * time.Second, StartPeriod: time.Minute, Retries: 3}},
+		{"exec list", `{test: [CMD, pg_isready, -U, app]}`,
+			dockerContainer.HealthConfig{Test: []string{"CMD", "pg_isready", "-U", "app"}}},
+		{"disabled", `{disable: true}`,
+			dockerContainer.HealthConfig{Test: []string{"NONE"}}},
+	}
+	for _, tt := range tests {
+		spec := composeSpec(t, "/srv/app", "services:\n  web:\n    image: x\n    healthcheck: "+tt.healthcheck+"\n", nil)
+		got := spec.Config.Healthcheck
+		if got == nil || !slices.Equal(got.Test, tt.want.Test) || got.Interval != tt.want.Interval ||
+			got.Timeout != tt.want.Timeout || got.StartPeriod != tt.want.StartPeriod || got.Retries != tt.want.Retries {
+			t.Errorf("%s: healthcheck = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	p, err := ParseCompose([]byte("services:\n  web:\n    image: x\n    healthcheck: {test: [CMD, x], interval: soon}\n"), "/srv/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ServiceSpec(p.Services["web"]); err == nil || !strings.Contains(err.Error(), "healthcheck") {
+		t.Errorf("bad interval: error = %v", err)
+	}
+}
+
+func TestServiceSpecLabels(t *testing.T) {
+	spec := composeSpec(t, "/srv/app", "name: Shop\nservices:\n  web:\n    image: x\n    labels: [tier=front]\n", nil)
+	labels := spec.Config.Labels
+	if labels["tier"] != "front" || labels[ComposeProjectLabel] != "shop" || labels[ComposeServiceLabel] != "web" {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	const file = "services:\n  web:\n    image: nginx\n    environment: {A: '1', B: '2'}\n    labels: {x: '1', y: '2'}\n"
+	a := composeSpec(t, "/srv/app", file, nil)
+	b := composeSpec(t, "/srv/app", file, nil)
+	if specHash(a) != specHash(b) {
+		t.Error("the same service hashes differently")
+	}
+	changed := composeSpec(t, "/srv/app", strings.Replace(file, "'2'}\n    labels", "'3'}\n    labels", 1), nil)
+	if specHash(a) == specHash(changed) {
+		t.Error("a changed environment keeps the hash")
+	}
+	moved := composeSpec(t, "/srv/other", file, nil)
+	if specHash(a) == specHash(moved) {
+		t.Error("a project in another directory keeps the hash")
+	}
+}
@@ -0,0 +1,615 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeSource is what FormatCompose needs to describe a set of
+// containers: their inspect data and that of the images, networks and
+// volumes they use.
+type ComposeSource struct {
+	Containers []types.ContainerJSON
+	// Images holds image configs by image ID. Containers whose image is
+	// missing have all their values written out.
+	Images   map[string]*dockerContainer.Config
+	Networks map[string]dockerNetwork.Inspect // by name
+	Volumes  map[string]volume.Volume         // by name
+}
+
+// ExportCompose writes a compose file that recreates the containers, along
+// with the networks and named volumes they use.
+func (s *Service) ExportCompose(ctx context.Context, ids []string) ([]byte, error) {
+	src := ComposeSource{
+		Images:   map[string]*dockerContainer.Config{},
+		Networks: map[string]dockerNetwork.Inspect{},
+		Volumes:  map[string]volume.Volume{},
+	}
+	for _, id := range ids {
+		info, err := s.InspectContainer(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		src.Containers = append(src.Containers, info)
+		if _, ok := src.Images[info.Image]; !ok {
+			if img, _, err := s.cli.ImageInspectWithRaw(ctx, info.Image); err == nil {
+				src.Images[info.Image] = img.Config
+			}
+		}
+		if info.NetworkSettings != nil {
+			for name := range info.NetworkSettings.Networks {
+				if _, ok := src.Networks[name]; ok || predefinedNetwork(name) {
+					continue
+				}
+				n, err := s.cli.NetworkInspect(ctx, name, dockerNetwork.InspectOptions{})
+				if err != nil {
+					return nil, fmt.Errorf("inspect network %s: %w", name, err)
+				}
+				src.Networks[name] = n
+			}
+		}
+		for _, m := range info.Mounts {
+			if _, ok := src.Volumes[m.Name]; ok || m.Type != mount.TypeVolume || m.Name == "" {
+				continue
+			}
+			v, err := s.cli.VolumeInspect(ctx, m.Name)
+			if err != nil {
+				return nil, fmt.Errorf("inspect volume %s: %w", m.Name, err)
+			}
+			src.Volumes[m.Name] = v
+		}
+	}
+	return FormatCompose(src)
+}
+
+// The structure of an exported file. Fields are in the order docker compose
+// documents them and empty ones are left out.
+type exportFile struct {
+	Name     string                    `yaml:"name,omitempty"`
+	Services map[string]*exportService `yaml:"services"`
+	Networks map[string]*exportNetwork `yaml:"networks,omitempty"`
+	Volumes  map[string]*exportVolume  `yaml:"volumes,omitempty"`
+}
+
+type exportService struct {
+	Image         string                           `yaml:"image"`
+	ContainerName string                           `yaml:"container_name,omitempty"`
+	Hostname      string                           `yaml:"hostname,omitempty"`
+	User          string                           `yaml:"user,omitempty"`
+	WorkingDir    string                           `yaml:"working_dir,omitempty"`
+	Entrypoint    []string                         `yaml:"entrypoint,omitempty,flow"`
+	Command       []string                         `yaml:"command,omitempty,flow"`
+	Environment   []string                         `yaml:"environment,omitempty"`
+	Ports         []string                         `yaml:"ports,omitempty"`
+	Expose        []string                         `yaml:"expose,omitempty"`
+	Volumes       []any                            `yaml:"volumes,omitempty"`
+	NetworkMode   string                           `yaml:"network_mode,omitempty"`
+	Networks      map[string]*exportServiceNetwork `yaml:"networks,omitempty"`
+	ExtraHosts    []string                         `yaml:"extra_hosts,omitempty"`
+	DNS           []string                         `yaml:"dns,omitempty"`
+	Restart       string                           `yaml:"restart,omitempty"`
+	Healthcheck   *exportHealthcheck               `yaml:"healthcheck,omitempty"`
+	Labels        map[string]string                `yaml:"labels,omitempty"`
+	Tty           bool                             `yaml:"tty,omitempty"`
+	StdinOpen     bool                             `yaml:"stdin_open,omitempty"`
+	Init          bool                             `yaml:"init,omitempty"`
+	Privileged    bool                             `yaml:"privileged,omitempty"`
+	ReadOnly      bool                             `yaml:"read_only,omitempty"`
+	CapAdd        []string                         `yaml:"cap_add,omitempty"`
+	CapDrop       []string                         `yaml:"cap_drop,omitempty"`
+	MemLimit      string                           `yaml:"mem_limit,omitempty"`
+	MemswapLimit  string                           `yaml:"memswap_limit,omitempty"`
+	Cpus          float64                          `yaml:"cpus,omitempty"`
+	CPUShares     int64                            `yaml:"cpu_shares,omitempty"`
+	PidsLimit     int64                            `yaml:"pids_limit,omitempty"`
+}
+
+type exportServiceNetwork struct {
+	Aliases     []string `yaml:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address,omitempty"`
+}
+
+// exportMount is a service volume in long syntax.
+type exportMount struct {
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source,omitempty"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only,omitempty"`
+	Bind     *struct {
+		Propagation string `yaml:"propagation"`
+	} `yaml:"bind,omitempty"`
+	Volume *struct {
+		NoCopy bool `yaml:"nocopy"`
+	} `yaml:"volume,omitempty"`
+	Tmpfs *struct {
+		Size int64 `yaml:"size"`
+	} `yaml:"tmpfs,omitempty"`
+}
+
+type exportHealthcheck struct {
+	Test        []string `yaml:"test,omitempty,flow"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+	Disable     bool     `yaml:"disable,omitempty"`
+}
+
+type exportNetwork struct {
+	Name       string            `yaml:"name,omitempty"`
+	Driver     string            `yaml:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+	Internal   bool              `yaml:"internal,omitempty"`
+	Attachable bool              `yaml:"attachable,omitempty"`
+	IPAM       *exportIPAM       `yaml:"ipam,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+}
+
+type exportIPAM struct {
+	Config []exportIPAMConfig `yaml:"config"`
+}
+
+type exportIPAMConfig struct {
+	Subnet  string `yaml:"subnet,omitempty"`
+	Gateway string `yaml:"gateway,omitempty"`
+}
+
+type exportVolume struct {
+	Name       string            `yaml:"name,omitempty"`
+	Driver     string            `yaml:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+}
+
+// FormatCompose writes the containers in src as a compose file. Values
+// equal to the image's are left out, as are the labels docker compose adds
+// itself. Containers only attached to the default bridge network get no
+// networks entry, so they join the project's default network.
+//
+// When all containers belong to one compose project, the file takes its
+// name and the networks and volumes that project created keep their keys.
+// Other networks and volumes are declared under their Docker names.
+func FormatCompose(src ComposeSource) ([]byte, error) {
+	e := &composeExporter{
+		src:        src,
+		file:       exportFile{Services: map[string]*exportService{}},
+		serviceFor: map[string]string{},
+		networkKey: map[string]string{},
+		volumeKey:  map[string]string{},
+		used:       map[string]bool{},
+	}
+	e.file.Name = commonProject(src.Containers)
+
+	// Name the services first so network_mode can refer to them.
+	serviceNames := map[string]bool{}
+	for _, info := range src.Containers {
+		name := strings.TrimPrefix(info.Name, "/")
+		key := name
+		if info.Config != nil && info.Config.Labels[ComposeServiceLabel] != "" {
+			key = info.Config.Labels[ComposeServiceLabel]
+		}
+		key = uniqueKey(composeKey(key), serviceNames)
+		e.serviceFor[info.ID] = key
+		e.serviceFor[name] = key
+	}
+	for _, info := range src.Containers {
+		e.file.Services[e.serviceFor[info.ID]] = e.service(info)
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(e.file); err != nil {
+		return nil, err
+	}
+	escapeDollars(&doc)
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// composeExporter carries the state of one FormatCompose call.
+type composeExporter struct {
+	src  ComposeSource
+	file exportFile
+	// serviceFor maps container IDs and names to service names.
+	serviceFor map[string]string
+	// networkKey and volumeKey map Docker names to keys in the file; used
+	// holds the keys taken.
+	networkKey map[string]string
+	volumeKey  map[string]string
+	used       map[string]bool
+}
+
+func (e *composeExporter) service(info types.ContainerJSON) *exportService {
+	cfg := info.Config
+	host := info.HostConfig
+	if cfg == nil {
+		cfg = &dockerContainer.Config{}
+	}
+	if host == nil {
+		host = &dockerContainer.HostConfig{}
+	}
+	image := e.src.Images[info.Image]
+	if image == nil {
+		image = &dockerContainer.Config{}
+	}
+	name := strings.TrimPrefix(info.Name, "/")
+	svc := &exportService{
+		Image:      cfg.Image,
+		Tty:        cfg.Tty,
+		StdinOpen:  cfg.OpenStdin,
+		Init:       host.Init != nil && *host.Init,
+		Privileged: host.Privileged,
+		ReadOnly:   host.ReadonlyRootfs,
+		CapAdd:     host.CapAdd,
+		CapDrop:    host.CapDrop,
+		ExtraHosts: host.ExtraHosts,
+		DNS:        host.DNS,
+		CPUShares:  host.CPUShares,
+	}
+	// Compose names its own containers; others keep their name.
+	if cfg.Labels[ComposeProjectLabel] == "" {
+		svc.ContainerName = name
+	}
+	if cfg.Hostname != "" && !strings.HasPrefix(info.ID, cfg.Hostname) {
+		svc.Hostname = cfg.Hostname
+	}
+	if cfg.User != image.User {
+		svc.User = cfg.User
+	}
+	if cfg.WorkingDir != image.WorkingDir {
+		svc.WorkingDir = cfg.WorkingDir
+	}
+
+	// Overriding the entrypoint drops the image's command, so the command
+	// is then always written. [""] resets the entrypoint as --entrypoint ""
+	// does.
+	entrypointChanged := !slices.Equal(cfg.Entrypoint, image.Entrypoint)
+	if entrypointChanged {
+		svc.Entrypoint = cfg.Entrypoint
+		if len(svc.Entrypoint) == 0 {
+			svc.Entrypoint = []string{""}
+		}
+	}
+	if entrypointChanged || !slices.Equal(cfg.Cmd, image.Cmd) {
+		svc.Command = cfg.Cmd
+	}
+
+	for _, kv := range cfg.Env {
+		if !slices.Contains(image.Env, kv) {
+			svc.Environment = append(svc.Environment, kv)
+		}
+	}
+	for k, v := range cfg.Labels {
+		if strings.HasPrefix(k, "com.docker.compose.") {
+			continue
+		}
+		if iv, ok := image.Labels[k]; ok && iv == v {
+			continue
+		}
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		svc.Labels[k] = v
+	}
+
+	// Ports
+	for _, port := range sortedPorts(host.PortBindings) {
+		for _, b := range host.PortBindings[port] {
+			svc.Ports = append(svc.Ports, FormatPortSpec(port, b))
+		}
+	}
+	for _, port := range sortedPorts(cfg.ExposedPorts) {
+		_, inImage := image.ExposedPorts[port]
+		_, published := host.PortBindings[port]
+		if !inImage && !published {
+			svc.Expose = append(svc.Expose, string(port))
+		}
+	}
+
+	e.addVolumes(svc, cfg, host, image)
+	e.addNetworks(svc, info, name)
+
+	if p := host.RestartPolicy; p.Name != "" && p.Name != dockerContainer.RestartPolicyDisabled {
+		svc.Restart = string(p.Name)
+		if p.MaximumRetryCount > 0 {
+			svc.Restart += ":" + strconv.Itoa(p.MaximumRetryCount)
+		}
+	}
+	if hc := cfg.Healthcheck; hc != nil && !reflect.DeepEqual(hc, image.Healthcheck) {
+		svc.Healthcheck = exportHealth(hc)
+	}
+
+	// Resources. The daemon sets the swap limit to twice the memory limit
+	// unless told otherwise.
+	if host.Memory > 0 {
+		svc.MemLimit = formatBytes(host.Memory)
+	}
+	if host.MemorySwap > 0 && host.MemorySwap != 2*host.Memory {
+		svc.MemswapLimit = formatBytes(host.MemorySwap)
+	}
+	if host.MemorySwap < 0 {
+		svc.MemswapLimit = "-1"
+	}
+	svc.Cpus = float64(host.NanoCPUs) / 1e9
+	if host.PidsLimit != nil && *host.PidsLimit > 0 {
+		svc.PidsLimit = *host.PidsLimit
+	}
+	return svc
+}
+
+// addVolumes writes the container's binds and mounts, declaring the named
+// volumes they use. Binds stay in short syntax; mounts use the long one.
+// Anonymous volumes declared by the image or mounted over are not repeated.
+func (e *composeExporter) addVolumes(svc *exportService, cfg *dockerContainer.Config, host *dockerContainer.HostConfig, image *dockerContainer.Config) {
+	targets := map[string]bool{}
+	for _, bind := range host.Binds {
+		parts := strings.SplitN(bind, ":", 2)
+		if len(parts) == 2 {
+			if src := parts[0]; !strings.HasPrefix(src, "/") && !strings.HasPrefix(src, ".") {
+				bind = e.volume(src) + ":" + parts[1]
+			}
+			target, _, _ := strings.Cut(parts[1], ":")
+			targets[target] = true
+		}
+		svc.Volumes = append(svc.Volumes, bind)
+	}
+	for _, m := range host.Mounts {
+		em := &exportMount{Type: string(m.Type), Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly}
+		switch m.Type {
+		case mount.TypeVolume:
+			if m.Source != "" {
+				em.Source = e.volume(m.Source)
+			}
+			if m.VolumeOptions != nil && m.VolumeOptions.NoCopy {
+				em.Volume = &struct {
+					NoCopy bool `yaml:"nocopy"`
+				}{true}
+			}
+		case mount.TypeBind:
+			if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+				em.Bind = &struct {
+					Propagation string `yaml:"propagation"`
+				}{string(m.BindOptions.Propagation)}
+			}
+		case mount.TypeTmpfs:
+			if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+				em.Tmpfs = &struct {
+					Size int64 `yaml:"size"`
+				}{m.TmpfsOptions.SizeBytes}
+			}
+		}
+		svc.Volumes = append(svc.Volumes, em)
+		targets[m.Target] = true
+	}
+	for _, path := range SortedKeys(cfg.Volumes) {
+		if _, inImage := image.Volumes[path]; !inImage && !targets[path] {
+			svc.Volumes = append(svc.Volumes, path)
+		}
+	}
+}
+
+// addNetworks writes the container's network mode or the user-defined
+// networks it is attached to, declaring those networks.
+func (e *composeExporter) addNetworks(svc *exportService, info types.ContainerJSON, name string) {
+	mode := ""
+	if info.HostConfig != nil {
+		mode = string(info.HostConfig.NetworkMode)
+	}
+	switch {
+	case mode == "host" || mode == "none":
+		svc.NetworkMode = mode
+		return
+	case strings.HasPrefix(mode, "container:"):
+		target := strings.TrimPrefix(mode, "container:")
+		if key, ok := e.serviceFor[target]; ok {
+			svc.NetworkMode = "service:" + key
+		} else {
+			svc.NetworkMode = mode
+		}
+		return
+	}
+	if info.NetworkSettings == nil {
+		return
+	}
+	for _, netName := range SortedKeys(info.NetworkSettings.Networks) {
+		if predefinedNetwork(netName) {
+			continue
+		}
+		ep := info.NetworkSettings.Networks[netName]
+		sn := &exportServiceNetwork{}
+		if ep != nil {
+			// Compose and the daemon add the service name, container name
+			// and ID as aliases themselves.
+			for _, alias := range ep.Aliases {
+				if alias != name && alias != e.serviceFor[info.ID] && !strings.HasPrefix(info.ID, alias) && (info.Config == nil || alias != info.Config.Hostname) {
+					sn.Aliases = append(sn.Aliases, alias)
+				}
+			}
+			if ep.IPAMConfig != nil {
+				sn.IPv4Address = ep.IPAMConfig.IPv4Address
+			}
+		}
+		key := e.network(netName, sn.IPv4Address != "")
+		if svc.Networks == nil {
+			svc.Networks = map[string]*exportServiceNetwork{}
+		}
+		svc.Networks[key] = sn
+	}
+}
+
+// network declares the network called name and returns its key. Subnets
+// are only pinned when a container asks for a static address.
+func (e *composeExporter) network(name string, pinSubnet bool) string {
+	n := e.src.Networks[name]
+	key, ok := e.networkKey[name]
+	if !ok {
+		key = e.resourceKey(name, n.Labels, ComposeNetworkLabel)
+		e.networkKey[name] = key
+		en := &exportNetwork{
+			DriverOpts: n.Options,
+			Internal:   n.Internal,
+			Attachable: n.Attachable,
+			Labels:     withoutComposeLabels(n.Labels),
+		}
+		if key != n.Labels[ComposeNetworkLabel] || n.Labels[ComposeProjectLabel] != e.file.Name {
+			en.Name = name
+		}
+		if n.Driver != "" && n.Driver != "bridge" {
+			en.Driver = n.Driver
+		}
+		if e.file.Networks == nil {
+			e.file.Networks = map[string]*exportNetwork{}
+		}
+		e.file.Networks[key] = en
+	}
+	if en := e.file.Networks[key]; pinSubnet && en.IPAM == nil && len(n.IPAM.Config) > 0 {
+		en.IPAM = &exportIPAM{}
+		for _, c := range n.IPAM.Config {
+			en.IPAM.Config = append(en.IPAM.Config, exportIPAMConfig{Subnet: c.Subnet, Gateway: c.Gateway})
+		}
+	}
+	return key
+}
+
+// volume declares the named volume and returns its key.
+func (e *composeExporter) volume(name string) string {
+	if key, ok := e.volumeKey[name]; ok {
+		return key
+	}
+	v := e.src.Volumes[name]
+	key := e.resourceKey(name, v.Labels, ComposeVolumeLabel)
+	e.volumeKey[name] = key
+	ev := &exportVolume{
+		DriverOpts: v.Options,
+		Labels:     withoutComposeLabels(v.Labels),
+	}
+	if key != v.Labels[ComposeVolumeLabel] || v.Labels[ComposeProjectLabel] != e.file.Name {
+		ev.Name = name
+	}
+	if v.Driver != "" && v.Driver != "local" {
+		ev.Driver = v.Driver
+	}
+	if e.file.Volumes == nil {
+		e.file.Volumes = map[string]*exportVolume{}
+	}
+	e.file.Volumes[key] = ev
+	return key
+}
+
+// resourceKey picks the key of a network or volume: the key it was declared
+// under when the exported project created it, otherwise its name.
+func (e *composeExporter) resourceKey(name string, labels map[string]string, keyLabel string) string {
+	key := name
+	if e.file.Name != "" && labels[ComposeProjectLabel] == e.file.Name && labels[keyLabel] != "" {
+		key = labels[keyLabel]
+	}
+	return uniqueKey(composeKey(key), e.used)
+}
+
+func exportHealth(hc *dockerContainer.HealthConfig) *exportHealthcheck {
+	if len(hc.Test) > 0 && hc.Test[0] == "NONE" {
+		return &exportHealthcheck{Disable: true}
+	}
+	duration := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.String()
+	}
+	return &exportHealthcheck{
+		Test:        hc.Test,
+		Interval:    duration(hc.Interval),
+		Timeout:     duration(hc.Timeout),
+		StartPeriod: duration(hc.StartPeriod),
+		Retries:     hc.Retries,
+	}
+}
+
+// commonProject returns the compose project all containers belong to, or
+// "" when they do not share one.
+func commonProject(containers []types.ContainerJSON) string {
+	project := ""
+	for i, info := range containers {
+		p := ""
+		if info.Config != nil {
+			p = info.Config.Labels[ComposeProjectLabel]
+		}
+		if i > 0 && p != project {
+			return ""
+		}
+		project = p
+	}
+	return project
+}
+
+func predefinedNetwork(name string) bool {
+	return name == "bridge" || name == "host" || name == "none"
+}
+
+func withoutComposeLabels(labels map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range labels {
+		if strings.HasPrefix(k, "com.docker.compose.") {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[k] = v
+	}
+	return result
+}
+
+var composeKeyInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// composeKey turns name into a valid service, network or volume key.
+func composeKey(name string) string {
+	key := strings.TrimLeft(composeKeyInvalid.ReplaceAllString(name, "-"), "_.-")
+	if key == "" {
+		return "unnamed"
+	}
+	return key
+}
+
+// uniqueKey returns key, or key with a number appended if it is taken, and
+// marks the result as taken.
+func uniqueKey(key string, used map[string]bool) string {
+	unique := key
+	for i := 2; used[unique]; i++ {
+		unique = key + "-" + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// escapeDollars doubles the dollar signs of every string in the document so
+// that compose does not read them as variable references.
+func escapeDollars(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		n.Value = strings.ReplaceAll(n.Value, "$", "$$")
+	}
+	for _, child := range n.Content {
+		escapeDollars(child)
+	}
+}
@@ -0,0 +1,102 @@
+package dashboard
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// exportedContainer builds the inspect data of a container named web.
+func exportedContainer(cfg *dockerContainer.Config, host *dockerContainer.HostConfig) types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         "0123456789ab0123456789ab0123456789ab0123456789ab0123456789abcdef",
+			Name:       "/web",
+			Image:      "sha256:nginx",
+			HostConfig: host,
+		},
+		Config: cfg,
+	}
+}
+
+// roundTrip exports info and converts the parsed service back to a spec.
+func roundTrip(t *testing.T, info types.ContainerJSON) ContainerSpec {
+	t.Helper()
+	data, err := FormatCompose(ComposeSource{
+		Containers: []types.ContainerJSON{info},
+		Images:     map[string]*dockerContainer.Config{"sha256:nginx": {Image: "nginx"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseCompose(data, "/srv/app", nil)
+	if err != nil {
+		t.Fatalf("parse exported file: %v\n%s", err, data)
+	}
+	svc := p.Services["web"]
+	if svc == nil {
+		t.Fatalf("no web service in\n%s", data)
+	}
+	spec, err := p.ServiceSpec(svc)
+	if err != nil {
+		t.Fatalf("spec of exported service: %v\n%s", err, data)
+	}
+	return spec
+}
+
+func TestComposeExportRoundTrip(t *testing.T) {
+	pids := int64(200)
+	cfg := &dockerContainer.Config{
+		Image:        "nginx",
+		Env:          []string{"MODE=prod"},
+		Cmd:          []string{"nginx", "-g", "daemon off;"},
+		ExposedPorts: nat.PortSet{"80/tcp": {}},
+	}
+	host := &dockerContainer.HostConfig{
+		PortBindings:  nat.PortMap{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}},
+		RestartPolicy: dockerContainer.RestartPolicy{Name: dockerContainer.RestartPolicyOnFailure, MaximumRetryCount: 3},
+		CapAdd:        []string{"NET_ADMIN"},
+		Resources: dockerContainer.Resources{
+			Memory:     512 << 20,
+			MemorySwap: 768 << 20,
+			NanoCPUs:   1500000000,
+			CPUShares:  512,
+			PidsLimit:  &pids,
+		},
+	}
+	spec := roundTrip(t, exportedContainer(cfg, host))
+	got := spec.HostConfig
+
+	if got.Memory != host.Memory || got.MemorySwap != host.MemorySwap {
+		t.Errorf("memory = %d, swap %d; want %d, %d", got.Memory, got.MemorySwap, host.Memory, host.MemorySwap)
+	}
+	if got.NanoCPUs != host.NanoCPUs || got.CPUShares != host.CPUShares {
+		t.Errorf("cpus = %d, shares %d; want %d, %d", got.NanoCPUs, got.CPUShares, host.NanoCPUs, host.CPUShares)
+	}
+	if got.PidsLimit == nil || *got.PidsLimit != pids {
+		t.Errorf("pids limit = %v, want %d", got.PidsLimit, pids)
+	}
+	if got.RestartPolicy != host.RestartPolicy {
+		t.Errorf("restart = %+v, want %+v", got.RestartPolicy, host.RestartPolicy)
+	}
+	if !slices.Equal(got.CapAdd, host.CapAdd) {
+		t.Errorf("cap_add = %v, want %v", got.CapAdd, host.CapAdd)
+	}
+	if b := got.PortBindings["80/tcp"]; len(b) != 1 || b[0] != host.PortBindings["80/tcp"][0] {
+		t.Errorf("port bindings = %v", got.PortBindings)
+	}
+	if !slices.Equal(spec.Config.Cmd, cfg.Cmd) || !slices.Contains(spec.Config.Env, "MODE=prod") {
+		t.Errorf("cmd %v, env %v", spec.Config.Cmd, spec.Config.Env)
+	}
+}
+
+func TestComposeExportRoundTripUnlimitedSwap(t *testing.T) {
+	host := &dockerContainer.HostConfig{Resources: dockerContainer.Resources{Memory: 256 << 20, MemorySwap: -1}}
+	got := roundTrip(t, exportedContainer(&dockerContainer.Config{Image: "nginx"}, host)).HostConfig
+	if got.Memory != 256<<20 || got.MemorySwap != -1 {
+		t.Errorf("memory = %d, swap %d; want %d, -1", got.Memory, got.MemorySwap, 256<<20)
+	}
+}
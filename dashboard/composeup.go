@@ -0,0 +1,620 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+)
+
+// composePollInterval is how often Up checks a dependency's state.
+const composePollInterval = time.Second
+
+// ServiceStatus is the state of one compose service.
+type ServiceStatus struct {
+	Service   string
+	Container string // empty when the service has no container
+	// State is the container state, such as running or exited, or
+	// "not created".
+	State  string
+	Status string
+	// Health is healthy, unhealthy or starting for services with a
+	// healthcheck.
+	Health string
+}
+
+// ComposeUp creates the project's networks and volumes, then creates and
+// starts its services in dependency order, waiting for depends_on
+// conditions. Containers whose configuration is unchanged are only started;
+// changed ones are recreated. progress, if not nil, receives a line per
+// step.
+func (s *Service) ComposeUp(ctx context.Context, p *ComposeProject, progress func(string)) error {
+	if progress == nil {
+		progress = func(string) {}
+	}
+	order, err := p.ServiceOrder()
+	if err != nil {
+		return err
+	}
+	for _, key := range p.UsedNetworks() {
+		if err := s.ensureComposeNetwork(ctx, p, key, progress); err != nil {
+			return err
+		}
+	}
+	for _, key := range SortedKeys(p.Volumes) {
+		if err := s.ensureComposeVolume(ctx, p, key, progress); err != nil {
+			return err
+		}
+	}
+	for _, svc := range order {
+		for _, dep := range svc.DependsOn {
+			if err := s.waitDependency(ctx, p, dep, progress); err != nil {
+				return fmt.Errorf("service %s: %w", svc.Name, err)
+			}
+		}
+		if err := s.upService(ctx, p, svc, progress); err != nil {
+			return fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+	}
+	progress("Project " + p.Name + " is up")
+	return nil
+}
+
+func (s *Service) ensureComposeNetwork(ctx context.Context, p *ComposeProject, key string, progress func(string)) error {
+	name := p.NetworkName(key)
+	_, err := s.cli.NetworkInspect(ctx, name, dockerNetwork.InspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return fmt.Errorf("inspect network %s: %w", name, err)
+	}
+	n := p.Networks[key]
+	if n == nil {
+		n = &ComposeNetwork{}
+	}
+	if n.External.External {
+		return fmt.Errorf("external network %s does not exist", name)
+	}
+	labels := mappingValues(n.Labels, nil)
+	labels[ComposeProjectLabel] = p.Name
+	labels[ComposeNetworkLabel] = key
+	progress("Creating network " + name)
+	_, err = s.cli.NetworkCreate(ctx, name, dockerNetwork.CreateOptions{
+		Driver:   n.Driver,
+		Internal: n.Internal,
+		Labels:   labels,
+	})
+	if err != nil {
+		return fmt.Errorf("create network %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *Service) ensureComposeVolume(ctx context.Context, p *ComposeProject, key string, progress func(string)) error {
+	name := p.VolumeName(key)
+	_, err := s.cli.VolumeInspect(ctx, name)
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return fmt.Errorf("inspect volume %s: %w", name, err)
+	}
+	v := p.Volumes[key]
+	if v.External.External {
+		return fmt.Errorf("external volume %s does not exist", name)
+	}
+	labels := mappingValues(v.Labels, nil)
+	labels[ComposeProjectLabel] = p.Name
+	labels[ComposeVolumeLabel] = key
+	progress("Creating volume " + name)
+	if _, err := s.cli.VolumeCreate(ctx, volume.CreateOptions{Name: name, Driver: v.Driver, Labels: labels}); err != nil {
+		return fmt.Errorf("create volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// waitDependency blocks until the dependency meets its condition.
+func (s *Service) waitDependency(ctx context.Context, p *ComposeProject, dep ComposeDependency, progress func(string)) error {
+	if dep.Condition == "service_started" {
+		return nil
+	}
+	name := p.ContainerName(p.Services[dep.Service])
+	if dep.Condition == "service_healthy" {
+		progress("Waiting for " + dep.Service + " to be healthy")
+	} else {
+		progress("Waiting for " + dep.Service + " to complete")
+	}
+	for {
+		info, err := s.InspectContainer(ctx, name)
+		if err != nil {
+			return err
+		}
+		state := info.State
+		switch dep.Condition {
+		case "service_healthy":
+			if state.Health == nil {
+				return fmt.Errorf("dependency %s has no healthcheck", dep.Service)
+			}
+			switch state.Health.Status {
+			case "healthy":
+				return nil
+			case "unhealthy":
+				return fmt.Errorf("dependency %s is unhealthy", dep.Service)
+			}
+			if !state.Running && !state.Restarting {
+				return fmt.Errorf("dependency %s exited with code %d", dep.Service, state.ExitCode)
+			}
+		case "service_completed_successfully":
+			if !state.Running && !state.Restarting && state.Status != "created" {
+				if state.ExitCode != 0 {
+					return fmt.Errorf("dependency %s exited with code %d", dep.Service, state.ExitCode)
+				}
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(composePollInterval):
+		}
+	}
+}
+
+func (s *Service) upService(ctx context.Context, p *ComposeProject, svc *ComposeService, progress func(string)) error {
+	spec, err := p.ServiceSpec(svc)
+	if err != nil {
+		return err
+	}
+	hash := specHash(spec)
+	spec.Config.Labels[ComposeConfigHashLabel] = hash
+
+	info, err := s.cli.ContainerInspect(ctx, spec.Name)
+	switch {
+	case err == nil && info.Config != nil && info.Config.Labels[ComposeConfigHashLabel] == hash:
+		if info.State != nil && info.State.Running {
+			progress("Container " + spec.Name + " is up to date")
+			return nil
+		}
+		progress("Starting " + spec.Name)
+		return s.StartContainer(ctx, info.ID)
+	case err == nil:
+		progress("Recreating " + spec.Name)
+		if err := s.RemoveContainer(ctx, info.ID); err != nil {
+			return err
+		}
+	case !errdefs.IsNotFound(err):
+		return fmt.Errorf("inspect container %s: %w", spec.Name, err)
+	default:
+		progress("Creating " + spec.Name)
+	}
+
+	if _, _, err := s.cli.ImageInspectWithRaw(ctx, spec.Config.Image); err != nil {
+		progress("Pulling " + spec.Config.Image)
+		if err := s.PullImage(ctx, spec.Config.Image); err != nil {
+			return err
+		}
+	}
+	resp, err := s.cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, spec.NetworkingConfig, nil, spec.Name)
+	if err != nil {
+		return fmt.Errorf("create container %s: %w", spec.Name, err)
+	}
+	// Only one network can be given at create time on older daemons; the
+	// rest are connected before the container starts.
+	networks := p.serviceNetworks(svc)
+	for i := 1; i < len(networks); i++ {
+		net := networks[i]
+		endpoint := &dockerNetwork.EndpointSettings{Aliases: append([]string{svc.Name}, net.Aliases...)}
+		if err := s.cli.NetworkConnect(ctx, p.NetworkName(net.Name), resp.ID, endpoint); err != nil {
+			return fmt.Errorf("connect %s to %s: %w", spec.Name, net.Name, err)
+		}
+	}
+	progress("Starting " + spec.Name)
+	return s.StartContainer(ctx, resp.ID)
+}
+
+// specHash fingerprints a spec so that Up can tell whether a container is
+// out of date.
+func specHash(spec ContainerSpec) string {
+	data, _ := json.Marshal(spec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComposeDown stops and removes the project's containers and networks, and
+// its volumes too when removeVolumes is set. Resources are found by their
+// project label, so this also works for stacks started by docker compose.
+func (s *Service) ComposeDown(ctx context.Context, project string, removeVolumes bool, progress func(string)) error {
+	if progress == nil {
+		progress = func(string) {}
+	}
+	byProject := filters.NewArgs(filters.Arg("label", ComposeProjectLabel+"="+project))
+	containers, err := s.cli.ContainerList(ctx, dockerContainer.ListOptions{All: true, Filters: byProject})
+	if err != nil {
+		return fmt.Errorf("list containers: %w", err)
+	}
+	for _, c := range containers {
+		name := containerFromSummary(c).Name
+		if c.State == "running" {
+			progress("Stopping " + name)
+			if err := s.StopContainer(ctx, c.ID); err != nil {
+				return err
+			}
+		}
+		progress("Removing " + name)
+		if err := s.RemoveContainer(ctx, c.ID); err != nil {
+			return err
+		}
+	}
+	networks, err := s.cli.NetworkList(ctx, dockerNetwork.ListOptions{Filters: byProject})
+	if err != nil {
+		return fmt.Errorf("list networks: %w", err)
+	}
+	for _, n := range networks {
+		progress("Removing network " + n.Name)
+		if err := s.RemoveNetwork(ctx, n.ID); err != nil {
+			return err
+		}
+	}
+	if removeVolumes {
+		resp, err := s.cli.VolumeList(ctx, volume.ListOptions{Filters: byProject})
+		if err != nil {
+			return fmt.Errorf("list volumes: %w", err)
+		}
+		for _, v := range resp.Volumes {
+			progress("Removing volume " + v.Name)
+			if err := s.RemoveVolume(ctx, v.Name); err != nil {
+				return err
+			}
+		}
+	}
+	progress("Project " + project + " is down")
+	return nil
+}
+
+// ComposeStatus returns the state of each of the project's services, in
+// dependency order.
+func (s *Service) ComposeStatus(ctx context.Context, p *ComposeProject) ([]ServiceStatus, error) {
+	order, err := p.ServiceOrder()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := s.ListContainers(ctx, filters.NewArgs(filters.Arg("label", ComposeProjectLabel+"="+p.Name)))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ServiceStatus, len(order))
+	for i, svc := range order {
+		result[i] = ServiceStatus{Service: svc.Name, State: "not created"}
+		for _, c := range containers {
+			if c.Labels[ComposeProjectLabel] == p.Name && c.Labels[ComposeServiceLabel] == svc.Name {
+				result[i].Container = c.Name
+				result[i].State = c.State
+				result[i].Status = c.Status
+				result[i].Health = HealthFromStatus(c.Status)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// HealthFromStatus extracts the health from a container list status such as
+// "Up 5 minutes (healthy)".
+func HealthFromStatus(status string) string {
+	for _, h := range []string{"unhealthy", "healthy", "health: starting"} {
+		if strings.Contains(status, "("+h+")") {
+			return strings.TrimPrefix(h, "health: ")
+		}
+	}
+	return ""
+}
+
+// =============================================================================
+// Service to container spec
+// =============================================================================
+
+// ServiceSpec converts a service to the container that runs it, labelled as
+// docker compose would label it.
+func (p *ComposeProject) ServiceSpec(svc *ComposeService) (ContainerSpec, error) {
+	env, err := p.serviceEnv(svc)
+	if err != nil {
+		return ContainerSpec{}, err
+	}
+	labels := mappingValues(svc.Labels, p.env)
+	labels[ComposeProjectLabel] = p.Name
+	labels[ComposeServiceLabel] = svc.Name
+	labels[ComposeNumberLabel] = "1"
+	labels[ComposeOneoffLabel] = "False"
+	labels[ComposeWorkingDirLabel] = p.Dir
+	if p.File != "" {
+		labels[ComposeFilesLabel] = p.File
+	}
+
+	cfg := &dockerContainer.Config{
+		Image:      svc.Image,
+		Cmd:        []string(svc.Command),
+		Entrypoint: []string(svc.Entrypoint),
+		Env:        env,
+		Labels:     labels,
+		WorkingDir: svc.WorkingDir,
+		User:       svc.User,
+		Hostname:   svc.Hostname,
+		Tty:        svc.Tty,
+		OpenStdin:  svc.StdinOpen,
+	}
+	host := &dockerContainer.HostConfig{
+		Privileged:     svc.Privileged,
+		ReadonlyRootfs: svc.ReadOnly,
+		Init:           svc.Init,
+		CapAdd:         svc.CapAdd,
+		CapDrop:        svc.CapDrop,
+		ExtraHosts:     svc.ExtraHosts,
+		DNS:            svc.DNS,
+	}
+
+	specs := make([]string, len(svc.Ports))
+	for i, port := range svc.Ports {
+		specs[i] = string(port)
+	}
+	exposed, bindings, err := nat.ParsePortSpecs(specs)
+	if err != nil {
+		return ContainerSpec{}, fmt.Errorf("ports: %w", err)
+	}
+	for _, e := range svc.Expose {
+		proto, ports := nat.SplitProtoPort(e)
+		start, end, err := nat.ParsePortRange(ports)
+		if err != nil {
+			return ContainerSpec{}, fmt.Errorf("expose: %w", err)
+		}
+		for port := start; port <= end; port++ {
+			exposed[nat.Port(fmt.Sprintf("%d/%s", port, proto))] = struct{}{}
+		}
+	}
+	if len(exposed) > 0 {
+		cfg.ExposedPorts = exposed
+	}
+	if len(bindings) > 0 {
+		host.PortBindings = bindings
+	}
+
+	for _, v := range svc.Volumes {
+		bind, m, err := p.serviceMount(v)
+		switch {
+		case err != nil:
+			return ContainerSpec{}, err
+		case m != nil:
+			host.Mounts = append(host.Mounts, *m)
+		case !strings.Contains(bind, ":"):
+			// An anonymous volume.
+			if cfg.Volumes == nil {
+				cfg.Volumes = map[string]struct{}{}
+			}
+			cfg.Volumes[bind] = struct{}{}
+		default:
+			host.Binds = append(host.Binds, bind)
+		}
+	}
+
+	if svc.Restart != "" {
+		policy, retries, hasRetries := strings.Cut(svc.Restart, ":")
+		host.RestartPolicy.Name = dockerContainer.RestartPolicyMode(policy)
+		if hasRetries {
+			n, err := strconv.Atoi(retries)
+			if err != nil {
+				return ContainerSpec{}, fmt.Errorf("restart: bad retry count %q", retries)
+			}
+			host.RestartPolicy.MaximumRetryCount = n
+		}
+		if err := dockerContainer.ValidateRestartPolicy(host.RestartPolicy); err != nil {
+			return ContainerSpec{}, fmt.Errorf("restart: %w", err)
+		}
+	}
+	if svc.MemLimit != "" {
+		if host.Memory, err = units.RAMInBytes(svc.MemLimit); err != nil {
+			return ContainerSpec{}, fmt.Errorf("mem_limit: %w", err)
+		}
+	}
+	// -1 allows unlimited swap.
+	switch svc.MemswapLimit {
+	case "":
+	case "-1":
+		host.MemorySwap = -1
+	default:
+		if host.MemorySwap, err = units.RAMInBytes(svc.MemswapLimit); err != nil {
+			return ContainerSpec{}, fmt.Errorf("memswap_limit: %w", err)
+		}
+	}
+	if svc.Cpus != "" {
+		cpus, err := strconv.ParseFloat(svc.Cpus, 64)
+		if err != nil {
+			return ContainerSpec{}, fmt.Errorf("cpus: bad CPU count %q", svc.Cpus)
+		}
+		host.NanoCPUs = int64(cpus * 1e9)
+	}
+	host.CPUShares = svc.CPUShares
+	if svc.PidsLimit != 0 {
+		pids := svc.PidsLimit
+		host.PidsLimit = &pids
+	}
+	if svc.Healthcheck != nil {
+		if cfg.Healthcheck, err = svc.Healthcheck.config(); err != nil {
+			return ContainerSpec{}, fmt.Errorf("healthcheck: %w", err)
+		}
+	}
+
+	var netConfig *dockerNetwork.NetworkingConfig
+	if svc.NetworkMode != "" {
+		host.NetworkMode = dockerContainer.NetworkMode(svc.NetworkMode)
+	} else {
+		first := p.serviceNetworks(svc)[0]
+		name := p.NetworkName(first.Name)
+		host.NetworkMode = dockerContainer.NetworkMode(name)
+		netConfig = &dockerNetwork.NetworkingConfig{
+			EndpointsConfig: map[string]*dockerNetwork.EndpointSettings{
+				name: {Aliases: append([]string{svc.Name}, first.Aliases...)},
+			},
+		}
+	}
+
+	return ContainerSpec{
+		Name:             p.ContainerName(svc),
+		Config:           cfg,
+		HostConfig:       host,
+		NetworkingConfig: netConfig,
+	}, nil
+}
+
+// serviceEnv merges the service's env_file entries and environment, the
+// latter taking precedence. Entries without a value come from the
+// project's environment and are dropped when it does not set them.
+func (p *ComposeProject) serviceEnv(svc *ComposeService) ([]string, error) {
+	var env []string
+	index := map[string]int{}
+	set := func(k, v string) {
+		if i, ok := index[k]; ok {
+			env[i] = k + "=" + v
+			return
+		}
+		index[k] = len(env)
+		env = append(env, k+"="+v)
+	}
+	for _, f := range svc.EnvFile {
+		data, err := os.ReadFile(p.resolvePath(f.Path))
+		if err != nil {
+			if !f.Required && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("env_file: %w", err)
+		}
+		vars, err := parseDotEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("env_file %s: %w", f.Path, err)
+		}
+		for _, kv := range vars {
+			k, v, _ := strings.Cut(kv, "=")
+			set(k, v)
+		}
+	}
+	for _, k := range SortedKeys(svc.Environment) {
+		if v := svc.Environment[k]; v != nil {
+			set(k, *v)
+		} else if v, ok := p.env[k]; ok {
+			set(k, v)
+		}
+	}
+	return env, nil
+}
+
+// serviceMount converts a service volume. The short syntax becomes a bind
+// string, which like docker compose creates missing host directories; the
+// long syntax becomes a mount.
+func (p *ComposeProject) serviceMount(v composeServiceVolume) (string, *mount.Mount, error) {
+	source := v.Source
+	switch v.Type {
+	case "bind":
+		source = p.resolvePath(source)
+	case "volume":
+		if source == "" {
+			break
+		}
+		if _, ok := p.Volumes[source]; !ok {
+			return "", nil, fmt.Errorf("volume %s is not declared under volumes", source)
+		}
+		source = p.VolumeName(source)
+	case "tmpfs":
+	default:
+		return "", nil, fmt.Errorf("volume %s: unsupported type %s", v.Target, v.Type)
+	}
+	if v.short {
+		if source == "" {
+			return v.Target, nil, nil
+		}
+		bind := source + ":" + v.Target
+		if v.Mode != "" {
+			bind += ":" + v.Mode
+		}
+		return bind, nil, nil
+	}
+	return "", &mount.Mount{
+		Type:     mount.Type(v.Type),
+		Source:   source,
+		Target:   v.Target,
+		ReadOnly: v.ReadOnly,
+	}, nil
+}
+
+// resolvePath makes a host path from the compose file absolute. Windows
+// paths are passed on as they are, for a daemon on a Windows host.
+func (p *ComposeProject) resolvePath(path string) string {
+	if windowsDrivePath.MatchString(path) {
+		return path
+	}
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+func (h *ComposeHealthcheck) config() (*dockerContainer.HealthConfig, error) {
+	if h.Disable {
+		return &dockerContainer.HealthConfig{Test: []string{"NONE"}}, nil
+	}
+	cfg := &dockerContainer.HealthConfig{Test: []string(h.Test), Retries: h.Retries}
+	for _, d := range []struct {
+		text string
+		dst  *time.Duration
+	}{
+		{h.Interval, &cfg.Interval},
+		{h.Timeout, &cfg.Timeout},
+		{h.StartPeriod, &cfg.StartPeriod},
+	} {
+		if d.text == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.text)
+		if err != nil {
+			return nil, err
+		}
+		*d.dst = v
+	}
+	return cfg, nil
+}
+
+// mappingValues flattens a mapping, taking keys without a value from env
+// (or leaving them empty when env is nil).
+func mappingValues(m composeMapping, env map[string]string) map[string]string {
+	result := make(map[string]string, len(m)+4)
+	for k, v := range m {
+		switch {
+		case v != nil:
+			result[k] = *v
+		case env != nil:
+			result[k] = env[k]
+		default:
+			result[k] = ""
+		}
+	}
+	return result
+}
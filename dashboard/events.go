@@ -0,0 +1,125 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Event is a change to one of the listed resources.
+type Event struct {
+	// Resync is set once after every (re)connection to the event stream.
+	// Events may have been missed while disconnected, so handlers should
+	// reload their lists from scratch.
+	Resync bool
+
+	Type   events.Type
+	Action events.Action
+	ID     string // container, image or network ID; volume name
+	Name   string
+	Time   time.Time
+}
+
+// watchedActions are the event actions that change what the tabs display.
+var watchedActions = map[events.Type][]events.Action{
+	events.ContainerEventType: {
+		events.ActionCreate, events.ActionStart, events.ActionRestart, events.ActionDie,
+		events.ActionPause, events.ActionUnPause, events.ActionRename, events.ActionDestroy,
+	},
+	events.ImageEventType: {
+		events.ActionPull, events.ActionDelete, events.ActionTag, events.ActionUnTag,
+		events.ActionLoad, events.ActionImport,
+	},
+	events.VolumeEventType:  {events.ActionCreate, events.ActionDestroy},
+	events.NetworkEventType: {events.ActionCreate, events.ActionDestroy},
+}
+
+const (
+	minEventsBackoff = time.Second
+	maxEventsBackoff = 30 * time.Second
+)
+
+// WatchEvents subscribes to the daemon's event stream and calls handle for
+// every container, image, volume and network change until ctx is cancelled.
+// When the stream breaks it calls lost, if not nil, with the reason and
+// reconnects with exponential backoff. handle and lost are called from the
+// watching goroutine, one at a time.
+func (s *Service) WatchEvents(ctx context.Context, handle func(Event), lost func(error)) error {
+	backoff := minEventsBackoff
+	for {
+		connected, err := s.watchOnce(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if lost != nil {
+			lost(err)
+		}
+		if connected {
+			backoff = minEventsBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxEventsBackoff {
+			backoff = maxEventsBackoff
+		}
+	}
+}
+
+// watchOnce runs a single subscription. It reports whether the daemon was
+// reachable so the caller can reset its backoff.
+func (s *Service) watchOnce(ctx context.Context, handle func(Event)) (bool, error) {
+	// Ping first: Events only reports a dead daemon once it is read from.
+	if _, err := s.cli.Ping(ctx); err != nil {
+		return false, fmt.Errorf("ping daemon: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, errs := s.cli.Events(ctx, events.ListOptions{Filters: eventFilters()})
+	s.finished.reset(true)
+	defer s.finished.reset(false)
+	handle(Event{Resync: true, Time: time.Now()})
+	for {
+		select {
+		case msg := <-msgs:
+			e := eventFromMessage(msg)
+			// Before handle, whose reload may read the cache.
+			s.finished.observe(e)
+			handle(e)
+		case err := <-errs:
+			return true, err
+		}
+	}
+}
+
+func eventFilters() filters.Args {
+	args := filters.NewArgs()
+	seen := map[events.Action]bool{}
+	for typ, actions := range watchedActions {
+		args.Add("type", string(typ))
+		for _, action := range actions {
+			if !seen[action] {
+				seen[action] = true
+				args.Add("event", string(action))
+			}
+		}
+	}
+	return args
+}
+
+func eventFromMessage(msg events.Message) Event {
+	return Event{
+		Type:   msg.Type,
+		Action: msg.Action,
+		ID:     msg.Actor.ID,
+		Name:   msg.Actor.Attributes["name"],
+		Time:   time.Unix(0, msg.TimeNano),
+	}
+}
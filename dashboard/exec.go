@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecOptions configures an interactive exec session.
+type ExecOptions struct {
+	Cmd        []string
+	User       string
+	WorkingDir string
+	// Rows and Cols are the initial terminal size.
+	Rows, Cols uint
+}
+
+// Session is an interactive connection to a process in a container: input
+// written to it goes to the process's stdin.
+type Session struct {
+	conn   types.HijackedResponse
+	tty    bool
+	stdin  bool
+	detach []byte // detach key sequence, for attach sessions
+	resize func(ctx context.Context, rows, cols uint) error
+	exit   func(ctx context.Context) (int, error)
+}
+
+// Exec starts a command in a running container with a TTY attached.
+func (s *Service) Exec(ctx context.Context, id string, opts ExecOptions) (*Session, error) {
+	size := &[2]uint{opts.Rows, opts.Cols}
+	created, err := s.cli.ContainerExecCreate(ctx, id, dockerContainer.ExecOptions{
+		User:         opts.User,
+		WorkingDir:   opts.WorkingDir,
+		Cmd:          opts.Cmd,
+		Env:          []string{"TERM=xterm-256color"},
+		Tty:          true,
+		ConsoleSize:  size,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, resourceError("exec in", "container", id, err)
+	}
+	conn, err := s.cli.ContainerExecAttach(ctx, created.ID, dockerContainer.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: size,
+	})
+	if err != nil {
+		return nil, resourceError("exec in", "container", id, err)
+	}
+	return &Session{
+		conn:  conn,
+		tty:   true,
+		stdin: true,
+		resize: func(ctx context.Context, rows, cols uint) error {
+			return s.cli.ContainerExecResize(ctx, created.ID, dockerContainer.ResizeOptions{Height: rows, Width: cols})
+		},
+		exit: func(ctx context.Context) (int, error) {
+			info, err := s.cli.ContainerExecInspect(ctx, created.ID)
+			return info.ExitCode, err
+		},
+	}, nil
+}
+
+// TTY reports whether the process runs with a terminal, in which case its
+// output is a single stream and line editing happens in the container.
+func (sess *Session) TTY() bool {
+	return sess.tty
+}
+
+// Write sends input to the process.
+func (sess *Session) Write(p []byte) (int, error) {
+	return sess.conn.Conn.Write(p)
+}
+
+// Copy forwards the process's output until it ends. TTY output goes to
+// stdout only.
+func (sess *Session) Copy(stdout, stderr io.Writer) error {
+	var err error
+	if sess.tty {
+		_, err = io.Copy(stdout, sess.conn.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, sess.conn.Reader)
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("read output: %w", err)
+	}
+	return nil
+}
+
+// Resize changes the terminal size; it does nothing without a TTY.
+func (sess *Session) Resize(ctx context.Context, rows, cols uint) error {
+	if !sess.tty || sess.resize == nil || rows == 0 || cols == 0 {
+		return nil
+	}
+	return sess.resize(ctx, rows, cols)
+}
+
+// ExitCode returns the process's exit code once it has ended.
+func (sess *Session) ExitCode(ctx context.Context) (int, error) {
+	if sess.exit == nil {
+		return 0, errors.New("exit code not available")
+	}
+	return sess.exit(ctx)
+}
+
+// Close ends the connection. For an exec session the process receives a
+// hangup; an attached container keeps running.
+func (sess *Session) Close() {
+	sess.conn.Close()
+}
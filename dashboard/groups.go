@@ -0,0 +1,106 @@
+package dashboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ContainerGroup is a set of containers sharing a label value, possibly
+// split further by another label.
+type ContainerGroup struct {
+	Label string
+	// Value is the label's value; it is empty for containers without the
+	// label.
+	Value string
+	// Groups holds the next level of grouping; Containers the members when
+	// this is the last level.
+	Groups     []*ContainerGroup
+	Containers []Container
+}
+
+// GroupContainers groups containers by the values of the label keys, one
+// level per key. Groups are sorted by value, with unlabelled containers
+// last.
+func GroupContainers(containers []Container, keys ...string) []*ContainerGroup {
+	if len(keys) == 0 {
+		return nil
+	}
+	byValue := map[string]*ContainerGroup{}
+	for _, c := range containers {
+		value := c.Labels[keys[0]]
+		g, ok := byValue[value]
+		if !ok {
+			g = &ContainerGroup{Label: keys[0], Value: value}
+			byValue[value] = g
+		}
+		g.Containers = append(g.Containers, c)
+	}
+	groups := make([]*ContainerGroup, 0, len(byValue))
+	for _, g := range byValue {
+		sort.Slice(g.Containers, func(i, j int) bool { return g.Containers[i].Name < g.Containers[j].Name })
+		if len(keys) > 1 {
+			g.Groups = GroupContainers(g.Containers, keys[1:]...)
+			g.Containers = nil
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].Value, groups[j].Value
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+	return groups
+}
+
+// All returns every container in the group and its subgroups.
+func (g *ContainerGroup) All() []Container {
+	all := append([]Container(nil), g.Containers...)
+	for _, sub := range g.Groups {
+		all = append(all, sub.All()...)
+	}
+	return all
+}
+
+// GroupSummary counts the states of a group's containers.
+type GroupSummary struct {
+	Total     int
+	Running   int
+	Unhealthy int
+}
+
+// Summary aggregates the state of the group's containers.
+func (g *ContainerGroup) Summary() GroupSummary {
+	var s GroupSummary
+	for _, c := range g.All() {
+		s.Total++
+		if c.State == "running" {
+			s.Running++
+		}
+		if HealthFromStatus(c.Status) == "unhealthy" {
+			s.Unhealthy++
+		}
+	}
+	return s
+}
+
+func (s GroupSummary) String() string {
+	parts := []string{fmt.Sprintf("%d/%d running", s.Running, s.Total)}
+	if s.Unhealthy > 0 {
+		parts = append(parts, fmt.Sprintf("%d unhealthy", s.Unhealthy))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LabelKeys returns the label keys used by any of the containers, sorted.
+func LabelKeys(containers []Container) []string {
+	seen := map[string]bool{}
+	for _, c := range containers {
+		for k := range c.Labels {
+			seen[k] = true
+		}
+	}
+	return SortedKeys(seen)
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+)
+
+// describeGroups renders groups as "value[members]", nesting subgroups and
+// writing "-" for the unlabelled group.
+func describeGroups(groups []*ContainerGroup) string {
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		value := g.Value
+		if value == "" {
+			value = "-"
+		}
+		var members []string
+		if len(g.Groups) > 0 {
+			members = append(members, describeGroups(g.Groups))
+		}
+		for _, c := range g.Containers {
+			members = append(members, c.Name)
+		}
+		parts[i] = value + "[" + strings.Join(members, " ") + "]"
+	}
+	return strings.Join(parts, " ")
+}
+
+func composeContainer(name, project, service, state, status string) Container {
+	labels := map[string]string{}
+	if project != "" {
+		labels[ComposeProjectLabel] = project
+	}
+	if service != "" {
+		labels[ComposeServiceLabel] = service
+	}
+	return Container{Name: name, State: state, Status: status, Labels: labels}
+}
+
+func TestGroupContainers(t *testing.T) {
+	containers := []Container{
+		composeContainer("shop-web-1", "shop", "web", "running", "Up 1 hour"),
+		composeContainer("standalone", "", "", "running", "Up 2 hours"),
+		composeContainer("app-web-1", "app", "web", "running", "Up 5 minutes (healthy)"),
+		composeContainer("app-api-2", "app", "api", "running", "Up 5 minutes (unhealthy)"),
+		composeContainer("app-api-1", "app", "api", "exited", "Exited (1) 2 minutes ago"),
+		composeContainer("app-migrate", "app", "", "exited", "Exited (0) 1 hour ago"),
+		composeContainer("another", "", "", "exited", "Exited (0) 1 day ago"),
+	}
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{nil, ""},
+		// Unlabelled containers come last, members sorted by name.
+		{[]string{ComposeProjectLabel},
+			"app[app-api-1 app-api-2 app-migrate app-web-1] shop[shop-web-1] -[another standalone]"},
+		{[]string{ComposeProjectLabel, ComposeServiceLabel},
+			"app[api[app-api-1 app-api-2] web[app-web-1] -[app-migrate]] shop[web[shop-web-1]] -[-[another standalone]]"},
+		{[]string{"no.such.label"}, "-[another app-api-1 app-api-2 app-migrate app-web-1 shop-web-1 standalone]"},
+	}
+	for _, tt := range tests {
+		if got := describeGroups(GroupContainers(containers, tt.keys...)); got != tt.want {
+			t.Errorf("GroupContainers(%v) =\n%s\nwant\n%s", tt.keys, got, tt.want)
+		}
+	}
+	if groups := GroupContainers(nil, ComposeProjectLabel); len(groups) != 0 {
+		t.Errorf("no containers: %d groups", len(groups))
+	}
+}
+
+func TestGroupSummary(t *testing.T) {
+	containers := []Container{
+		composeContainer("app-web-1", "app", "web", "running", "Up 5 minutes (healthy)"),
+		composeContainer("app-api-1", "app", "api", "exited", "Exited (1) 2 minutes ago"),
+		composeContainer("app-api-2", "app", "api", "running", "Up 5 minutes (unhealthy)"),
+		composeContainer("app-api-3", "app", "api", "running", "Up 1 second (health: starting)"),
+		composeContainer("app-worker-1", "app", "worker", "restarting", "Restarting (1) 3 seconds ago"),
+		composeContainer("shop-web-1", "shop", "web", "running", "Up 1 hour"),
+	}
+	groups := GroupContainers(containers, ComposeProjectLabel, ComposeServiceLabel)
+	app, shop := groups[0], groups[1]
+
+	tests := []struct {
+		name  string
+		group *ContainerGroup
+		want  GroupSummary
+		text  string
+	}{
+		// Project summaries count the containers of every service.
+		{"app", app, GroupSummary{Total: 5, Running: 3, Unhealthy: 1}, "3/5 running, 1 unhealthy"},
+		{"app api", app.Groups[0], GroupSummary{Total: 3, Running: 2, Unhealthy: 1}, "2/3 running, 1 unhealthy"},
+		{"app worker", app.Groups[2], GroupSummary{Total: 1}, "0/1 running"},
+		{"shop", shop, GroupSummary{Total: 1, Running: 1}, "1/1 running"},
+	}
+	for _, tt := range tests {
+		if got := tt.group.Summary(); got != tt.want {
+			t.Errorf("%s: Summary() = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if got := tt.group.Summary().String(); got != tt.text {
+			t.Errorf("%s: summary text = %q, want %q", tt.name, got, tt.text)
+		}
+	}
+	if n := len(app.All()); n != 5 {
+		t.Errorf("app.All() has %d containers, want 5", n)
+	}
+}
+
+func TestLabelKeys(t *testing.T) {
+	containers := []Container{
+		composeContainer("a", "app", "web", "running", ""),
+		{Name: "b", Labels: map[string]string{"maintainer": "me", ComposeProjectLabel: "shop"}},
+		{Name: "c"},
+	}
+	want := []string{ComposeProjectLabel, ComposeServiceLabel, "maintainer"}
+	if got := LabelKeys(containers); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("LabelKeys() = %v, want %v", got, want)
+	}
+	if got := LabelKeys(nil); len(got) != 0 {
+		t.Errorf("LabelKeys(nil) = %v", got)
+	}
+}
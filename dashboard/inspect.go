@@ -0,0 +1,231 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	dockerNetwork "github.com/docker/docker/api/types/network"
+)
+
+// InspectRaw returns the daemon's inspect output for a resource as JSON.
+// kind is "container", "image", "volume" or "network".
+func (s *Service) InspectRaw(ctx context.Context, kind, id string) ([]byte, error) {
+	var (
+		raw []byte
+		err error
+	)
+	switch kind {
+	case "container":
+		_, raw, err = s.cli.ContainerInspectWithRaw(ctx, id, false)
+	case "image":
+		_, raw, err = s.cli.ImageInspectWithRaw(ctx, id)
+	case "volume":
+		_, raw, err = s.cli.VolumeInspectWithRaw(ctx, id)
+	case "network":
+		_, raw, err = s.cli.NetworkInspectWithRaw(ctx, id, dockerNetwork.InspectOptions{})
+	default:
+		return nil, fmt.Errorf("cannot inspect %s", kind)
+	}
+	if err != nil {
+		return nil, resourceError("inspect", kind, id, err)
+	}
+	return raw, nil
+}
+
+// JSONKind is the type of a node in a JSONTree.
+type JSONKind int
+
+const (
+	JSONScalar JSONKind = iota
+	JSONObject
+	JSONArray
+)
+
+// JSONNode is one value in a JSONTree.
+type JSONNode struct {
+	// Key is the object key or array index; it is empty for the root.
+	Key string
+	// Path addresses the node in jq syntax, e.g. .Config.Env[0]. The root's
+	// path is empty.
+	Path   string
+	Parent string
+	Kind   JSONKind
+	// Value is the JSON text of a scalar.
+	Value string
+	// Children holds the paths of an object's or array's members in
+	// document order.
+	Children []string
+}
+
+// JSONTree is a parsed JSON document addressable by path, keeping the
+// document's key order.
+type JSONTree struct {
+	nodes map[string]*JSONNode
+}
+
+// ParseJSONTree parses a JSON document.
+func ParseJSONTree(raw []byte) (*JSONTree, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	t := &JSONTree{nodes: map[string]*JSONNode{}}
+	if err := t.parse(dec, "", "", ""); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+	return t, nil
+}
+
+func (t *JSONTree) parse(dec *json.Decoder, key, path, parent string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	node := &JSONNode{Key: key, Path: path, Parent: parent}
+	t.nodes[path] = node
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		node.Kind = JSONScalar
+		node.Value = scalarJSON(tok)
+		return nil
+	}
+	if delim == '{' {
+		node.Kind = JSONObject
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			k, _ := tok.(string)
+			child := path + pathKey(k)
+			node.Children = append(node.Children, child)
+			if err := t.parse(dec, k, child, path); err != nil {
+				return err
+			}
+		}
+	} else {
+		node.Kind = JSONArray
+		for i := 0; dec.More(); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			node.Children = append(node.Children, child)
+			if err := t.parse(dec, fmt.Sprint(i), child, path); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = dec.Token() // closing delimiter
+	return err
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pathKey renders an object key as a jq path step.
+func pathKey(k string) string {
+	if identifierPattern.MatchString(k) {
+		return "." + k
+	}
+	quoted, _ := json.Marshal(k)
+	return "[" + string(quoted) + "]"
+}
+
+func scalarJSON(tok json.Token) string {
+	switch v := tok.(type) {
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	}
+	raw, _ := json.Marshal(tok)
+	return string(raw)
+}
+
+// Node returns the node at path, or nil.
+func (t *JSONTree) Node(path string) *JSONNode {
+	return t.nodes[path]
+}
+
+// PlainValue returns a scalar's value without JSON quoting, or a branch's
+// JSON, indented.
+func (t *JSONTree) PlainValue(path string) string {
+	n := t.nodes[path]
+	if n == nil {
+		return ""
+	}
+	if n.Kind == JSONScalar {
+		var s string
+		if json.Unmarshal([]byte(n.Value), &s) == nil {
+			return s
+		}
+		return n.Value
+	}
+	var compact, indented bytes.Buffer
+	t.writeJSON(&compact, n)
+	if json.Indent(&indented, compact.Bytes(), "", "  ") != nil {
+		return compact.String()
+	}
+	return indented.String()
+}
+
+func (t *JSONTree) writeJSON(buf *bytes.Buffer, n *JSONNode) {
+	switch n.Kind {
+	case JSONScalar:
+		buf.WriteString(n.Value)
+	case JSONObject:
+		buf.WriteByte('{')
+		for i, child := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			c := t.nodes[child]
+			key, _ := json.Marshal(c.Key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			t.writeJSON(buf, c)
+		}
+		buf.WriteByte('}')
+	case JSONArray:
+		buf.WriteByte('[')
+		for i, child := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			t.writeJSON(buf, t.nodes[child])
+		}
+		buf.WriteByte(']')
+	}
+}
+
+// Search returns, in document order, the paths of nodes whose key or scalar
+// value contains query, ignoring case.
+func (t *JSONTree) Search(query string) []string {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	var matches []string
+	var walk func(path string)
+	walk = func(path string) {
+		n := t.nodes[path]
+		if path != "" && (strings.Contains(strings.ToLower(n.Key), query) ||
+			n.Kind == JSONScalar && strings.Contains(strings.ToLower(n.Value), query)) {
+			matches = append(matches, path)
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk("")
+	return matches
+}
+
+// Ancestors returns the paths of the branches containing path, outermost
+// first.
+func (t *JSONTree) Ancestors(path string) []string {
+	var result []string
+	for n := t.nodes[path]; n != nil && n.Path != ""; n = t.nodes[n.Parent] {
+		result = append([]string{n.Parent}, result...)
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package dashboard
+
+import (
+	"slices"
+	"testing"
+)
+
+const inspectSample = `{
+  "Id": "abc",
+  "State": {"Running": true, "Pid": 42, "Error": ""},
+  "Config": {
+    "Env": ["PATH=/usr/bin", "MODE=prod"],
+    "Labels": {"com.example.team": "web", "tier": null}
+  },
+  "Mounts": [],
+  "Size": 1.5e3
+}`
+
+func TestParseJSONTree(t *testing.T) {
+	tree, err := ParseJSONTree([]byte(inspectSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := tree.Node("")
+	if root == nil || root.Kind != JSONObject {
+		t.Fatalf("root = %+v", root)
+	}
+	// Children keep the document's order, not the sorted one.
+	if want := []string{".Id", ".State", ".Config", ".Mounts", ".Size"}; !slices.Equal(root.Children, want) {
+		t.Errorf("root children = %v, want %v", root.Children, want)
+	}
+
+	tests := []struct {
+		path, key, parent string
+		kind              JSONKind
+		value             string
+	}{
+		{".Id", "Id", "", JSONScalar, `"abc"`},
+		{".State.Running", "Running", ".State", JSONScalar, "true"},
+		{".State.Pid", "Pid", ".State", JSONScalar, "42"},
+		{".Config.Env", "Env", ".Config", JSONArray, ""},
+		{".Config.Env[1]", "1", ".Config.Env", JSONScalar, `"MODE=prod"`},
+		// Keys that are not identifiers are quoted.
+		{`.Config.Labels["com.example.team"]`, "com.example.team", ".Config.Labels", JSONScalar, `"web"`},
+		{".Config.Labels.tier", "tier", ".Config.Labels", JSONScalar, "null"},
+		{".Mounts", "Mounts", "", JSONArray, ""},
+		// Numbers keep the text they were written with.
+		{".Size", "Size", "", JSONScalar, "1.5e3"},
+	}
+	for _, tt := range tests {
+		n := tree.Node(tt.path)
+		if n == nil {
+			t.Errorf("%s: no node", tt.path)
+			continue
+		}
+		if n.Path != tt.path || n.Key != tt.key || n.Parent != tt.parent || n.Kind != tt.kind || n.Value != tt.value {
+			t.Errorf("%s = %+v, want key %q parent %q kind %d value %s", tt.path, n, tt.key, tt.parent, tt.kind, tt.value)
+		}
+	}
+	if n := tree.Node(".Mounts"); len(n.Children) != 0 {
+		t.Errorf("empty array has children %v", n.Children)
+	}
+	if tree.Node(".Nope") != nil {
+		t.Error("a missing path has a node")
+	}
+}
+
+func TestParseJSONTreeErrors(t *testing.T) {
+	for _, raw := range []string{``, `{`, `{"a":}`, `[1,`, `{"a" 1}`} {
+		if _, err := ParseJSONTree([]byte(raw)); err == nil {
+			t.Errorf("%q: no error", raw)
+		}
+	}
+	tree, err := ParseJSONTree([]byte(`"just a string"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := tree.Node(""); n.Kind != JSONScalar || tree.PlainValue("") != "just a string" {
+		t.Errorf("scalar root = %+v", n)
+	}
+}
+
+func TestJSONTreePlainValue(t *testing.T) {
+	tree, err := ParseJSONTree([]byte(inspectSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		".Id":            "abc",
+		".State.Error":   "",
+		".State.Pid":     "42",
+		".State.Running": "true",
+		".Config.Env[0]": "PATH=/usr/bin",
+		".Size":          "1.5e3",
+		".Mounts":        "[]",
+		".Config.Env":    "[\n  \"PATH=/usr/bin\",\n  \"MODE=prod\"\n]",
+		".Config.Labels": "{\n  \"com.example.team\": \"web\",\n  \"tier\": null\n}",
+		".Missing":       "",
+	}
+	for path, want := range tests {
+		if got := tree.PlainValue(path); got != want {
+			t.Errorf("PlainValue(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestJSONTreeSearch(t *testing.T) {
+	tree, err := ParseJSONTree([]byte(inspectSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		// Keys and scalar values match, ignoring case, in document order.
+		{"mode", []string{".Config.Env[1]"}},
+		{"env", []string{".Config.Env"}},
+		{"TEAM", []string{`.Config.Labels["com.example.team"]`}},
+		{"run", []string{".State.Running"}},
+		{"true", []string{".State.Running"}},
+		{"/usr", []string{".Config.Env[0]"}},
+		{"nothing here", nil},
+	}
+	for _, tt := range tests {
+		if got := tree.Search(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestJSONTreeAncestors(t *testing.T) {
+	tree, err := ParseJSONTree([]byte(inspectSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		".Config.Env[1]": {"", ".Config", ".Config.Env"},
+		".Id":            {""},
+		"":               nil,
+		".Missing":       nil,
+	}
+	for path, want := range tests {
+		if got := tree.Ancestors(path); !slices.Equal(got, want) {
+			t.Errorf("Ancestors(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
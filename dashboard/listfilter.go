@@ -0,0 +1,191 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ExitedWithinFilter is a container filter Docker lacks: exited-within=24h
+// keeps the containers that exited in the last 24 hours. ListContainers
+// applies it after listing, since it needs each container's finish time.
+const ExitedWithinFilter = "exited-within"
+
+// FilterPreset is a named set of list filters.
+type FilterPreset struct {
+	Name    string       `json:"name"`
+	Filters filters.Args `json:"filters"`
+}
+
+// UnmarshalJSON decodes a preset. filters.Args cannot decode into its zero
+// value, so the filters go through filters.FromJSON.
+func (p *FilterPreset) UnmarshalJSON(raw []byte) error {
+	var v struct {
+		Name    string          `json:"name"`
+		Filters json.RawMessage `json:"filters"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	args, err := filters.FromJSON(string(v.Filters))
+	if err != nil {
+		return fmt.Errorf("filter preset %q: %w", v.Name, err)
+	}
+	p.Name, p.Filters = v.Name, args
+	return nil
+}
+
+// DefaultFilterPresets returns the presets offered for a list of kind
+// "containers", "images", "volumes" or "networks" before the user saves
+// any.
+func DefaultFilterPresets(kind string) []FilterPreset {
+	switch kind {
+	case "containers":
+		return []FilterPreset{
+			{"Running", filters.NewArgs(filters.Arg("status", "running"))},
+			{"Exited", filters.NewArgs(filters.Arg("status", "exited"))},
+			{"Exited in last day", filters.NewArgs(filters.Arg("status", "exited"), filters.Arg(ExitedWithinFilter, "24h"))},
+			{"Unhealthy", filters.NewArgs(filters.Arg("health", "unhealthy"))},
+			{"Compose managed", filters.NewArgs(filters.Arg("label", ComposeProjectLabel))},
+		}
+	case "images":
+		return []FilterPreset{
+			{"Dangling images", filters.NewArgs(filters.Arg("dangling", "true"))},
+			{"Older than a week", filters.NewArgs(filters.Arg("until", "168h"))},
+		}
+	case "volumes":
+		return []FilterPreset{
+			{"Unused volumes", filters.NewArgs(filters.Arg("dangling", "true"))},
+			{"Local driver", filters.NewArgs(filters.Arg("driver", "local"))},
+		}
+	case "networks":
+		return []FilterPreset{
+			{"User-defined", filters.NewArgs(filters.Arg("type", "custom"))},
+			{"Unused networks", filters.NewArgs(filters.Arg("dangling", "true"))},
+			{"Bridge networks", filters.NewArgs(filters.Arg("driver", "bridge"))},
+		}
+	}
+	return nil
+}
+
+// splitExitedWithin returns a copy of f without ExitedWithinFilter and the
+// duration it held, zero when absent.
+func splitExitedWithin(f filters.Args) (filters.Args, time.Duration, error) {
+	values := f.Get(ExitedWithinFilter)
+	if len(values) == 0 {
+		return f, 0, nil
+	}
+	if len(values) > 1 {
+		return f, 0, fmt.Errorf("%s filter given more than once", ExitedWithinFilter)
+	}
+	d, err := time.ParseDuration(values[0])
+	if err != nil || d <= 0 {
+		return f, 0, fmt.Errorf("invalid %s duration %q: want e.g. 30m or 24h", ExitedWithinFilter, values[0])
+	}
+	f = f.Clone()
+	f.Del(ExitedWithinFilter, values[0])
+	return f, d, nil
+}
+
+// finishTimes caches the finish times of exited containers so that
+// reloading a list filtered with ExitedWithinFilter does not inspect every
+// exited container again. It is only used while the event stream is
+// connected: die events record the time directly, and a container that
+// starts again or is removed is forgotten. Events missed while
+// disconnected could leave stale times, so the cache is emptied on every
+// (re)connection and off while the stream is down.
+type finishTimes struct {
+	mu    sync.Mutex
+	times map[string]time.Time // nil while off
+}
+
+// reset empties the cache, turning it on or off.
+func (f *finishTimes) reset(on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.times = nil
+	if on {
+		f.times = map[string]time.Time{}
+	}
+}
+
+// observe updates the cache from a container event.
+func (f *finishTimes) observe(e Event) {
+	if e.Type != events.ContainerEventType {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.times == nil {
+		return
+	}
+	switch e.Action {
+	case events.ActionDie:
+		f.times[e.ID] = e.Time
+	case events.ActionStart, events.ActionRestart, events.ActionDestroy:
+		delete(f.times, e.ID)
+	}
+}
+
+func (f *finishTimes) get(id string) (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.times[id]
+	return t, ok
+}
+
+// put caches a finish time read by inspecting the container. A time a die
+// event recorded meanwhile is newer and kept.
+func (f *finishTimes) put(id string, t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.times[id]; !ok && f.times != nil {
+		f.times[id] = t
+	}
+}
+
+// exitedSince keeps the containers that exited or died after since.
+// Containers removed meanwhile are dropped.
+func (s *Service) exitedSince(ctx context.Context, containers []Container, since time.Time) ([]Container, error) {
+	var candidates []Container
+	for _, c := range containers {
+		if c.State == "exited" || c.State == "dead" {
+			candidates = append(candidates, c)
+		}
+	}
+	finished := make([]time.Time, len(candidates))
+	errs := RunBulk(ctx, len(candidates), BulkWorkers, func(ctx context.Context, i int) error {
+		id := candidates[i].ID
+		if t, ok := s.finished.get(id); ok {
+			finished[i] = t
+			return nil
+		}
+		info, err := s.InspectContainer(ctx, id)
+		if err != nil {
+			return err
+		}
+		if info.State != nil {
+			finished[i], _ = time.Parse(time.RFC3339Nano, info.State.FinishedAt)
+			s.finished.put(id, finished[i])
+		}
+		return nil
+	})
+	var result []Container
+	for i, c := range candidates {
+		switch {
+		case errs[i] == nil:
+			if finished[i].After(since) {
+				result = append(result, c)
+			}
+		case !errors.Is(errs[i], ErrNotFound):
+			return nil, errs[i]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
+)
+
+// finishClient answers container inspects with fixed finish times.
+type finishClient struct {
+	fakeClient
+	finishedAt map[string]time.Time
+	inspects   int
+}
+
+func (c *finishClient) ContainerInspect(_ context.Context, id string) (types.ContainerJSON, error) {
+	c.inspects++
+	t, ok := c.finishedAt[id]
+	if !ok {
+		return types.ContainerJSON{}, errdefs.NotFound(errors.New("No such container: " + id))
+	}
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		ID:    id,
+		State: &types.ContainerState{Status: "exited", FinishedAt: t.Format(time.RFC3339Nano)},
+	}}, nil
+}
+
+func TestSplitExitedWithin(t *testing.T) {
+	f := filters.NewArgs(filters.Arg("status", "exited"), filters.Arg(ExitedWithinFilter, "24h"))
+	rest, d, err := splitExitedWithin(f)
+	if err != nil || d != 24*time.Hour || rest.Contains(ExitedWithinFilter) || !rest.ExactMatch("status", "exited") {
+		t.Errorf("split = %v, %v, %v", rest, d, err)
+	}
+	if !f.Contains(ExitedWithinFilter) {
+		t.Error("the caller's filters were changed")
+	}
+	for _, bad := range []string{"soon", "0s", "-1h"} {
+		if _, _, err := splitExitedWithin(filters.NewArgs(filters.Arg(ExitedWithinFilter, bad))); err == nil {
+			t.Errorf("%q: no error", bad)
+		}
+	}
+	if _, _, err := splitExitedWithin(filters.NewArgs(filters.Arg(ExitedWithinFilter, "1h"), filters.Arg(ExitedWithinFilter, "2h"))); err == nil {
+		t.Error("a repeated filter is accepted")
+	}
+}
+
+func TestExitedWithinCachesFinishTimes(t *testing.T) {
+	svc, fake := newFakeService()
+	cli := &finishClient{fakeClient: *fake, finishedAt: map[string]time.Time{dbID: time.Now().Add(-time.Hour)}}
+	svc.cli = cli
+	f := filters.NewArgs(filters.Arg(ExitedWithinFilter, "24h"))
+	list := func() []Container {
+		t.Helper()
+		result, err := svc.ListContainers(context.Background(), f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Without an event stream every reload inspects the exited container.
+	list()
+	if got := list(); len(got) != 1 || got[0].ID != dbID || cli.inspects != 2 {
+		t.Fatalf("uncached: %v after %d inspects", got, cli.inspects)
+	}
+
+	// While connected, the finish time is only read once.
+	svc.finished.reset(true)
+	list()
+	list()
+	if cli.inspects != 3 {
+		t.Errorf("cached: %d inspects, want 3", cli.inspects)
+	}
+
+	// A die event records the new finish time without an inspect.
+	svc.finished.observe(Event{Type: events.ContainerEventType, Action: events.ActionDie, ID: dbID, Time: time.Now().Add(-48 * time.Hour)})
+	if got := list(); len(got) != 0 || cli.inspects != 3 {
+		t.Errorf("after die: %v after %d inspects", got, cli.inspects)
+	}
+
+	// A container that starts again is inspected once it has exited.
+	svc.finished.observe(Event{Type: events.ContainerEventType, Action: events.ActionStart, ID: dbID, Time: time.Now()})
+	if got := list(); len(got) != 1 || cli.inspects != 4 {
+		t.Errorf("after start: %v after %d inspects", got, cli.inspects)
+	}
+
+	// A lost stream may have missed events, so the cache is dropped.
+	svc.finished.reset(false)
+	list()
+	if cli.inspects != 5 {
+		t.Errorf("after disconnect: %d inspects, want 5", cli.inspects)
+	}
+}
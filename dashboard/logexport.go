@@ -0,0 +1,295 @@
+package dashboard
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LogFormat selects how exported log lines are written.
+type LogFormat int
+
+const (
+	// LogFormatText writes one line per log line, prefixed with its
+	// timestamp and container.
+	LogFormatText LogFormat = iota
+	// LogFormatJSON writes one JSON object per line with time, container,
+	// stream and message fields.
+	LogFormatJSON
+)
+
+// Ext returns the file extension for the format.
+func (f LogFormat) Ext() string {
+	if f == LogFormatJSON {
+		return ".jsonl"
+	}
+	return ".log"
+}
+
+// exportedLine is the JSON lines record.
+type exportedLine struct {
+	Time      *time.Time `json:"time,omitempty"`
+	Container string     `json:"container,omitempty"`
+	Stream    string     `json:"stream"`
+	Message   string     `json:"message"`
+}
+
+// LogWriter encodes log lines onto an underlying writer, optionally gzip
+// compressed. Close flushes it but does not close the underlying writer.
+type LogWriter struct {
+	format LogFormat
+	gz     *gzip.Writer
+	w      io.Writer
+	enc    *json.Encoder
+}
+
+// NewLogWriter returns a writer encoding lines in format onto w.
+func NewLogWriter(w io.Writer, format LogFormat, compress bool) *LogWriter {
+	lw := &LogWriter{format: format, w: w}
+	if compress {
+		lw.gz = gzip.NewWriter(w)
+		lw.w = lw.gz
+	}
+	lw.enc = json.NewEncoder(lw.w)
+	return lw
+}
+
+// Write encodes a single line.
+func (lw *LogWriter) Write(line LogLine) error {
+	if lw.format == LogFormatJSON {
+		rec := exportedLine{Container: line.Container, Stream: line.Stream.String(), Message: line.Text}
+		if !line.Time.IsZero() {
+			rec.Time = &line.Time
+		}
+		return lw.enc.Encode(rec)
+	}
+	prefix := ""
+	if !line.Time.IsZero() {
+		prefix = line.Time.Format(time.RFC3339Nano) + " "
+	}
+	if line.Container != "" {
+		prefix += line.Container + " | "
+	}
+	_, err := fmt.Fprintf(lw.w, "%s%s\n", prefix, line.Text)
+	return err
+}
+
+// Close flushes buffered output.
+func (lw *LogWriter) Close() error {
+	if lw.gz != nil {
+		return lw.gz.Close()
+	}
+	return nil
+}
+
+// RotateOptions configures a capture to disk.
+type RotateOptions struct {
+	// Dir receives the files, named <Prefix>-<start time><ext>.
+	Dir    string
+	Prefix string
+	// MaxBytes starts a new file once the current one reaches this size on
+	// disk; zero disables size-based rotation.
+	MaxBytes int64
+	// MaxAge starts a new file once the current one is this old; zero
+	// disables time-based rotation.
+	MaxAge   time.Duration
+	Format   LogFormat
+	Compress bool
+}
+
+// rotateCheckInterval is how often a RotatingLogWriter flushes its buffer
+// and closes a file that has grown too old while the log was quiet.
+const rotateCheckInterval = time.Second
+
+// RotatingLogWriter writes log lines to a series of files, starting a new
+// one by size or age. Writes are buffered and flushed every second, and a
+// file past MaxAge is closed then even if no more lines arrive; the next
+// line opens a new one. It is safe for concurrent use.
+type RotatingLogWriter struct {
+	opts RotateOptions
+	done chan struct{}
+
+	mu      sync.Mutex
+	file    *os.File
+	buf     *bufio.Writer
+	counter *countingWriter
+	lw      *LogWriter
+	opened  time.Time
+	files   []string
+	lines   int
+	closed  bool
+	err     error // from a background flush or rotation
+}
+
+// NewRotatingLogWriter prepares a capture; the first file is created on the
+// first line.
+func NewRotatingLogWriter(opts RotateOptions) (*RotatingLogWriter, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create capture directory: %w", err)
+	}
+	r := &RotatingLogWriter{opts: opts, done: make(chan struct{})}
+	go r.run()
+	return r, nil
+}
+
+func (r *RotatingLogWriter) run() {
+	ticker := time.NewTicker(rotateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.tick()
+		}
+	}
+}
+
+// tick flushes buffered lines and closes the current file once it is too
+// old. An error is kept for the next Write or Close.
+func (r *RotatingLogWriter) tick() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil || r.err != nil {
+		return
+	}
+	if r.opts.MaxAge > 0 && time.Since(r.opened) >= r.opts.MaxAge {
+		r.err = r.closeFile()
+		return
+	}
+	if err := r.buf.Flush(); err != nil {
+		r.err = fmt.Errorf("write %s: %w", r.file.Name(), err)
+	}
+}
+
+// Write appends a line, rotating first if the current file is full or old.
+func (r *RotatingLogWriter) Write(line LogLine) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if r.closed {
+		return errors.New("capture is closed")
+	}
+	if r.file == nil || r.due() {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := r.lw.Write(line); err != nil {
+		return fmt.Errorf("write %s: %w", r.file.Name(), err)
+	}
+	r.lines++
+	return nil
+}
+
+func (r *RotatingLogWriter) due() bool {
+	if r.opts.MaxBytes > 0 && r.counter.n >= r.opts.MaxBytes {
+		return true
+	}
+	return r.opts.MaxAge > 0 && time.Since(r.opened) >= r.opts.MaxAge
+}
+
+func (r *RotatingLogWriter) rotate() error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	now := time.Now()
+	ext := r.opts.Format.Ext()
+	if r.opts.Compress {
+		ext += ".gz"
+	}
+	base := r.opts.Prefix + "-" + now.Format("20060102-150405.000")
+	// Never overwrite an earlier file, even when rotating twice within a
+	// millisecond.
+	var (
+		path string
+		f    *os.File
+		err  error
+	)
+	for i := 0; ; i++ {
+		path = filepath.Join(r.opts.Dir, base+ext)
+		if i > 0 {
+			path = filepath.Join(r.opts.Dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+		}
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("create capture file: %w", err)
+	}
+	r.file = f
+	r.buf = bufio.NewWriter(f)
+	r.counter = &countingWriter{w: r.buf}
+	r.lw = NewLogWriter(r.counter, r.opts.Format, r.opts.Compress)
+	r.opened = now
+	r.files = append(r.files, path)
+	return nil
+}
+
+func (r *RotatingLogWriter) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.lw.Close()
+	if ferr := r.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file = nil
+	if err != nil {
+		return fmt.Errorf("close capture file: %w", err)
+	}
+	return nil
+}
+
+// Close finishes the current file.
+func (r *RotatingLogWriter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	return errors.Join(r.err, r.closeFile())
+}
+
+// Files returns the paths written so far, oldest first.
+func (r *RotatingLogWriter) Files() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.files...)
+}
+
+// Lines returns how many lines were written.
+func (r *RotatingLogWriter) Lines() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lines
+}
+
+// countingWriter counts the bytes bound for the file.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingLogWriterQuietAge(t *testing.T) {
+	r, err := NewRotatingLogWriter(RotateOptions{Dir: t.TempDir(), Prefix: "app", MaxAge: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write(LogLine{Text: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	first := r.Files()[0]
+
+	// A quiet log still has its old file finished once it is too old.
+	time.Sleep(2 * time.Millisecond)
+	r.tick()
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\n" {
+		t.Errorf("first file = %q, want %q", data, "first\n")
+	}
+
+	if err := r.Write(LogLine{Text: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := r.Files()
+	if len(files) != 2 {
+		t.Fatalf("files = %v, want 2", files)
+	}
+	data, err = os.ReadFile(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second\n" {
+		t.Errorf("second file = %q, want %q", data, "second\n")
+	}
+}
+
+func TestRotatingLogWriterBuffers(t *testing.T) {
+	r, err := NewRotatingLogWriter(RotateOptions{Dir: t.TempDir(), Prefix: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for i := 0; i < 3; i++ {
+		if err := r.Write(LogLine{Text: "line"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := r.Files()[0]
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("lines reached the file before a flush: %q", data)
+	}
+	r.tick()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "line\n"); got != 3 {
+		t.Errorf("flushed %d lines, want 3", got)
+	}
+}
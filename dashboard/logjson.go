@@ -0,0 +1,157 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fieldAliases lets the well-known columns find their value under the names
+// used by common logging libraries.
+var fieldAliases = map[string][]string{
+	"time":  {"time", "ts", "timestamp", "@timestamp", "t"},
+	"level": {"level", "lvl", "severity", "levelname", "log.level"},
+	"msg":   {"msg", "message", "@message"},
+}
+
+// parseJSONFields returns the fields of a line that holds a single JSON
+// object, or nil for anything else.
+func parseJSONFields(text string) map[string]any {
+	t := strings.TrimSpace(text)
+	if len(t) < 2 || t[0] != '{' || t[len(t)-1] != '}' {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(t))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil || dec.More() {
+		return nil
+	}
+	return fields
+}
+
+// Field returns a field of a JSON log line formatted for display. Nested
+// objects are addressed with dotted paths such as "http.status", and the
+// names "time", "level" and "msg" also match their common aliases.
+func (l LogLine) Field(name string) (string, bool) {
+	if l.Fields == nil {
+		return "", false
+	}
+	names, ok := fieldAliases[name]
+	if !ok {
+		names = []string{name}
+	}
+	for _, n := range names {
+		if v, ok := lookupField(l.Fields, n); ok {
+			return formatFieldValue(v), true
+		}
+	}
+	return "", false
+}
+
+func lookupField(fields map[string]any, path string) (any, bool) {
+	if v, ok := fields[path]; ok {
+		return v, true
+	}
+	head, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return nil, false
+	}
+	nested, ok := fields[head].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupField(nested, rest)
+}
+
+func formatFieldValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+// JSONFieldNames lists the fields found in the JSON lines, sorted, with nested
+// objects flattened to dotted paths.
+func JSONFieldNames(lines []LogLine) []string {
+	seen := map[string]bool{}
+	for _, line := range lines {
+		collectFieldNames(line.Fields, "", seen)
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func collectFieldNames(fields map[string]any, prefix string, seen map[string]bool) {
+	for k, v := range fields {
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			collectFieldNames(nested, prefix+k+".", seen)
+			continue
+		}
+		seen[prefix+k] = true
+	}
+}
+
+// FieldFilter keeps JSON log lines whose field equals (or, negated, differs
+// from) a value. Values are compared case-insensitively.
+type FieldFilter struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// ParseFieldFilters parses space-separated "field=value" and "field!=value"
+// terms, such as "level=error service=api".
+func ParseFieldFilters(query string) ([]FieldFilter, error) {
+	var filters []FieldFilter
+	for _, term := range strings.Fields(query) {
+		f := FieldFilter{}
+		if field, value, ok := strings.Cut(term, "!="); ok {
+			f = FieldFilter{Field: field, Value: value, Negate: true}
+		} else if field, value, ok := strings.Cut(term, "="); ok {
+			f = FieldFilter{Field: field, Value: value}
+		} else {
+			return nil, fmt.Errorf("invalid filter %q: expected field=value", term)
+		}
+		if f.Field == "" {
+			return nil, fmt.Errorf("invalid filter %q: missing field name", term)
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// Match reports whether line passes the filter. Plain text lines have no
+// fields and only pass negated filters.
+func (f FieldFilter) Match(line LogLine) bool {
+	value, ok := line.Field(f.Field)
+	equal := ok && strings.EqualFold(value, f.Value)
+	return equal != f.Negate
+}
+
+// MatchFieldFilters reports whether line passes all filters.
+func MatchFieldFilters(filters []FieldFilter, line LogLine) bool {
+	for _, f := range filters {
+		if !f.Match(line) {
+			return false
+		}
+	}
+	return true
+}
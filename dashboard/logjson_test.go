@@ -0,0 +1,140 @@
+package dashboard
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseJSONFields(t *testing.T) {
+	tests := []struct {
+		text string
+		ok   bool
+	}{
+		{`{"level":"info","msg":"started"}`, true},
+		{`  {"a":1}  `, true},
+		{`{}`, true},
+		{`plain text`, false},
+		{`[1,2]`, false},
+		{`{"a":1} {"b":2}`, false},
+		{`{"a":1} trailing}`, false},
+		{`{"a":}`, false},
+		{`{`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := parseJSONFields(tt.text); (got != nil) != tt.ok {
+			t.Errorf("parseJSONFields(%q) = %v, want ok %v", tt.text, got, tt.ok)
+		}
+	}
+}
+
+func TestLogLineField(t *testing.T) {
+	line := LogLine{Fields: parseJSONFields(`{"ts":"2024-05-01T10:00:00Z","severity":"WARN","message":"slow",` +
+		`"http":{"status":503,"path":"/api"},"a.b":"dotted","count":1e3,"big":12345678901234567890,` +
+		`"ok":true,"none":null,"tags":["x","y"],"msg":"preferred"}`)}
+	tests := []struct {
+		name, want string
+		ok         bool
+	}{
+		{"time", "2024-05-01T10:00:00Z", true},
+		{"level", "WARN", true},
+		// "msg" itself comes before its aliases.
+		{"msg", "preferred", true},
+		{"message", "slow", true},
+		{"http.status", "503", true},
+		{"http.path", "/api", true},
+		{"http", `{"path":"/api","status":503}`, true},
+		// A key containing a dot is found before a nested path.
+		{"a.b", "dotted", true},
+		// Numbers keep the text they were written with.
+		{"count", "1e3", true},
+		{"big", "12345678901234567890", true},
+		{"ok", "true", true},
+		{"none", "null", true},
+		{"tags", `["x","y"]`, true},
+		{"http.method", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := line.Field(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Field(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := (LogLine{Text: "plain"}).Field("msg"); ok {
+		t.Error("a plain line has fields")
+	}
+}
+
+func TestJSONFieldNames(t *testing.T) {
+	lines := []LogLine{
+		{Fields: parseJSONFields(`{"level":"info","http":{"status":200,"req":{"id":"1"}}}`)},
+		{Text: "plain"},
+		{Fields: parseJSONFields(`{"level":"warn","empty":{},"msg":"x"}`)},
+	}
+	want := []string{"empty", "http.req.id", "http.status", "level", "msg"}
+	if got := JSONFieldNames(lines); !slices.Equal(got, want) {
+		t.Errorf("JSONFieldNames = %v, want %v", got, want)
+	}
+}
+
+func TestParseFieldFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []FieldFilter
+		err   bool
+	}{
+		{"", nil, false},
+		{"level=error", []FieldFilter{{Field: "level", Value: "error"}}, false},
+		{"  level=error   service!=api ", []FieldFilter{{Field: "level", Value: "error"}, {Field: "service", Value: "api", Negate: true}}, false},
+		{"http.status=500", []FieldFilter{{Field: "http.status", Value: "500"}}, false},
+		{"msg=", []FieldFilter{{Field: "msg"}}, false},
+		{"expr=a=b", []FieldFilter{{Field: "expr", Value: "a=b"}}, false},
+		{"level", nil, true},
+		{"=error", nil, true},
+		{"!=error", nil, true},
+		{"level=error oops", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFieldFilters(tt.query)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: err = %v, want error %v", tt.query, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchFieldFilters(t *testing.T) {
+	jsonLine := LogLine{Fields: parseJSONFields(`{"lvl":"ERROR","service":"api","http":{"status":500}}`)}
+	plain := LogLine{Text: "level=error"}
+	tests := []struct {
+		query       string
+		json, plain bool
+	}{
+		{"", true, true},
+		{"level=error", true, false},
+		{"level=warn", false, false},
+		{"level!=warn", true, true},
+		{"level!=error", false, true},
+		{"level=error service=api", true, false},
+		{"level=error service=web", false, false},
+		{"http.status=500", true, false},
+		{"user!=bob", true, true},
+	}
+	for _, tt := range tests {
+		filters, err := ParseFieldFilters(tt.query)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if got := MatchFieldFilters(filters, jsonLine); got != tt.json {
+			t.Errorf("%q on a JSON line = %v, want %v", tt.query, got, tt.json)
+		}
+		if got := MatchFieldFilters(filters, plain); got != tt.plain {
+			t.Errorf("%q on a plain line = %v, want %v", tt.query, got, tt.plain)
+		}
+	}
+}
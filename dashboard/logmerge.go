@@ -0,0 +1,243 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LogSource is one container in a merged log timeline.
+type LogSource struct {
+	ID   string
+	Name string
+}
+
+const (
+	// mergeDelay is how long live lines are held back so that a line from a
+	// slower stream can still be put in front of them.
+	mergeDelay = 500 * time.Millisecond
+	// restartPollInterval is how often a stopped container is checked for a
+	// restart while following.
+	restartPollInterval = time.Second
+)
+
+// StreamMergedLogs reads the logs of several containers and calls handle
+// with their lines interleaved in timestamp order. Timestamps are always
+// requested, since ordering depends on them. When following, a container that
+// stops is picked up again once it restarts, without repeating lines already
+// seen. It returns when every stream has ended or ctx is cancelled.
+func (s *Service) StreamMergedLogs(ctx context.Context, sources []LogSource, opts LogOptions, handle func(LogLine)) error {
+	opts.Timestamps = true
+	m := newLogMerger(len(sources), opts.Follow, handle)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src LogSource) {
+			defer wg.Done()
+			defer m.end(i)
+			emit := func(line LogLine) { m.add(i, line) }
+			if err := s.streamSourceLogs(ctx, src, opts, emit); err != nil && ctx.Err() == nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", src.Name, err))
+				mu.Unlock()
+			}
+		}(i, src)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	if opts.Follow {
+		ticker := time.NewTicker(mergeDelay / 2)
+		defer ticker.Stop()
+	loop:
+		for {
+			select {
+			case <-done:
+				break loop
+			case <-ctx.Done():
+				<-done
+				return ctx.Err()
+			case now := <-ticker.C:
+				m.flush(now.Add(-mergeDelay))
+			}
+		}
+	} else {
+		<-done
+	}
+	m.flush(time.Time{})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.Join(errs...)
+}
+
+// streamSourceLogs streams one container's log, following it across
+// restarts when opts.Follow is set.
+func (s *Service) streamSourceLogs(ctx context.Context, src LogSource, opts LogOptions, emit func(LogLine)) error {
+	var last time.Time
+	for {
+		err := s.StreamLogs(ctx, src.ID, opts, func(line LogLine) {
+			// After a restart the stream resumes at the last timestamp seen,
+			// which the daemon includes again.
+			if !last.IsZero() && !line.Time.After(last) {
+				return
+			}
+			if !line.Time.IsZero() {
+				last = line.Time
+			}
+			emit(line)
+		})
+		if !opts.Follow || ctx.Err() != nil || errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if err := s.waitRunning(ctx, src.ID); err != nil {
+			return err
+		}
+		if !last.IsZero() {
+			opts.Since = fmt.Sprintf("%d.%09d", last.Unix(), last.Nanosecond())
+			opts.Tail = "all"
+		}
+	}
+}
+
+// waitRunning returns once the container is running again.
+func (s *Service) waitRunning(ctx context.Context, id string) error {
+	ticker := time.NewTicker(restartPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		info, err := s.cli.ContainerInspect(ctx, id)
+		if err != nil {
+			return resourceError("inspect", "container", id, err)
+		}
+		if info.State != nil && info.State.Running {
+			return nil
+		}
+	}
+}
+
+// logMerger orders lines from concurrent streams by timestamp with a k-way
+// merge: each stream delivers its own lines in order, so the earliest of the
+// streams' oldest queued lines can be emitted once every stream still
+// running has one queued. While following, a quiet stream would hold up the
+// others forever, so lines are also emitted once they are older than the
+// flush cutoff, and a stream's queue that fills up is drained regardless.
+// Otherwise a stream whose queue is full waits for the others to catch up,
+// which bounds memory for a read of whole logs.
+type logMerger struct {
+	mu     sync.Mutex
+	more   *sync.Cond // signalled when lines are emitted
+	queues [][]pendingLine
+	ended  []bool
+	follow bool
+	handle func(LogLine) // called with mu held, in timestamp order
+}
+
+type pendingLine struct {
+	line    LogLine
+	arrived time.Time
+}
+
+// mergeQueueLines bounds how many lines are held per stream.
+const mergeQueueLines = 1000
+
+func newLogMerger(streams int, follow bool, handle func(LogLine)) *logMerger {
+	m := &logMerger{
+		queues: make([][]pendingLine, streams),
+		ended:  make([]bool, streams),
+		follow: follow,
+		handle: handle,
+	}
+	m.more = sync.NewCond(&m.mu)
+	return m
+}
+
+// add queues a line of stream src and emits what is ready.
+func (m *logMerger) add(src int, line LogLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for !m.follow && len(m.queues[src]) >= mergeQueueLines {
+		m.more.Wait()
+	}
+	m.queues[src] = append(m.queues[src], pendingLine{line: line, arrived: time.Now()})
+	m.emitReady()
+	for m.follow && len(m.queues[src]) > mergeQueueLines {
+		m.emit(m.earliest())
+	}
+}
+
+// end marks stream src as finished, so the others no longer wait for it.
+func (m *logMerger) end(src int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ended[src] = true
+	m.emitReady()
+}
+
+// flush emits lines that arrived before cutoff, in order, stopping at the
+// first one that is still too fresh. A zero cutoff emits everything.
+func (m *logMerger) flush(cutoff time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emitReady()
+	for {
+		src := m.earliest()
+		if src < 0 || !cutoff.IsZero() && !m.queues[src][0].arrived.Before(cutoff) {
+			return
+		}
+		m.emit(src)
+	}
+}
+
+// emitReady emits lines while every running stream has one queued. Call it
+// with mu held.
+func (m *logMerger) emitReady() {
+	for {
+		for i, q := range m.queues {
+			if len(q) == 0 && !m.ended[i] {
+				return
+			}
+		}
+		src := m.earliest()
+		if src < 0 {
+			return
+		}
+		m.emit(src)
+	}
+}
+
+// earliest returns the stream whose oldest queued line comes first, or -1
+// when nothing is queued. Ties go to the first stream. Call it with mu held.
+func (m *logMerger) earliest() int {
+	best := -1
+	for i, q := range m.queues {
+		if len(q) > 0 && (best < 0 || q[0].line.Time.Before(m.queues[best][0].line.Time)) {
+			best = i
+		}
+	}
+	return best
+}
+
+// emit hands on the oldest queued line of stream src. Call it with mu held.
+func (m *logMerger) emit(src int) {
+	q := m.queues[src]
+	line := q[0].line
+	q[0] = pendingLine{}
+	m.queues[src] = q[1:]
+	m.handle(line)
+	m.more.Broadcast()
+}
@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogMergerOrdersStreams(t *testing.T) {
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	const perStream = 3 * mergeQueueLines
+
+	var got []LogLine
+	var m *logMerger
+	m = newLogMerger(2, false, func(line LogLine) {
+		for i, q := range m.queues {
+			if len(q) > mergeQueueLines {
+				t.Errorf("stream %d holds %d lines", i, len(q))
+			}
+		}
+		got = append(got, line)
+	})
+	var wg sync.WaitGroup
+	for src := 0; src < 2; src++ {
+		wg.Add(1)
+		go func(src int) {
+			defer wg.Done()
+			defer m.end(src)
+			for i := 0; i < perStream; i++ {
+				m.add(src, LogLine{Time: base.Add(time.Duration(2*i+src) * time.Millisecond)})
+			}
+		}(src)
+	}
+	wg.Wait()
+	m.flush(time.Time{})
+
+	if len(got) != 2*perStream {
+		t.Fatalf("got %d lines, want %d", len(got), 2*perStream)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Time.Before(got[i-1].Time) {
+			t.Fatalf("line %d at %v comes after %v", i, got[i].Time, got[i-1].Time)
+		}
+	}
+}
+
+func TestLogMergerFollowQuietStream(t *testing.T) {
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	var got []string
+	m := newLogMerger(2, true, func(line LogLine) { got = append(got, line.Text) })
+
+	// Stream 1 is quiet, so stream 0's lines wait for the cutoff.
+	m.add(0, LogLine{Time: base.Add(2 * time.Second), Text: "b"})
+	m.flush(time.Now().Add(-time.Hour))
+	if len(got) != 0 {
+		t.Fatalf("emitted %v before the cutoff", got)
+	}
+	// A late line from the other stream is still put in front.
+	m.add(1, LogLine{Time: base.Add(time.Second), Text: "a"})
+	m.flush(time.Now().Add(time.Hour))
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("got %v, want [a b]", got)
+	}
+}
@@ -0,0 +1,239 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// LogStream tells which output stream a log line came from.
+type LogStream int
+
+const (
+	Stdout LogStream = iota
+	Stderr
+)
+
+func (s LogStream) String() string {
+	if s == Stderr {
+		return "stderr"
+	}
+	return "stdout"
+}
+
+// LogLine is a single line of container output, without its trailing newline.
+type LogLine struct {
+	Stream LogStream
+	// Time is the daemon's timestamp, or zero when timestamps were not
+	// requested.
+	Time time.Time
+	Text string
+	// Container is the name of the container the line came from.
+	Container string
+	// Fields holds the parsed object when Text is a single JSON object, and
+	// is nil for plain text lines.
+	Fields map[string]any
+}
+
+// LogOptions selects which part of a container's log to read.
+type LogOptions struct {
+	// Tail is the number of lines to show from the end, or "all".
+	Tail string
+	// Since and Until accept RFC 3339 timestamps, Unix timestamps or
+	// durations relative to now such as "10m".
+	Since string
+	Until string
+	// Timestamps asks the daemon to stamp each line; LogLine.Time is only
+	// set when it is true.
+	Timestamps bool
+	// Follow keeps the stream open for new output until ctx is cancelled.
+	Follow bool
+}
+
+// maxLogLineLength bounds a single line; longer lines are split so one
+// runaway write cannot grow the buffer without limit.
+const maxLogLineLength = 64 * 1024
+
+// StreamLogs reads a container's log and calls handle for every line, in
+// order. Non-TTY output is demultiplexed into stdout and stderr; TTY output
+// has a single stream and is reported as stdout. It returns when the log is
+// exhausted, or, when following, when ctx is cancelled or the container's
+// output ends.
+func (s *Service) StreamLogs(ctx context.Context, id string, opts LogOptions, handle func(LogLine)) error {
+	info, err := s.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return resourceError("read logs of", "container", id, err)
+	}
+	tty := info.Config != nil && info.Config.Tty
+	name := strings.TrimPrefix(info.Name, "/")
+
+	rc, err := s.cli.ContainerLogs(ctx, id, dockerContainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       opts.Tail,
+		Since:      opts.Since,
+		Until:      opts.Until,
+		Timestamps: opts.Timestamps,
+		Follow:     opts.Follow,
+	})
+	if err != nil {
+		return resourceError("read logs of", "container", id, err)
+	}
+	defer rc.Close()
+
+	return copyLogLines(ctx, rc, tty, opts.Timestamps, func(line LogLine) {
+		line.Container = name
+		handle(line)
+	})
+}
+
+// copyLogLines splits a raw log stream into lines and hands them to handle.
+func copyLogLines(ctx context.Context, r io.Reader, tty, timestamps bool, handle func(LogLine)) error {
+	stdout := &lineWriter{stream: Stdout, timestamps: timestamps, emit: handle}
+	stderr := &lineWriter{stream: Stderr, timestamps: timestamps, emit: handle}
+	var err error
+	if tty {
+		_, err = io.Copy(stdout, r)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, r)
+	}
+	stdout.Flush()
+	stderr.Flush()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read logs: %w", err)
+	}
+	return nil
+}
+
+// lineWriter buffers writes until a full line is available and emits each
+// line separately.
+type lineWriter struct {
+	stream     LogStream
+	timestamps bool
+	emit       func(LogLine)
+	buf        []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		text := p
+		if i >= 0 {
+			text = p[:i]
+		}
+		// A line reaching the limit is emitted in parts, however the
+		// writes split it.
+		if room := maxLogLineLength - len(w.buf); len(text) > room {
+			w.buf = append(w.buf, text[:room]...)
+			w.Flush()
+			p = p[room:]
+			continue
+		}
+		w.buf = append(w.buf, text...)
+		if i < 0 {
+			break
+		}
+		w.Flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush emits any buffered partial line.
+func (w *lineWriter) Flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	text := strings.TrimSuffix(string(w.buf), "\r")
+	w.buf = w.buf[:0]
+	line := LogLine{Stream: w.stream, Text: text}
+	if w.timestamps {
+		// The daemon prefixes "2006-01-02T15:04:05.999999999Z07:00 ".
+		if stamp, rest, ok := strings.Cut(text, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				line.Time = t
+				line.Text = rest
+			}
+		}
+	}
+	line.Fields = parseJSONFields(line.Text)
+	w.emit(line)
+}
+
+// LogBuffer keeps the most recent log lines up to a fixed count, so a chatty
+// container cannot exhaust memory. It is safe for concurrent use.
+type LogBuffer struct {
+	mu    sync.Mutex
+	ring  []LogLine
+	start int // index of the oldest line in ring
+	count int
+	// dropped counts lines discarded because the buffer was full.
+	dropped int
+}
+
+// NewLogBuffer returns a buffer holding at most max lines.
+func NewLogBuffer(max int) *LogBuffer {
+	return &LogBuffer{ring: make([]LogLine, max)}
+}
+
+// Append adds a line, discarding the oldest one when the buffer is full.
+func (b *LogBuffer) Append(line LogLine) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.ring) == 0 {
+		return
+	}
+	if b.count < len(b.ring) {
+		b.ring[(b.start+b.count)%len(b.ring)] = line
+		b.count++
+		return
+	}
+	b.ring[b.start] = line
+	b.start = (b.start + 1) % len(b.ring)
+	b.dropped++
+}
+
+// Lines returns a copy of the buffered lines, oldest first.
+func (b *LogBuffer) Lines() []LogLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := make([]LogLine, b.count)
+	for i := range lines {
+		lines[i] = b.ring[(b.start+i)%len(b.ring)]
+	}
+	return lines
+}
+
+// Len returns the number of buffered lines.
+func (b *LogBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// Dropped returns how many lines were discarded since the last Reset.
+func (b *LogBuffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Reset empties the buffer.
+func (b *LogBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.ring)
+	b.start, b.count, b.dropped = 0, 0, 0
+}
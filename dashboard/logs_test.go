@@ -0,0 +1,210 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// muxLog builds a multiplexed log stream from (stream, data) frames.
+func muxLog(t *testing.T, frames ...any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for i := 0; i < len(frames); i += 2 {
+		std := stdcopy.Stdout
+		if frames[i].(LogStream) == Stderr {
+			std = stdcopy.Stderr
+		}
+		if _, err := stdcopy.NewStdWriter(&buf, std).Write([]byte(frames[i+1].(string))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func collectLogLines(t *testing.T, r io.Reader, tty, timestamps bool) []LogLine {
+	t.Helper()
+	var lines []LogLine
+	if err := copyLogLines(context.Background(), r, tty, timestamps, func(l LogLine) { lines = append(lines, l) }); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestCopyLogLines(t *testing.T) {
+	type want struct {
+		stream LogStream
+		text   string
+	}
+	tests := []struct {
+		name string
+		raw  []byte
+		tty  bool
+		want []want
+	}{
+		{"demux", muxLog(t, Stdout, "a\nb", Stderr, "oops\n", Stdout, "c\r\nd"),
+			false, []want{{Stdout, "a"}, {Stderr, "oops"}, {Stdout, "bc"}, {Stdout, "d"}}},
+		// Empty lines are kept, but a trailing newline does not add one.
+		{"empty lines", muxLog(t, Stdout, "a\n\nb\n"), false, []want{{Stdout, "a"}, {Stdout, "b"}}},
+		// TTY output is a single stream, read as stdout.
+		{"tty", []byte("one\r\ntwo\n"), true, []want{{Stdout, "one"}, {Stdout, "two"}}},
+	}
+	for _, tt := range tests {
+		got := collectLogLines(t, bytes.NewReader(tt.raw), tt.tty, false)
+		var lines []want
+		for _, l := range got {
+			lines = append(lines, want{l.Stream, l.Text})
+		}
+		if len(lines) != len(tt.want) {
+			t.Errorf("%s: lines = %v, want %v", tt.name, lines, tt.want)
+			continue
+		}
+		for i := range lines {
+			if lines[i] != tt.want[i] {
+				t.Errorf("%s: lines = %v, want %v", tt.name, lines, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCopyLogLinesTimestamps(t *testing.T) {
+	stamp := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		text       string
+		timestamps bool
+		time       time.Time
+		want       string
+	}{
+		{"2024-05-01T10:00:00.123456789Z hello world", true, stamp, "hello world"},
+		{"2024-05-01T12:00:00.123456789+02:00 offset", true, stamp, "offset"},
+		// Lines whose first word is not a timestamp are kept whole.
+		{"not-a-time hello", true, time.Time{}, "not-a-time hello"},
+		{"nospace", true, time.Time{}, "nospace"},
+		{"2024-05-01T10:00:00.123456789Z untouched", false, time.Time{}, "2024-05-01T10:00:00.123456789Z untouched"},
+	}
+	for _, tt := range tests {
+		lines := collectLogLines(t, strings.NewReader(tt.text+"\n"), true, tt.timestamps)
+		if len(lines) != 1 {
+			t.Errorf("%q: %d lines", tt.text, len(lines))
+			continue
+		}
+		if !lines[0].Time.Equal(tt.time) || lines[0].Text != tt.want {
+			t.Errorf("%q = %v %q, want %v %q", tt.text, lines[0].Time, lines[0].Text, tt.time, tt.want)
+		}
+	}
+
+	// The JSON fields are parsed from the text after the timestamp.
+	lines := collectLogLines(t, strings.NewReader(`2024-05-01T10:00:00Z {"level":"warn"}`+"\n"), true, true)
+	if level, ok := lines[0].Field("level"); !ok || level != "warn" {
+		t.Errorf("level = %q, %v", level, ok)
+	}
+}
+
+func TestCopyLogLinesSplitsLongLines(t *testing.T) {
+	long := strings.Repeat("x", 2*maxLogLineLength+10)
+	tests := []struct {
+		name string
+		raw  []byte
+		tty  bool
+	}{
+		{"one write", []byte(long + "\nnext\n"), true},
+		{"frames", muxLog(t, Stdout, long[:1000], Stdout, long[1000:]+"\nnext\n"), false},
+	}
+	for _, tt := range tests {
+		lines := collectLogLines(t, bytes.NewReader(tt.raw), tt.tty, false)
+		var lengths []int
+		for _, l := range lines {
+			lengths = append(lengths, len(l.Text))
+		}
+		if len(lengths) != 4 || lengths[0] != maxLogLineLength || lengths[1] != maxLogLineLength || lengths[2] != 10 || lines[3].Text != "next" {
+			t.Errorf("%s: line lengths = %v, want %d, %d, 10, 4", tt.name, lengths, maxLogLineLength, maxLogLineLength)
+		}
+	}
+}
+
+// failingReader returns data, then err.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestCopyLogLinesErrors(t *testing.T) {
+	var lines []LogLine
+	handle := func(l LogLine) { lines = append(lines, l) }
+
+	// Lines read before an error are still handed on.
+	broken := errors.New("connection reset")
+	err := copyLogLines(context.Background(), &failingReader{data: []byte("partial"), err: broken}, true, false, handle)
+	if !errors.Is(err, broken) || len(lines) != 1 || lines[0].Text != "partial" {
+		t.Errorf("read error: %v, lines %v", err, lines)
+	}
+
+	// A cancelled stream reports the cancellation, not the read error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = copyLogLines(ctx, &failingReader{err: errors.New("use of closed connection")}, true, false, handle)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: %v", err)
+	}
+
+	// A corrupt multiplexed stream is an error.
+	err = copyLogLines(context.Background(), bytes.NewReader([]byte{9, 0, 0, 0, 0, 0, 0, 1, 'x'}), false, false, handle)
+	if err == nil {
+		t.Error("a bad stream header is accepted")
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	b := NewLogBuffer(3)
+	for _, text := range []string{"1", "2"} {
+		b.Append(LogLine{Text: text})
+	}
+	if got := logTexts(b.Lines()); got != "1 2" || b.Len() != 2 || b.Dropped() != 0 {
+		t.Errorf("partly full: %q, len %d, dropped %d", got, b.Len(), b.Dropped())
+	}
+	// Once full, the oldest lines make room.
+	for _, text := range []string{"3", "4", "5"} {
+		b.Append(LogLine{Text: text})
+	}
+	if got := logTexts(b.Lines()); got != "3 4 5" || b.Len() != 3 || b.Dropped() != 2 {
+		t.Errorf("full: %q, len %d, dropped %d", got, b.Len(), b.Dropped())
+	}
+	b.Reset()
+	if b.Len() != 0 || b.Dropped() != 0 || len(b.Lines()) != 0 {
+		t.Errorf("after reset: len %d, dropped %d", b.Len(), b.Dropped())
+	}
+	b.Append(LogLine{Text: "6"})
+	if got := logTexts(b.Lines()); got != "6" {
+		t.Errorf("after reset and append: %q", got)
+	}
+
+	empty := NewLogBuffer(0)
+	empty.Append(LogLine{Text: "x"})
+	if empty.Len() != 0 {
+		t.Error("a zero-size buffer holds lines")
+	}
+}
+
+func logTexts(lines []LogLine) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return strings.Join(texts, " ")
+}
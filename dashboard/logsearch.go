@@ -0,0 +1,107 @@
+package dashboard
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// LogMatcher finds a search term in log lines.
+type LogMatcher struct {
+	re *regexp.Regexp
+}
+
+// NewLogMatcher compiles a search. Plain queries match literally; with regex
+// set the query is a Go regular expression. An empty query returns nil, which
+// matches nothing.
+func NewLogMatcher(query string, regex, caseSensitive bool) (*LogMatcher, error) {
+	if query == "" {
+		return nil, nil
+	}
+	if !regex {
+		query = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		query = "(?i)" + query
+	}
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search: %w", err)
+	}
+	return &LogMatcher{re: re}, nil
+}
+
+// Match reports whether text contains the search term. A nil matcher
+// matches nothing.
+func (m *LogMatcher) Match(text string) bool {
+	return m != nil && m.re.MatchString(text)
+}
+
+// FindAll returns the byte ranges of every non-empty match in text.
+func (m *LogMatcher) FindAll(text string) [][]int {
+	if m == nil {
+		return nil
+	}
+	var result [][]int
+	for _, loc := range m.re.FindAllStringIndex(text, -1) {
+		if loc[1] > loc[0] {
+			result = append(result, loc)
+		}
+	}
+	return result
+}
+
+// Filter returns the indexes of the lines that match.
+func (m *LogMatcher) Filter(lines []LogLine) []int {
+	var result []int
+	for i, line := range lines {
+		if m.Match(line.Text) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// HighlightRule colours the parts of log lines that match Pattern.
+type HighlightRule struct {
+	Pattern       string `json:"pattern"`
+	Regex         bool   `json:"regex"`
+	CaseSensitive bool   `json:"caseSensitive"`
+	// Color is a colour name understood by the front-end, e.g. "red".
+	Color string `json:"color"`
+}
+
+// DefaultHighlightRules mark common log levels.
+func DefaultHighlightRules() []HighlightRule {
+	return []HighlightRule{
+		{Pattern: `\b(ERROR|FATAL|PANIC)\b`, Regex: true, CaseSensitive: true, Color: "red"},
+		{Pattern: `\bWARN(ING)?\b`, Regex: true, CaseSensitive: true, Color: "yellow"},
+	}
+}
+
+// CompiledHighlightRule is a HighlightRule ready for matching.
+type CompiledHighlightRule struct {
+	HighlightRule
+	matcher *LogMatcher
+}
+
+// FindAll returns the byte ranges the rule colours in text.
+func (r CompiledHighlightRule) FindAll(text string) [][]int {
+	return r.matcher.FindAll(text)
+}
+
+// CompileHighlightRules compiles rules in order, skipping empty patterns.
+// It fails on the first invalid pattern.
+func CompileHighlightRules(rules []HighlightRule) ([]CompiledHighlightRule, error) {
+	var result []CompiledHighlightRule
+	for _, rule := range rules {
+		m, err := NewLogMatcher(rule.Pattern, rule.Regex, rule.CaseSensitive)
+		if err != nil {
+			return nil, fmt.Errorf("highlight rule %q: %w", rule.Pattern, err)
+		}
+		if m == nil {
+			continue
+		}
+		result = append(result, CompiledHighlightRule{HighlightRule: rule, matcher: m})
+	}
+	return result, nil
+}
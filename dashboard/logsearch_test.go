@@ -0,0 +1,97 @@
+package dashboard
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLogMatcher(t *testing.T) {
+	tests := []struct {
+		query                string
+		regex, caseSensitive bool
+		text                 string
+		want                 [][]int
+	}{
+		{"err", false, false, "Error: err", [][]int{{0, 3}, {7, 10}}},
+		{"err", false, true, "Error: err", [][]int{{7, 10}}},
+		// Plain queries match regexp characters literally.
+		{"a.b", false, false, "axb a.b", [][]int{{4, 7}}},
+		{"[1]", false, false, "id[1]", [][]int{{2, 5}}},
+		{`\d+`, true, false, "took 12ms, 3 tries", [][]int{{5, 7}, {11, 12}}},
+		{`(?-i)WARN`, true, false, "warn WARN", [][]int{{5, 9}}},
+		// Empty matches are not ranges anyone can see.
+		{`x*`, true, false, "abc", nil},
+		{"nope", false, false, "abc", nil},
+	}
+	for _, tt := range tests {
+		m, err := NewLogMatcher(tt.query, tt.regex, tt.caseSensitive)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		got := m.FindAll(tt.text)
+		if !slices.EqualFunc(got, tt.want, slices.Equal[[]int]) {
+			t.Errorf("%q in %q = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLogMatcherEmptyAndInvalid(t *testing.T) {
+	m, err := NewLogMatcher("", true, false)
+	if m != nil || err != nil {
+		t.Fatalf("empty query = %v, %v", m, err)
+	}
+	// A nil matcher matches nothing.
+	if m.Match("anything") || m.FindAll("anything") != nil || m.Filter([]LogLine{{Text: "anything"}}) != nil {
+		t.Error("nil matcher matches")
+	}
+	if _, err := NewLogMatcher("(", true, false); err == nil {
+		t.Error("an invalid regexp is accepted")
+	}
+	if _, err := NewLogMatcher("(", false, false); err != nil {
+		t.Errorf("plain ( rejected: %v", err)
+	}
+}
+
+func TestLogMatcherFilter(t *testing.T) {
+	m, err := NewLogMatcher("timeout", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []LogLine{{Text: "start"}, {Text: "Timeout after 5s"}, {Text: "retry"}, {Text: "timeout again"}}
+	if got := m.Filter(lines); !slices.Equal(got, []int{1, 3}) {
+		t.Errorf("Filter = %v, want [1 3]", got)
+	}
+}
+
+func TestCompileHighlightRules(t *testing.T) {
+	rules, err := CompileHighlightRules(append(DefaultHighlightRules(),
+		HighlightRule{Pattern: "", Color: "blue"},
+		HighlightRule{Pattern: "db", Color: "green"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("%d rules, want the empty one skipped", len(rules))
+	}
+	tests := []struct {
+		rule int
+		text string
+		want [][]int
+	}{
+		{0, "ERROR: disk full", [][]int{{0, 5}}},
+		{0, "error: disk full", nil},
+		{0, "NOERRORS", nil},
+		{1, "WARNING and WARN", [][]int{{0, 7}, {12, 16}}},
+		{2, "DB down", [][]int{{0, 2}}},
+	}
+	for _, tt := range tests {
+		if got := rules[tt.rule].FindAll(tt.text); !slices.EqualFunc(got, tt.want, slices.Equal[[]int]) {
+			t.Errorf("rule %q in %q = %v, want %v", rules[tt.rule].Pattern, tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := CompileHighlightRules([]HighlightRule{{Pattern: "ok"}, {Pattern: "[", Regex: true}}); err == nil {
+		t.Error("an invalid rule is accepted")
+	}
+}
@@ -0,0 +1,182 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	dockerImage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/opencontainers/go-digest"
+)
+
+// PullOptions are the optional settings of an image pull.
+type PullOptions struct {
+	// Platform picks the variant of a multi-platform image, e.g.
+	// "linux/arm64". Empty lets the daemon pick its own platform.
+	Platform string
+	// Digest pins the pull to one manifest, e.g. "sha256:...". It takes
+	// the place of the reference's tag.
+	Digest string
+}
+
+// PullReference returns the reference pulled for ref with opts: the tag
+// defaults to "latest" and a digest replaces the tag.
+func PullReference(ref string, opts PullOptions) (string, error) {
+	named, err := reference.ParseNormalizedNamed(strings.TrimSpace(ref))
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	if opts.Digest == "" {
+		return reference.FamiliarString(reference.TagNameOnly(named)), nil
+	}
+	d, err := digest.Parse(strings.TrimSpace(opts.Digest))
+	if err != nil {
+		return "", fmt.Errorf("invalid digest %q: %w", opts.Digest, err)
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), d)
+	if err != nil {
+		return "", err
+	}
+	return reference.FamiliarString(pinned), nil
+}
+
+// LayerProgress is the state of one layer of a pull.
+type LayerProgress struct {
+	ID     string
+	Status string // e.g. "Downloading", "Extracting" or "Pull complete"
+	// Current and Total are the progress of the running step, download or
+	// extraction, in bytes. Total is 0 when unknown.
+	Current, Total int64
+	// Size is the download size once the download has started, and
+	// Downloaded how much of it has arrived.
+	Size, Downloaded int64
+	// Done is set once the layer is complete or already present.
+	Done bool
+}
+
+// PullProgress folds the progress messages of a pull into per-layer state.
+type PullProgress struct {
+	Layers []*LayerProgress // in order of first mention
+	Status string           // latest message not about a layer
+	Digest string           // digest of the pulled manifest, once known
+	Start  time.Time
+
+	layers map[string]*LayerProgress
+}
+
+func NewPullProgress(start time.Time) *PullProgress {
+	return &PullProgress{Start: start, layers: map[string]*LayerProgress{}}
+}
+
+// Update applies one progress message. A message carrying an error, such
+// as a missing tag or a failed download, is returned as the error.
+func (p *PullProgress) Update(msg jsonmessage.JSONMessage) error {
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if msg.ErrorMessage != "" {
+		return errors.New(msg.ErrorMessage)
+	}
+	// "Pulling from library/alpine" names the tag in place of a layer.
+	if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from ") {
+		if d, ok := strings.CutPrefix(msg.Status, "Digest: "); ok {
+			p.Digest = d
+		}
+		p.Status = strings.TrimSpace(msg.ID + " " + msg.Status)
+		return nil
+	}
+
+	layer := p.layers[msg.ID]
+	if layer == nil {
+		layer = &LayerProgress{ID: msg.ID}
+		p.layers[msg.ID] = layer
+		p.Layers = append(p.Layers, layer)
+	}
+	layer.Status = msg.Status
+	layer.Current, layer.Total = 0, 0
+	if msg.Progress != nil {
+		layer.Current, layer.Total = msg.Progress.Current, msg.Progress.Total
+	}
+	switch msg.Status {
+	case "Downloading":
+		layer.Size, layer.Downloaded = layer.Total, layer.Current
+	case "Verifying Checksum", "Download complete", "Extracting":
+		layer.Downloaded = layer.Size
+	case "Pull complete":
+		layer.Downloaded, layer.Done = layer.Size, true
+	case "Already exists":
+		layer.Done = true
+	}
+	return nil
+}
+
+// Bytes returns how much has been downloaded out of the sizes known so far.
+// known is false while layers are waiting to start and so have no size.
+func (p *PullProgress) Bytes() (downloaded, total int64, known bool) {
+	known = true
+	for _, l := range p.Layers {
+		downloaded += l.Downloaded
+		total += l.Size
+		if l.Size == 0 && !l.Done {
+			known = false
+		}
+	}
+	return downloaded, total, known
+}
+
+// ETA estimates the time left from the average rate since Start. It
+// reports false until the rate and the total are known.
+func (p *PullProgress) ETA(now time.Time) (time.Duration, bool) {
+	downloaded, total, known := p.Bytes()
+	elapsed := now.Sub(p.Start)
+	if !known || downloaded == 0 || elapsed <= 0 {
+		return 0, false
+	}
+	rate := float64(downloaded) / elapsed.Seconds()
+	return time.Duration(float64(total-downloaded) / rate * float64(time.Second)), true
+}
+
+// PullImageWithProgress pulls ref with opts, calling handle, if not nil,
+// after every progress message. The pull stops when ctx is cancelled. It
+// returns the final progress, which holds the pulled digest.
+func (s *Service) PullImageWithProgress(ctx context.Context, ref string, opts PullOptions, handle func(*PullProgress)) (*PullProgress, error) {
+	ref, err := PullReference(ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.encodedAuth(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	progress := NewPullProgress(time.Now())
+	rc, err := s.cli.ImagePull(ctx, ref, dockerImage.PullOptions{Platform: opts.Platform, RegistryAuth: auth})
+	if err != nil {
+		return progress, fmt.Errorf("pull image %s: %w", ref, err)
+	}
+	defer rc.Close()
+	// The pull only runs as long as someone reads the progress stream.
+	dec := json.NewDecoder(rc)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			return progress, nil
+		} else if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return progress, fmt.Errorf("pull image %s: %w", ref, err)
+		}
+		if err := progress.Update(msg); err != nil {
+			return progress, fmt.Errorf("pull image %s: %w", ref, err)
+		}
+		if handle != nil {
+			handle(progress)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	dockerImage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+func TestPullReference(t *testing.T) {
+	const sum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		ref    string
+		digest string
+		want   string
+		err    bool
+	}{
+		{ref: "nginx", want: "nginx:latest"},
+		{ref: " nginx:1.27 ", want: "nginx:1.27"},
+		{ref: "docker.io/library/nginx", want: "nginx:latest"},
+		{ref: "ghcr.io/org/app", want: "ghcr.io/org/app:latest"},
+		{ref: "localhost:5000/app:dev", want: "localhost:5000/app:dev"},
+		{ref: "nginx@" + sum, want: "nginx@" + sum},
+		// A digest replaces the tag.
+		{ref: "nginx:1.27", digest: sum, want: "nginx@" + sum},
+		{ref: "ghcr.io/org/app", digest: " " + sum + " ", want: "ghcr.io/org/app@" + sum},
+		{ref: "nginx", digest: "sha256:short", err: true},
+		{ref: "nginx", digest: "latest", err: true},
+		{ref: "Nginx", err: true},
+		{ref: "", err: true},
+		{ref: "nginx:bad tag", err: true},
+	}
+	for _, tt := range tests {
+		got, err := PullReference(tt.ref, PullOptions{Digest: tt.digest})
+		if (err != nil) != tt.err {
+			t.Errorf("%q, digest %q: err = %v, want error %v", tt.ref, tt.digest, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q, digest %q = %q, want %q", tt.ref, tt.digest, got, tt.want)
+		}
+	}
+}
+
+// progressMsg builds a layer progress message.
+func progressMsg(id, status string, current, total int64) jsonmessage.JSONMessage {
+	msg := jsonmessage.JSONMessage{ID: id, Status: status}
+	if total > 0 || current > 0 {
+		msg.Progress = &jsonmessage.JSONProgress{Current: current, Total: total}
+	}
+	return msg
+}
+
+func TestPullProgressUpdate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	p := NewPullProgress(start)
+	steps := []jsonmessage.JSONMessage{
+		{ID: "1.27", Status: "Pulling from library/nginx"},
+		progressMsg("aaa", "Pulling fs layer", 0, 0),
+		progressMsg("bbb", "Already exists", 0, 0),
+		progressMsg("ccc", "Pulling fs layer", 0, 0),
+		progressMsg("aaa", "Downloading", 100, 1000),
+	}
+	for _, msg := range steps {
+		if err := p.Update(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.Status != "1.27 Pulling from library/nginx" {
+		t.Errorf("status = %q", p.Status)
+	}
+	if len(p.Layers) != 3 || p.Layers[0].ID != "aaa" || p.Layers[1].ID != "bbb" || p.Layers[2].ID != "ccc" {
+		t.Fatalf("layers = %v, want aaa, bbb, ccc in order", p.Layers)
+	}
+	if l := p.Layers[0]; l.Current != 100 || l.Total != 1000 || l.Size != 1000 || l.Downloaded != 100 || l.Done {
+		t.Errorf("downloading layer = %+v", *l)
+	}
+	if !p.Layers[1].Done {
+		t.Error("an existing layer is not done")
+	}
+	// ccc has not started, so the total is not known yet.
+	if down, total, known := p.Bytes(); down != 100 || total != 1000 || known {
+		t.Errorf("bytes = %d/%d, known %v; want 100/1000, false", down, total, known)
+	}
+	if _, ok := p.ETA(start.Add(time.Second)); ok {
+		t.Error("ETA known before every layer has a size")
+	}
+
+	for _, msg := range []jsonmessage.JSONMessage{
+		progressMsg("ccc", "Downloading", 0, 3000),
+		progressMsg("aaa", "Download complete", 0, 0),
+		progressMsg("aaa", "Extracting", 500, 1000),
+	} {
+		if err := p.Update(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Extraction progress is the step's, not the download's.
+	if l := p.Layers[0]; l.Current != 500 || l.Downloaded != 1000 || l.Size != 1000 {
+		t.Errorf("extracting layer = %+v", *l)
+	}
+	down, total, known := p.Bytes()
+	if down != 1000 || total != 4000 || !known {
+		t.Errorf("bytes = %d/%d, known %v; want 1000/4000, true", down, total, known)
+	}
+	// 1000 bytes in 2s leaves 3000 bytes, 6s at that rate.
+	if eta, ok := p.ETA(start.Add(2 * time.Second)); !ok || eta != 6*time.Second {
+		t.Errorf("ETA = %v, %v; want 6s", eta, ok)
+	}
+	if _, ok := p.ETA(start); ok {
+		t.Error("ETA known with no time elapsed")
+	}
+
+	for _, msg := range []jsonmessage.JSONMessage{
+		progressMsg("aaa", "Pull complete", 0, 0),
+		progressMsg("ccc", "Verifying Checksum", 0, 0),
+		progressMsg("ccc", "Pull complete", 0, 0),
+		{Status: "Digest: sha256:feed"},
+		{Status: "Status: Downloaded newer image for nginx:1.27"},
+	} {
+		if err := p.Update(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.Digest != "sha256:feed" || p.Status != "Status: Downloaded newer image for nginx:1.27" {
+		t.Errorf("digest %q, status %q", p.Digest, p.Status)
+	}
+	if down, total, known := p.Bytes(); down != 4000 || total != 4000 || !known {
+		t.Errorf("bytes = %d/%d, known %v; want 4000/4000, true", down, total, known)
+	}
+	if eta, ok := p.ETA(start.Add(4 * time.Second)); !ok || eta != 0 {
+		t.Errorf("ETA when done = %v, %v", eta, ok)
+	}
+}
+
+func TestPullProgressErrors(t *testing.T) {
+	p := NewPullProgress(time.Now())
+	if err := p.Update(jsonmessage.JSONMessage{Error: &jsonmessage.JSONError{Message: "manifest unknown"}}); err == nil || err.Error() != "manifest unknown" {
+		t.Errorf("error = %v", err)
+	}
+	if err := p.Update(jsonmessage.JSONMessage{ErrorMessage: "toomanyrequests"}); err == nil || err.Error() != "toomanyrequests" {
+		t.Errorf("error message = %v", err)
+	}
+	// With nothing downloaded there is no rate to estimate from.
+	if _, ok := p.ETA(time.Now().Add(time.Second)); ok {
+		t.Error("ETA known without a download")
+	}
+}
+
+// pullClient answers image pulls with a fixed progress stream.
+type pullClient struct {
+	fakeClient
+	stream string
+	pulled string
+}
+
+func (c *pullClient) ImagePull(_ context.Context, ref string, _ dockerImage.PullOptions) (io.ReadCloser, error) {
+	c.pulled = ref
+	return io.NopCloser(strings.NewReader(c.stream)), nil
+}
+
+func TestPullImageWithProgress(t *testing.T) {
+	cli := &pullClient{stream: `{"status":"Pulling from library/alpine","id":"latest"}
+{"status":"Downloading","id":"aaa","progressDetail":{"current":5,"total":10}}
+{"status":"Pull complete","id":"aaa"}
+{"status":"Digest: sha256:feed"}
+`}
+	svc := NewService(cli)
+	calls := 0
+	progress, err := svc.PullImageWithProgress(context.Background(), "alpine", PullOptions{}, func(*PullProgress) { calls++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.pulled != "alpine:latest" || calls != 4 || progress.Digest != "sha256:feed" {
+		t.Errorf("pulled %q, %d updates, digest %q", cli.pulled, calls, progress.Digest)
+	}
+
+	cli.stream = `{"status":"Pulling from library/alpine","id":"nope"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`
+	if _, err := svc.PullImageWithProgress(context.Background(), "alpine:nope", PullOptions{}, nil); err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("failed pull: %v", err)
+	}
+}
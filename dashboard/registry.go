@@ -0,0 +1,719 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	dockerImage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// DockerHubRegistry is the address Docker files Docker Hub credentials
+// under.
+const DockerHubRegistry = "https://index.docker.io/v1/"
+
+// credentialHelperTimeout bounds a call to a docker-credential-* helper,
+// which may wait on a keychain prompt.
+const credentialHelperTimeout = 30 * time.Second
+
+// RegistryCredential is a login to one registry.
+type RegistryCredential struct {
+	// Registry is the registry host, e.g. "ghcr.io"; "docker.io" for
+	// Docker Hub.
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+	// Password is a password or an access token.
+	Password string `json:"password,omitempty"`
+	// IdentityToken replaces the password for registries using OAuth.
+	IdentityToken string `json:"identitytoken,omitempty"`
+	// Source tells where the credential comes from, e.g. "dashboard" or
+	// "config.json". It is not stored.
+	Source string `json:"-"`
+}
+
+// authConfig converts the credential for the API.
+func (c RegistryCredential) authConfig() registry.AuthConfig {
+	server := c.Registry
+	if server == "docker.io" {
+		server = DockerHubRegistry
+	}
+	return registry.AuthConfig{
+		Username:      c.Username,
+		Password:      c.Password,
+		IdentityToken: c.IdentityToken,
+		ServerAddress: server,
+	}
+}
+
+// RegistryHost normalises a registry address as written in config files or
+// login forms: the scheme and path are dropped and the Docker Hub aliases
+// become "docker.io".
+func RegistryHost(addr string) string {
+	host := strings.TrimSpace(addr)
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host, _, _ = strings.Cut(host, "/")
+	host = strings.ToLower(host)
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com", "":
+		return "docker.io"
+	}
+	return host
+}
+
+// ReferenceRegistry returns the registry host an image reference or search
+// term points at, e.g. "ghcr.io" for "ghcr.io/org/app:1.0" and "docker.io"
+// for "nginx".
+func ReferenceRegistry(ref string) string {
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		return RegistryHost(reference.Domain(named))
+	}
+	// Search terms need not be valid references; a first part with a dot
+	// or a port names a registry, as in the CLI.
+	if first, _, ok := strings.Cut(ref, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return RegistryHost(first)
+	}
+	return "docker.io"
+}
+
+// =============================================================================
+// Encrypted credential store
+// =============================================================================
+
+// ErrCredentialStoreUnreadable reports a credential store that exists but
+// cannot be decrypted, e.g. because its key was lost from the keyring.
+// Reset starts over with an empty store.
+var ErrCredentialStoreUnreadable = errors.New("credential store is unreadable")
+
+// CredentialStore keeps the credentials entered in the dashboard in a file
+// encrypted with AES-GCM. The key is generated on first save and kept in
+// the OS keyring (the macOS keychain, Windows Credential Manager or the
+// Secret Service on Linux), so a copy of the file alone, e.g. in a backup,
+// does not give the credentials away. Programs running as the same user
+// can still ask the keyring for the key.
+//
+// Linux hosts without the Secret Service helper or a D-Bus session keep the
+// key in a file next to the store instead. That is the weaker mode: the
+// file is readable only by the user, but a copy of the directory holds
+// both the credentials and their key.
+type CredentialStore struct {
+	path string
+	keys keyring
+	mu   sync.Mutex
+}
+
+// NewCredentialStore returns the store kept in dir as registries.enc, with
+// its key in the platform's keyring or, failing that on Linux, in
+// registries.key. The directory is created on first save.
+func NewCredentialStore(dir string) *CredentialStore {
+	path := filepath.Join(dir, "registries.enc")
+	return &CredentialStore{path: path, keys: defaultKeyring(path)}
+}
+
+// KeyLocation describes where the store's key is kept, for display.
+func (s *CredentialStore) KeyLocation() string {
+	return s.keys.String()
+}
+
+// Load returns the stored credentials, none when nothing was saved yet.
+func (s *CredentialStore) Load() ([]RegistryCredential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+func (s *CredentialStore) load() ([]RegistryCredential, error) {
+	sealed, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read credential store: %w", err)
+	}
+	key, ok, err := s.keys.get()
+	if err != nil {
+		return nil, fmt.Errorf("read credential store key: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: its key is missing from %s", ErrCredentialStoreUnreadable, s.keys)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCredentialStoreUnreadable, err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: the file is truncated", ErrCredentialStoreUnreadable)
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decrypt: %w", ErrCredentialStoreUnreadable, err)
+	}
+	var creds []RegistryCredential
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("%w: decode: %w", ErrCredentialStoreUnreadable, err)
+	}
+	for i := range creds {
+		creds[i].Source = "dashboard"
+	}
+	return creds, nil
+}
+
+// Put adds cred, replacing any credential for the same registry.
+func (s *CredentialStore) Put(cred RegistryCredential) error {
+	cred.Registry = RegistryHost(cred.Registry)
+	return s.update(func(creds []RegistryCredential) []RegistryCredential {
+		if i := slices.IndexFunc(creds, func(c RegistryCredential) bool { return c.Registry == cred.Registry }); i >= 0 {
+			creds[i] = cred
+			return creds
+		}
+		return append(creds, cred)
+	})
+}
+
+// Delete removes the credential for registry, if any.
+func (s *CredentialStore) Delete(registry string) error {
+	host := RegistryHost(registry)
+	return s.update(func(creds []RegistryCredential) []RegistryCredential {
+		return slices.DeleteFunc(creds, func(c RegistryCredential) bool { return c.Registry == host })
+	})
+}
+
+// Lookup returns the stored credential for a registry host.
+func (s *CredentialStore) Lookup(host string) (RegistryCredential, bool, error) {
+	creds, err := s.Load()
+	if err != nil {
+		return RegistryCredential{}, false, err
+	}
+	for _, c := range creds {
+		if c.Registry == host {
+			return c, true, nil
+		}
+	}
+	return RegistryCredential{}, false, nil
+}
+
+// Reset deletes the stored credentials, so that a store which became
+// unreadable can be used again. The next save generates a new key if the
+// old one is gone.
+func (s *CredentialStore) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reset credential store: %w", err)
+	}
+	return nil
+}
+
+func (s *CredentialStore) update(change func([]RegistryCredential) []RegistryCredential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, err := s.load()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(change(creds))
+	if err != nil {
+		return err
+	}
+	key, err := s.key()
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, gcm.Seal(nonce, nonce, plain, nil))
+}
+
+// key reads the store's key from the keyring, generating it on first use.
+// Call it with mu held, after load has succeeded, so that a key missing
+// from the keyring is only replaced while there is no store to lose.
+func (s *CredentialStore) key() ([]byte, error) {
+	key, ok, err := s.keys.get()
+	if err != nil {
+		return nil, fmt.Errorf("read credential store key: %w", err)
+	}
+	if ok {
+		return key, nil
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := s.keys.set(key); err != nil {
+		return nil, fmt.Errorf("save credential store key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("credential store key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic replaces path with data, readable only by the user.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// keyring holds the key of a CredentialStore. String describes where.
+type keyring interface {
+	// get returns the key, and false when none was saved yet.
+	get() ([]byte, bool, error)
+	set(key []byte) error
+	String() string
+}
+
+// defaultKeyring picks the keyring for the store at storePath: the OS
+// keyring, except on Linux hosts that lack the Secret Service helper or a
+// D-Bus session to reach it through, which get a key file. A store keeps
+// the mode it was created with, so a helper removed later is reported
+// rather than the store being taken for a new one.
+func defaultKeyring(storePath string) keyring {
+	helper := helperKeyring{helper: nativeCredentialHelper()}
+	if runtime.GOOS != "linux" {
+		return helper
+	}
+	file := fileKeyring{path: strings.TrimSuffix(storePath, filepath.Ext(storePath)) + ".key"}
+	if _, err := os.Stat(file.path); err == nil {
+		return file
+	}
+	if _, err := os.Stat(storePath); err == nil {
+		return helper
+	}
+	if _, err := exec.LookPath("docker-credential-" + helper.helper); err != nil || !hasSessionBus() {
+		return file
+	}
+	return helper
+}
+
+// hasSessionBus reports whether a D-Bus session bus is likely reachable:
+// announced in the environment, or at its default socket.
+func hasSessionBus() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		return true
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, "bus"))
+	return err == nil
+}
+
+// keyringServer and keyringUser name the keyring entry holding the
+// credential store key.
+const (
+	keyringServer = "sprint://registries"
+	keyringUser   = "sprint"
+)
+
+// nativeCredentialHelper returns the docker-credential helper for the
+// platform's keyring.
+func nativeCredentialHelper() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osxkeychain"
+	case "windows":
+		return "wincred"
+	}
+	return "secretservice"
+}
+
+// helperKeyring keeps the key in the OS keyring through a
+// docker-credential helper, the same programs the Docker CLI uses, so no
+// keyring library is needed.
+type helperKeyring struct {
+	helper string
+}
+
+func (k helperKeyring) get() ([]byte, bool, error) {
+	cred, ok, err := credentialHelperGet(context.Background(), k.helper, keyringServer, "")
+	if err != nil || !ok {
+		return nil, false, k.wrap(err)
+	}
+	key, err := base64.StdEncoding.DecodeString(cred.Password)
+	if err != nil {
+		return nil, false, fmt.Errorf("keyring entry %s: %w", keyringServer, err)
+	}
+	return key, true, nil
+}
+
+func (k helperKeyring) set(key []byte) error {
+	return k.wrap(credentialHelperStore(context.Background(), k.helper, keyringServer, keyringUser, base64.StdEncoding.EncodeToString(key)))
+}
+
+func (k helperKeyring) String() string {
+	return "the OS keyring (docker-credential-" + k.helper + ")"
+}
+
+// wrap points at the missing helper, the usual reason the keyring cannot
+// be reached.
+func (k helperKeyring) wrap(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("no keyring: install docker-credential-%s: %w", k.helper, err)
+	}
+	return err
+}
+
+// fileKeyring keeps the key in a file readable only by the user. It is the
+// fallback for hosts without a usable OS keyring and protects less: anyone
+// who can read the store's directory can read the key too.
+type fileKeyring struct {
+	path string
+}
+
+func (k fileKeyring) get() ([]byte, bool, error) {
+	key, err := os.ReadFile(k.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	return key, err == nil, err
+}
+
+func (k fileKeyring) set(key []byte) error {
+	return writeFileAtomic(k.path, key)
+}
+
+func (k fileKeyring) String() string {
+	return "the key file " + k.path
+}
+
+// =============================================================================
+// Docker CLI configuration
+// =============================================================================
+
+// DockerConfig is the part of the Docker CLI's config.json that holds
+// registry credentials.
+type DockerConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	// CredsStore is the helper holding the credentials of every registry
+	// without an entry in CredHelpers, e.g. "desktop" or "osxkeychain".
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// DockerConfigPath returns where the Docker CLI keeps config.json:
+// $DOCKER_CONFIG, or ~/.docker.
+func DockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfig reads a config.json. A missing file is an empty config.
+func LoadDockerConfig(path string) (*DockerConfig, error) {
+	cfg := &DockerConfig{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) || path == "" {
+		return cfg, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Entries lists the registries the config has credentials for, without
+// asking credential helpers for the secrets.
+func (c *DockerConfig) Entries() []RegistryCredential {
+	var result []RegistryCredential
+	seen := map[string]bool{}
+	for server, helper := range c.CredHelpers {
+		host := RegistryHost(server)
+		seen[host] = true
+		result = append(result, RegistryCredential{Registry: host, Source: "helper " + helper})
+	}
+	for server, entry := range c.Auths {
+		host := RegistryHost(server)
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		cred := RegistryCredential{Registry: host, Source: "config.json"}
+		switch {
+		case entry.Auth != "":
+			cred.Username, _, _ = decodeBasicAuth(entry.Auth)
+		case entry.IdentityToken == "" && c.CredsStore != "":
+			// An empty entry marks a login kept by the credential store.
+			cred.Source = "helper " + c.CredsStore
+		}
+		result = append(result, cred)
+	}
+	slices.SortFunc(result, func(a, b RegistryCredential) int { return strings.Compare(a.Registry, b.Registry) })
+	return result
+}
+
+// Lookup returns the credential for a registry host the way the Docker CLI
+// resolves it: a registry's own helper, then an inline auth entry, then
+// the default credential store.
+func (c *DockerConfig) Lookup(ctx context.Context, host string) (RegistryCredential, bool, error) {
+	for server, helper := range c.CredHelpers {
+		if RegistryHost(server) == host {
+			return credentialHelperGet(ctx, helper, server, host)
+		}
+	}
+	server := host
+	if host == "docker.io" {
+		server = DockerHubRegistry
+	}
+	for key, entry := range c.Auths {
+		if RegistryHost(key) != host {
+			continue
+		}
+		server = key
+		if entry.Auth == "" && entry.IdentityToken == "" {
+			continue
+		}
+		cred := RegistryCredential{Registry: host, IdentityToken: entry.IdentityToken, Source: "config.json"}
+		if entry.Auth != "" {
+			var err error
+			if cred.Username, cred.Password, err = decodeBasicAuth(entry.Auth); err != nil {
+				return RegistryCredential{}, false, fmt.Errorf("config.json auth for %s: %w", host, err)
+			}
+		}
+		return cred, true, nil
+	}
+	if c.CredsStore != "" {
+		return credentialHelperGet(ctx, c.CredsStore, server, host)
+	}
+	return RegistryCredential{}, false, nil
+}
+
+// decodeBasicAuth splits a config.json "auth" value, base64 of
+// "user:password".
+func decodeBasicAuth(auth string) (user, password string, err error) {
+	raw, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return "", "", err
+	}
+	user, password, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return "", "", errors.New("auth is not user:password")
+	}
+	return user, password, nil
+}
+
+// credentialHelperGet asks docker-credential-<helper> for the credential
+// filed under server, as the Docker CLI does.
+func credentialHelperGet(ctx context.Context, helper, server, host string) (RegistryCredential, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	out, err := cmd.Output()
+	if err != nil {
+		msg := string(out)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			msg += string(exitErr.Stderr)
+		}
+		if strings.Contains(msg, "credentials not found") {
+			return RegistryCredential{}, false, nil
+		}
+		return RegistryCredential{}, false, fmt.Errorf("credential helper %s: %w %s", helper, err, strings.TrimSpace(msg))
+	}
+	var resp struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return RegistryCredential{}, false, fmt.Errorf("credential helper %s: %w", helper, err)
+	}
+	cred := RegistryCredential{Registry: host, Username: resp.Username, Password: resp.Secret, Source: "helper " + helper}
+	// Helpers file identity tokens under this username.
+	if resp.Username == "<token>" {
+		cred.Username, cred.Password, cred.IdentityToken = "", "", resp.Secret
+	}
+	return cred, true, nil
+}
+
+// credentialHelperStore files a secret under server with
+// docker-credential-<helper>.
+func credentialHelperStore(ctx context.Context, helper, server, username, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+	req, err := json.Marshal(struct {
+		ServerURL string
+		Username  string
+		Secret    string
+	}{server, username, secret})
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "store")
+	cmd.Stdin = bytes.NewReader(req)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("credential helper %s: %w %s", helper, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// =============================================================================
+// Resolving credentials for API calls
+// =============================================================================
+
+// RegistryAuth finds the credential for a registry: the dashboard's own
+// store first, then the Docker CLI's config.json, which is read on every
+// lookup so that `docker login` takes effect without a restart. A store
+// that cannot be read is logged and skipped, so the Docker CLI's logins
+// keep working.
+type RegistryAuth struct {
+	Store      *CredentialStore
+	ConfigPath string
+}
+
+// Lookup returns the credential for a registry host, if any.
+func (a *RegistryAuth) Lookup(ctx context.Context, host string) (RegistryCredential, bool, error) {
+	if a.Store != nil {
+		cred, ok, err := a.Store.Lookup(host)
+		if err != nil {
+			log.Println("Error reading the dashboard's registry credentials:", err)
+		} else if ok {
+			return cred, true, nil
+		}
+	}
+	cfg, err := LoadDockerConfig(a.ConfigPath)
+	if err != nil {
+		return RegistryCredential{}, false, err
+	}
+	return cfg.Lookup(ctx, host)
+}
+
+// SetRegistryAuth makes the service attach credentials from auth to pulls,
+// pushes and searches. nil sends requests anonymously.
+func (s *Service) SetRegistryAuth(auth *RegistryAuth) {
+	s.auth = auth
+}
+
+// encodedAuth returns the X-Registry-Auth value for the registry ref points
+// at, empty when there are no credentials for it. A broken credential
+// helper is logged and the request goes out anonymously, so public images
+// still pull.
+func (s *Service) encodedAuth(ctx context.Context, ref string) (string, error) {
+	if s.auth == nil {
+		return "", nil
+	}
+	host := ReferenceRegistry(ref)
+	cred, ok, err := s.auth.Lookup(ctx, host)
+	if err != nil {
+		log.Printf("Error reading credentials for %s: %v", host, err)
+		return "", nil
+	}
+	if !ok {
+		return "", nil
+	}
+	return registry.EncodeAuthConfig(cred.authConfig())
+}
+
+// RegistryLogin checks cred against its registry through the daemon and
+// returns the registry's status message, e.g. "Login Succeeded".
+func (s *Service) RegistryLogin(ctx context.Context, cred RegistryCredential) (string, error) {
+	resp, err := s.cli.RegistryLogin(ctx, cred.authConfig())
+	if err != nil {
+		return "", fmt.Errorf("log in to %s: %w", cred.Registry, err)
+	}
+	return resp.Status, nil
+}
+
+// SearchImages searches a registry, Docker Hub unless term names one, for
+// up to limit repositories.
+func (s *Service) SearchImages(ctx context.Context, term string, limit int) ([]registry.SearchResult, error) {
+	auth, err := s.encodedAuth(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.cli.ImageSearch(ctx, term, registry.SearchOptions{RegistryAuth: auth, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("search %s: %w", term, err)
+	}
+	return results, nil
+}
+
+// PushImage pushes a tagged image, calling progress with each step but
+// not with the byte counts of running uploads.
+func (s *Service) PushImage(ctx context.Context, ref string, progress func(string)) error {
+	auth, err := s.encodedAuth(ctx, ref)
+	if err != nil {
+		return err
+	}
+	rc, err := s.cli.ImagePush(ctx, ref, dockerImage.PushOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("push image %s: %w", ref, err)
+	}
+	defer rc.Close()
+	dec := json.NewDecoder(rc)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("push image %s: %w", ref, err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("push image %s: %w", ref, msg.Error)
+		}
+		if msg.Progress != nil && msg.Progress.Total > 0 {
+			continue
+		}
+		progress(strings.TrimSpace(msg.ID + " " + msg.Status))
+	}
+}
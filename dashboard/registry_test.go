@@ -0,0 +1,384 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io":                         "ghcr.io",
+		"GHCR.io":                         "ghcr.io",
+		" ghcr.io ":                       "ghcr.io",
+		"https://ghcr.io/v2/":             "ghcr.io",
+		"localhost:5000":                  "localhost:5000",
+		"http://localhost:5000/v2":        "localhost:5000",
+		DockerHubRegistry:                 "docker.io",
+		"index.docker.io":                 "docker.io",
+		"registry-1.docker.io":            "docker.io",
+		"https://registry.hub.docker.com": "docker.io",
+		"docker.io":                       "docker.io",
+		"":                                "docker.io",
+	}
+	for addr, want := range tests {
+		if got := RegistryHost(addr); got != want {
+			t.Errorf("RegistryHost(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestReferenceRegistry(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                   "docker.io",
+		"library/nginx:1.27":      "docker.io",
+		"docker.io/library/nginx": "docker.io",
+		"index.docker.io/org/app": "docker.io",
+		"ghcr.io/org/app:1.0":     "ghcr.io",
+		"localhost:5000/app":      "localhost:5000",
+		"localhost/app":           "localhost",
+		"registry.example.com/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef": "registry.example.com",
+		// Search terms.
+		"quay.io/Some Term": "quay.io",
+		"org/Some Term":     "docker.io",
+		"some term":         "docker.io",
+	}
+	for ref, want := range tests {
+		if got := ReferenceRegistry(ref); got != want {
+			t.Errorf("ReferenceRegistry(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+// installCredentialHelpers puts docker-credential-<name> scripts on PATH.
+// Each answers with its own name as the username and the server it was
+// asked for as the secret, except for servers containing "missing", which
+// it has no credentials for, and "token", which it files an identity token
+// under.
+func installCredentialHelpers(t *testing.T, names ...string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helpers are shell scripts")
+	}
+	dir := t.TempDir()
+	for _, name := range names {
+		script := `#!/bin/sh
+read -r server
+case "$server" in
+*missing*) echo "credentials not found in native keychain"; exit 1 ;;
+*token*) printf '{"ServerURL":"%s","Username":"<token>","Secret":"tok"}' "$server" ;;
+*) printf '{"ServerURL":"%s","Username":"` + name + `","Secret":"%s"}' "$server" "$server" ;;
+esac
+`
+		if err := os.WriteFile(filepath.Join(dir, "docker-credential-"+name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestDockerConfigLookup(t *testing.T) {
+	installCredentialHelpers(t, "ghcr", "store")
+	cfg := &DockerConfig{}
+	cfg.Auths = map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	}{
+		DockerHubRegistry:         {Auth: base64.StdEncoding.EncodeToString([]byte("hubuser:hubpass"))},
+		"https://ghcr.io":         {Auth: base64.StdEncoding.EncodeToString([]byte("inline:secret"))},
+		"https://quay.io/v1/":     {},
+		"registry.example.com":    {IdentityToken: "oauth"},
+		"broken.example.com":      {Auth: "not base64!"},
+		"missing.example.com":     {},
+		"token.example.com":       {},
+		"https://unlisted.io/v2/": {},
+	}
+	cfg.CredsStore = "store"
+	cfg.CredHelpers = map[string]string{"ghcr.io": "ghcr"}
+
+	tests := []struct {
+		host                       string
+		found                      bool
+		user, password, token, src string
+	}{
+		// A registry's own helper comes before an inline entry.
+		{host: "ghcr.io", found: true, user: "ghcr", password: "ghcr.io", src: "helper ghcr"},
+		// Inline entries come before the credential store.
+		{host: "docker.io", found: true, user: "hubuser", password: "hubpass", src: "config.json"},
+		{host: "registry.example.com", found: true, token: "oauth", src: "config.json"},
+		// Empty entries are kept by the credential store, which is asked
+		// for the server as config.json spells it.
+		{host: "quay.io", found: true, user: "store", password: "https://quay.io/v1/", src: "helper store"},
+		{host: "unlisted.io", found: true, user: "store", password: "https://unlisted.io/v2/", src: "helper store"},
+		{host: "token.example.com", found: true, token: "tok", src: "helper store"},
+		{host: "missing.example.com", found: false},
+	}
+	for _, tt := range tests {
+		cred, ok, err := cfg.Lookup(context.Background(), tt.host)
+		if err != nil {
+			t.Errorf("%s: %v", tt.host, err)
+			continue
+		}
+		if ok != tt.found {
+			t.Errorf("%s: found = %v, want %v", tt.host, ok, tt.found)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if cred.Registry != tt.host || cred.Username != tt.user || cred.Password != tt.password ||
+			cred.IdentityToken != tt.token || cred.Source != tt.src {
+			t.Errorf("%s: got %+v, want user %q password %q token %q from %q", tt.host, cred, tt.user, tt.password, tt.token, tt.src)
+		}
+	}
+	if _, _, err := cfg.Lookup(context.Background(), "broken.example.com"); err == nil {
+		t.Error("a broken auth entry is accepted")
+	}
+
+	// Without a credential store, hosts with no entry have no credential.
+	cfg.CredsStore = ""
+	if cred, ok, err := cfg.Lookup(context.Background(), "quay.io"); ok || err != nil {
+		t.Errorf("quay.io without a store: %+v, %v, %v", cred, ok, err)
+	}
+}
+
+func TestDockerConfigLookupMissingHelper(t *testing.T) {
+	cfg := &DockerConfig{CredsStore: "sprint-test-no-such-helper"}
+	if _, _, err := cfg.Lookup(context.Background(), "ghcr.io"); err == nil {
+		t.Error("a missing helper is not reported")
+	}
+}
+
+// memKeyring is a keyring in memory.
+type memKeyring struct {
+	key []byte
+}
+
+func (k *memKeyring) get() ([]byte, bool, error) {
+	return k.key, k.key != nil, nil
+}
+
+func (k *memKeyring) set(key []byte) error {
+	k.key = key
+	return nil
+}
+
+func (k *memKeyring) String() string {
+	return "memory"
+}
+
+func TestCredentialStore(t *testing.T) {
+	dir := t.TempDir()
+	keys := &memKeyring{}
+	s := &CredentialStore{path: filepath.Join(dir, "registries.enc"), keys: keys}
+
+	if creds, err := s.Load(); err != nil || len(creds) != 0 {
+		t.Fatalf("empty store: %v, %v", creds, err)
+	}
+	if err := s.Put(RegistryCredential{Registry: "https://GHCR.io/", Username: "me", Password: "pat"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(RegistryCredential{Registry: "index.docker.io", Username: "hub", Password: "pw"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(RegistryCredential{Registry: "ghcr.io", Username: "me", Password: "ghcr-access-token"}); err != nil {
+		t.Fatal(err)
+	}
+	cred, ok, err := s.Lookup("ghcr.io")
+	if err != nil || !ok || cred.Password != "ghcr-access-token" || cred.Source != "dashboard" {
+		t.Errorf("ghcr.io = %+v, %v, %v", cred, ok, err)
+	}
+	if err := s.Delete("docker.io"); err != nil {
+		t.Fatal(err)
+	}
+	if creds, err := s.Load(); err != nil || len(creds) != 1 {
+		t.Errorf("after delete: %v, %v", creds, err)
+	}
+
+	// The file alone does not hold the key or the secrets.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("store directory holds %d files, want 1", len(entries))
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("ghcr-access-token")) || bytes.Contains(data, keys.key) {
+		t.Error("the store file holds a secret or its key")
+	}
+
+	// A lost key is reported rather than replaced, which would make the
+	// stored credentials unreadable for good.
+	keys.key = nil
+	if _, err := s.Load(); !errors.Is(err, ErrCredentialStoreUnreadable) {
+		t.Errorf("load without the key: %v", err)
+	}
+	if err := s.Put(RegistryCredential{Registry: "quay.io"}); !errors.Is(err, ErrCredentialStoreUnreadable) || keys.key != nil {
+		t.Errorf("put without the key: %v, key %v", err, keys.key)
+	}
+
+	// Resetting starts over with an empty store and a new key.
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(RegistryCredential{Registry: "quay.io", Username: "q", Password: "pw"}); err != nil || keys.key == nil {
+		t.Fatalf("put after reset: %v, key %v", err, keys.key)
+	}
+	if creds, err := s.Load(); err != nil || len(creds) != 1 || creds[0].Registry != "quay.io" {
+		t.Errorf("after reset: %v, %v", creds, err)
+	}
+
+	// A key that does not open the store is reported the same way.
+	keys.key = bytes.Repeat([]byte{1}, 32)
+	if _, err := s.Load(); !errors.Is(err, ErrCredentialStoreUnreadable) {
+		t.Errorf("load with the wrong key: %v", err)
+	}
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reset(); err != nil {
+		t.Errorf("resetting an empty store: %v", err)
+	}
+}
+
+func TestFileKeyring(t *testing.T) {
+	dir := t.TempDir()
+	k := fileKeyring{path: filepath.Join(dir, "sub", "registries.key")}
+	if key, ok, err := k.get(); ok || err != nil {
+		t.Fatalf("no key file: %v, %v, %v", key, ok, err)
+	}
+	want := []byte{0, 1, 2, 0xfe, 0xff}
+	if err := k.set(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := k.get(); !ok || err != nil || !bytes.Equal(got, want) {
+		t.Errorf("key = %v, %v, %v; want %v", got, ok, err, want)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(k.path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("key file mode: %v, %v", info.Mode(), err)
+		}
+	}
+}
+
+func TestDefaultKeyring(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux falls back to a key file")
+	}
+	helperDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(helperDir, "docker-credential-secretservice"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	tests := []struct {
+		name       string
+		helper     bool
+		bus        string
+		storeFile  bool
+		keyFile    bool
+		wantHelper bool
+	}{
+		{name: "keyring", helper: true, bus: "unix:path=/run/bus", wantHelper: true},
+		{name: "no helper", bus: "unix:path=/run/bus"},
+		{name: "no session bus", helper: true},
+		// A store keeps the mode it was created in.
+		{name: "existing store", storeFile: true, wantHelper: true},
+		{name: "existing key file", helper: true, bus: "unix:path=/run/bus", storeFile: true, keyFile: true},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if tt.helper {
+			t.Setenv("PATH", helperDir)
+		} else {
+			t.Setenv("PATH", dir)
+		}
+		t.Setenv("DBUS_SESSION_BUS_ADDRESS", tt.bus)
+		store := filepath.Join(dir, "registries.enc")
+		if tt.storeFile {
+			if err := os.WriteFile(store, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tt.keyFile {
+			if err := os.WriteFile(filepath.Join(dir, "registries.key"), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		k := defaultKeyring(store)
+		if _, isHelper := k.(helperKeyring); isHelper != tt.wantHelper {
+			t.Errorf("%s: keyring = %s", tt.name, k)
+		}
+		if f, ok := k.(fileKeyring); ok && f.path != filepath.Join(dir, "registries.key") {
+			t.Errorf("%s: key file = %s", tt.name, f.path)
+		}
+	}
+}
+
+func TestRegistryAuthLookupSkipsUnreadableStore(t *testing.T) {
+	dir := t.TempDir()
+	keys := &memKeyring{}
+	store := &CredentialStore{path: filepath.Join(dir, "registries.enc"), keys: keys}
+	if err := store.Put(RegistryCredential{Registry: "ghcr.io", Username: "me", Password: "pat"}); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	config := `{"auths":{"ghcr.io":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("cli:secret")) + `"}}}`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	auth := &RegistryAuth{Store: store, ConfigPath: configPath}
+
+	if cred, ok, err := auth.Lookup(context.Background(), "ghcr.io"); err != nil || !ok || cred.Source != "dashboard" {
+		t.Errorf("readable store: %+v, %v, %v", cred, ok, err)
+	}
+	keys.key = nil
+	if cred, ok, err := auth.Lookup(context.Background(), "ghcr.io"); err != nil || !ok || cred.Username != "cli" || cred.Source != "config.json" {
+		t.Errorf("unreadable store: %+v, %v, %v", cred, ok, err)
+	}
+}
+
+func TestHelperKeyring(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+	dir := t.TempDir()
+	// The helper keeps one entry, in the format get answers with.
+	script := `#!/bin/sh
+entry="$(dirname "$0")/entry"
+case "$1" in
+store) cat > "$entry" ;;
+get) [ -f "$entry" ] || { echo "credentials not found in native keychain"; exit 1; }; cat "$entry" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	k := helperKeyring{helper: "fake"}
+	if key, ok, err := k.get(); ok || err != nil {
+		t.Fatalf("empty keyring: %v, %v, %v", key, ok, err)
+	}
+	want := []byte{0, 1, 2, 0xfe, 0xff}
+	if err := k.set(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := k.get(); !ok || err != nil || !bytes.Equal(got, want) {
+		t.Errorf("key = %v, %v, %v; want %v", got, ok, err, want)
+	}
+
+	if _, _, err := (helperKeyring{helper: "sprint-test-no-such-helper"}).get(); err == nil || !strings.Contains(err.Error(), "install docker-credential-sprint-test-no-such-helper") {
+		t.Errorf("missing helper: %v", err)
+	}
+}
@@ -0,0 +1,678 @@
+package dashboard
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+)
+
+// RunCommand is a docker run command line parsed into a container spec.
+type RunCommand struct {
+	Spec ContainerSpec
+	// Unsupported lists the flags, with their values, that were recognised
+	// as flags but could not be imported.
+	Unsupported []string
+}
+
+// runShortFlags maps docker run's single letter flags to their long names.
+var runShortFlags = map[byte]string{
+	'a': "attach",
+	'c': "cpu-shares",
+	'd': "detach",
+	'e': "env",
+	'h': "hostname",
+	'i': "interactive",
+	'l': "label",
+	'm': "memory",
+	'p': "publish",
+	'P': "publish-all",
+	'q': "quiet",
+	't': "tty",
+	'u': "user",
+	'v': "volume",
+	'w': "workdir",
+}
+
+// runBoolFlags are the docker run flags that take no value. Any other
+// unknown flag is assumed to take one.
+var runBoolFlags = map[string]bool{
+	"detach":                true,
+	"interactive":           true,
+	"tty":                   true,
+	"publish-all":           true,
+	"rm":                    true,
+	"privileged":            true,
+	"read-only":             true,
+	"init":                  true,
+	"quiet":                 true,
+	"no-healthcheck":        true,
+	"oom-kill-disable":      true,
+	"sig-proxy":             true,
+	"disable-content-trust": true,
+	"help":                  true,
+}
+
+// ParseRunCommand parses a docker run (or docker create) command line.
+// The leading "docker run" is optional. Flags that are understood but have
+// no equivalent here are listed in Unsupported rather than dropped silently;
+// malformed values are errors.
+func ParseRunCommand(line string) (*RunCommand, error) {
+	words, err := SplitCommandLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) > 0 && words[0] == "docker" {
+		words = words[1:]
+		if len(words) > 0 && words[0] == "container" {
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && (words[0] == "run" || words[0] == "create") {
+		words = words[1:]
+	}
+
+	p := &runParser{
+		cfg:  &dockerContainer.Config{},
+		host: &dockerContainer.HostConfig{},
+		cmd:  &RunCommand{},
+	}
+	i := 0
+	for ; i < len(words); i++ {
+		w := words[i]
+		if w == "--" {
+			i++
+			break
+		}
+		if !strings.HasPrefix(w, "-") || w == "-" {
+			break
+		}
+		// next consumes the word after the flag as its value.
+		next := func(name string) (string, error) {
+			if i+1 >= len(words) {
+				return "", fmt.Errorf("flag %s needs a value", name)
+			}
+			i++
+			return words[i], nil
+		}
+
+		if strings.HasPrefix(w, "--") {
+			name, value, hasValue := strings.Cut(w[2:], "=")
+			if !hasValue && !runBoolFlags[name] {
+				if value, err = next(w); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.apply(name, value, w); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Short flags may be grouped (-it) and the last may carry its value
+		// in the same word (-p8080:80).
+		for j := 1; j < len(w); j++ {
+			name, ok := runShortFlags[w[j]]
+			if !ok {
+				return nil, fmt.Errorf("unknown flag -%c", w[j])
+			}
+			if runBoolFlags[name] {
+				if err := p.apply(name, "", "-"+w[j:j+1]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value := w[j+1:]
+			if value == "" {
+				if value, err = next("-" + w[j:j+1]); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.apply(name, value, "-"+w[j:j+1]); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if i >= len(words) {
+		return nil, fmt.Errorf("no image given")
+	}
+	p.cfg.Image = words[i]
+	if len(words) > i+1 {
+		p.cfg.Cmd = words[i+1:]
+	}
+	if err := p.finish(); err != nil {
+		return nil, err
+	}
+	p.cmd.Spec.Config = p.cfg
+	p.cmd.Spec.HostConfig = p.host
+	return p.cmd, nil
+}
+
+type runParser struct {
+	cfg     *dockerContainer.Config
+	host    *dockerContainer.HostConfig
+	cmd     *RunCommand
+	ports   []string
+	exposed []string
+}
+
+// apply applies one flag. flag is the flag as written, used in messages.
+func (p *runParser) apply(name, value, flag string) error {
+	// Boolean flags may be given as --rm=false.
+	on := value == "" || value == "true"
+	if runBoolFlags[name] && value != "" && value != "true" && value != "false" {
+		return fmt.Errorf("flag %s: %q is not true or false", flag, value)
+	}
+
+	switch name {
+	case "detach", "quiet":
+		// The dashboard always starts containers in the background.
+	case "interactive":
+		p.cfg.OpenStdin = on
+	case "tty":
+		p.cfg.Tty = on
+	case "rm":
+		p.host.AutoRemove = on
+	case "privileged":
+		p.host.Privileged = on
+	case "read-only":
+		p.host.ReadonlyRootfs = on
+	case "init":
+		p.host.Init = &on
+	case "publish-all":
+		p.host.PublishAllPorts = on
+	case "name":
+		p.cmd.Spec.Name = value
+	case "hostname":
+		p.cfg.Hostname = value
+	case "publish":
+		p.ports = append(p.ports, value)
+	case "expose":
+		p.exposed = append(p.exposed, value)
+	case "env":
+		p.addEnv(value)
+	case "env-file":
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", flag, err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				p.addEnv(line)
+			}
+		}
+	case "volume":
+		if !strings.Contains(value, ":") {
+			if p.cfg.Volumes == nil {
+				p.cfg.Volumes = map[string]struct{}{}
+			}
+			p.cfg.Volumes[value] = struct{}{}
+			break
+		}
+		p.host.Binds = append(p.host.Binds, value)
+	case "mount":
+		m, ignored, err := parseMount(value)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", flag, err)
+		}
+		for _, option := range ignored {
+			p.unsupported("--mount", option)
+		}
+		p.host.Mounts = append(p.host.Mounts, m)
+	case "network", "net":
+		if p.host.NetworkMode != "" {
+			p.unsupported(flag, value)
+			break
+		}
+		p.host.NetworkMode = dockerContainer.NetworkMode(value)
+	case "restart":
+		policy, retries, hasRetries := strings.Cut(value, ":")
+		p.host.RestartPolicy.Name = dockerContainer.RestartPolicyMode(policy)
+		if hasRetries {
+			n, err := strconv.Atoi(retries)
+			if err != nil || n < 0 {
+				return fmt.Errorf("flag %s: bad retry count %q", flag, retries)
+			}
+			p.host.RestartPolicy.MaximumRetryCount = n
+		}
+		if err := dockerContainer.ValidateRestartPolicy(p.host.RestartPolicy); err != nil {
+			return fmt.Errorf("flag %s: %w", flag, err)
+		}
+	case "memory":
+		n, err := units.RAMInBytes(value)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", flag, err)
+		}
+		p.host.Memory = n
+	case "cpus":
+		cpus, err := strconv.ParseFloat(value, 64)
+		if err != nil || cpus < 0 {
+			return fmt.Errorf("flag %s: bad CPU count %q", flag, value)
+		}
+		p.host.NanoCPUs = int64(cpus * 1e9)
+	case "cpu-shares":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("flag %s: bad CPU shares %q", flag, value)
+		}
+		p.host.CPUShares = n
+	case "cap-add":
+		p.host.CapAdd = append(p.host.CapAdd, value)
+	case "cap-drop":
+		p.host.CapDrop = append(p.host.CapDrop, value)
+	case "label":
+		k, v, _ := strings.Cut(value, "=")
+		if p.cfg.Labels == nil {
+			p.cfg.Labels = map[string]string{}
+		}
+		p.cfg.Labels[k] = v
+	case "entrypoint":
+		// Like docker run, the value is one word; an empty one clears the
+		// image's entrypoint.
+		p.cfg.Entrypoint = []string{value}
+	case "workdir":
+		p.cfg.WorkingDir = value
+	case "user":
+		p.cfg.User = value
+	default:
+		p.unsupported(flag, value)
+	}
+	return nil
+}
+
+// addEnv adds KEY=value, or KEY with its value taken from this process's
+// environment, as docker run does.
+func (p *runParser) addEnv(value string) {
+	if !strings.Contains(value, "=") {
+		v, ok := os.LookupEnv(value)
+		if !ok {
+			return
+		}
+		value += "=" + v
+	}
+	p.cfg.Env = append(p.cfg.Env, value)
+}
+
+func (p *runParser) unsupported(flag, value string) {
+	entry := flag
+	if value != "" && !strings.Contains(flag, "=") {
+		entry += " " + QuoteCommandLine([]string{value})
+	}
+	p.cmd.Unsupported = append(p.cmd.Unsupported, entry)
+}
+
+// finish turns the collected port flags into exposed ports and bindings.
+func (p *runParser) finish() error {
+	exposed, bindings, err := nat.ParsePortSpecs(p.ports)
+	if err != nil {
+		return fmt.Errorf("flag --publish: %w", err)
+	}
+	for _, e := range p.exposed {
+		proto, ports := nat.SplitProtoPort(e)
+		start, end, err := nat.ParsePortRange(ports)
+		if err != nil {
+			return fmt.Errorf("flag --expose: %w", err)
+		}
+		for port := start; port <= end; port++ {
+			exposed[nat.Port(fmt.Sprintf("%d/%s", port, proto))] = struct{}{}
+		}
+	}
+	if len(exposed) > 0 {
+		p.cfg.ExposedPorts = exposed
+	}
+	if len(bindings) > 0 {
+		p.host.PortBindings = bindings
+	}
+	return nil
+}
+
+// parseMount parses the comma-separated key=value options of --mount.
+// Options it does not know are returned in ignored, as written.
+func parseMount(value string) (m mount.Mount, ignored []string, err error) {
+	m = mount.Mount{Type: mount.TypeVolume}
+	for _, field := range strings.Split(value, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		key, val, hasVal := strings.Cut(field, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		flag := func() (bool, error) {
+			if !hasVal {
+				return true, nil
+			}
+			return strconv.ParseBool(val)
+		}
+		switch key {
+		case "type":
+			m.Type = mount.Type(val)
+		case "source", "src":
+			m.Source = val
+		case "target", "destination", "dst":
+			m.Target = val
+		case "readonly", "ro":
+			m.ReadOnly, err = flag()
+		case "bind-propagation":
+			m.BindOptions = &mount.BindOptions{Propagation: mount.Propagation(val)}
+		case "volume-nocopy":
+			if m.VolumeOptions == nil {
+				m.VolumeOptions = &mount.VolumeOptions{}
+			}
+			m.VolumeOptions.NoCopy, err = flag()
+		case "tmpfs-size":
+			if m.TmpfsOptions == nil {
+				m.TmpfsOptions = &mount.TmpfsOptions{}
+			}
+			m.TmpfsOptions.SizeBytes, err = units.RAMInBytes(val)
+		default:
+			ignored = append(ignored, field)
+		}
+		if err != nil {
+			return m, nil, fmt.Errorf("mount option %s: %w", key, err)
+		}
+	}
+	switch m.Type {
+	case mount.TypeBind, mount.TypeVolume, mount.TypeTmpfs:
+	default:
+		return m, nil, fmt.Errorf("unsupported mount type %q", m.Type)
+	}
+	if m.Target == "" {
+		return m, nil, fmt.Errorf("mount has no target")
+	}
+	return m, ignored, nil
+}
+
+// RunCommandFor rebuilds a docker run command line that recreates the
+// container, leaving out values the container inherits from its image.
+func (s *Service) RunCommandFor(ctx context.Context, id string) (string, error) {
+	info, err := s.InspectContainer(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	var image *dockerContainer.Config
+	if img, _, err := s.cli.ImageInspectWithRaw(ctx, info.Image); err == nil {
+		image = img.Config
+	}
+	// Without the image every value is written out, which is verbose but
+	// still correct.
+	return FormatRunCommand(info, image), nil
+}
+
+// FormatRunCommand writes info as a docker run command, one flag per line.
+// Values equal to those in image, the image's config, are left out.
+func FormatRunCommand(info types.ContainerJSON, image *dockerContainer.Config) string {
+	cfg := info.Config
+	host := info.HostConfig
+	if cfg == nil {
+		cfg = &dockerContainer.Config{}
+	}
+	if host == nil {
+		host = &dockerContainer.HostConfig{}
+	}
+	if image == nil {
+		image = &dockerContainer.Config{}
+	}
+
+	var lines [][]string
+	add := func(words ...string) { lines = append(lines, words) }
+
+	if name := strings.TrimPrefix(info.Name, "/"); name != "" {
+		add("--name", name)
+	}
+	if cfg.Hostname != "" && !strings.HasPrefix(info.ID, cfg.Hostname) {
+		add("--hostname", cfg.Hostname)
+	}
+	if cfg.User != image.User {
+		add("--user", cfg.User)
+	}
+	if cfg.WorkingDir != image.WorkingDir {
+		add("--workdir", cfg.WorkingDir)
+	}
+	if cfg.Tty {
+		add("--tty")
+	}
+	if cfg.OpenStdin {
+		add("--interactive")
+	}
+	if host.AutoRemove {
+		add("--rm")
+	}
+	if host.Init != nil && *host.Init {
+		add("--init")
+	}
+	if host.Privileged {
+		add("--privileged")
+	}
+	if host.ReadonlyRootfs {
+		add("--read-only")
+	}
+
+	for _, kv := range cfg.Env {
+		if !slices.Contains(image.Env, kv) {
+			add("--env", kv)
+		}
+	}
+	for _, k := range SortedKeys(cfg.Labels) {
+		if v, ok := image.Labels[k]; !ok || v != cfg.Labels[k] {
+			add("--label", k+"="+cfg.Labels[k])
+		}
+	}
+
+	// Ports
+	if host.PublishAllPorts {
+		add("--publish-all")
+	}
+	for _, port := range sortedPorts(host.PortBindings) {
+		for _, b := range host.PortBindings[port] {
+			add("--publish", FormatPortSpec(port, b))
+		}
+	}
+	exposed := nat.PortSet{}
+	for port := range cfg.ExposedPorts {
+		_, inImage := image.ExposedPorts[port]
+		_, published := host.PortBindings[port]
+		if !inImage && !published {
+			exposed[port] = struct{}{}
+		}
+	}
+	for _, port := range sortedPorts(exposed) {
+		add("--expose", string(port))
+	}
+
+	// Storage. Anonymous volumes show up in Config.Volumes; those declared by
+	// the image or mounted over by another flag are not repeated.
+	targets := map[string]bool{}
+	for _, bind := range host.Binds {
+		add("--volume", bind)
+		if parts := strings.Split(bind, ":"); len(parts) > 1 {
+			targets[parts[1]] = true
+		}
+	}
+	for _, m := range host.Mounts {
+		add("--mount", formatMount(m))
+		targets[m.Target] = true
+	}
+	for _, path := range SortedKeys(cfg.Volumes) {
+		if _, inImage := image.Volumes[path]; !inImage && !targets[path] {
+			add("--volume", path)
+		}
+	}
+
+	// Networks
+	mode := string(host.NetworkMode)
+	if mode != "" && mode != "default" && mode != "bridge" {
+		add("--network", mode)
+	}
+	if info.NetworkSettings != nil {
+		for _, name := range SortedKeys(info.NetworkSettings.Networks) {
+			if name != mode && !(name == "bridge" && (mode == "" || mode == "default")) {
+				add("--network", name)
+			}
+		}
+	}
+	for _, h := range host.ExtraHosts {
+		add("--add-host", h)
+	}
+	for _, dns := range host.DNS {
+		add("--dns", dns)
+	}
+
+	if p := host.RestartPolicy; p.Name != "" && p.Name != dockerContainer.RestartPolicyDisabled {
+		policy := string(p.Name)
+		if p.MaximumRetryCount > 0 {
+			policy += ":" + strconv.Itoa(p.MaximumRetryCount)
+		}
+		add("--restart", policy)
+	}
+
+	// Resources. The daemon sets the swap limit to twice the memory limit
+	// unless told otherwise.
+	if host.Memory > 0 {
+		add("--memory", formatBytes(host.Memory))
+	}
+	if host.MemorySwap > 0 && host.MemorySwap != 2*host.Memory {
+		add("--memory-swap", formatBytes(host.MemorySwap))
+	}
+	if host.MemorySwap < 0 {
+		add("--memory-swap", "-1")
+	}
+	if host.NanoCPUs > 0 {
+		add("--cpus", strconv.FormatFloat(float64(host.NanoCPUs)/1e9, 'f', -1, 64))
+	}
+	if host.CPUShares > 0 {
+		add("--cpu-shares", strconv.FormatInt(host.CPUShares, 10))
+	}
+	if host.PidsLimit != nil && *host.PidsLimit > 0 {
+		add("--pids-limit", strconv.FormatInt(*host.PidsLimit, 10))
+	}
+	for _, c := range host.CapAdd {
+		add("--cap-add", c)
+	}
+	for _, c := range host.CapDrop {
+		add("--cap-drop", c)
+	}
+
+	// docker run takes a single word for --entrypoint; any further words
+	// move in front of the command. Overriding the entrypoint also drops the
+	// image's command, so the command is then always written.
+	cmd := []string(cfg.Cmd)
+	entrypointChanged := !slices.Equal(cfg.Entrypoint, image.Entrypoint)
+	if entrypointChanged {
+		if len(cfg.Entrypoint) == 0 {
+			add("--entrypoint", "")
+		} else {
+			add("--entrypoint", cfg.Entrypoint[0])
+			cmd = append(slices.Clone(cfg.Entrypoint[1:]), cmd...)
+		}
+	}
+	if !entrypointChanged && slices.Equal(cfg.Cmd, image.Cmd) {
+		cmd = nil
+	}
+
+	var b strings.Builder
+	b.WriteString("docker run --detach")
+	for _, words := range lines {
+		b.WriteString(" \\\n  ")
+		b.WriteString(QuoteCommandLine(words))
+	}
+	b.WriteString(" \\\n  ")
+	b.WriteString(QuoteCommandLine([]string{cfg.Image}))
+	if len(cmd) > 0 {
+		b.WriteString(" ")
+		b.WriteString(QuoteCommandLine(cmd))
+	}
+	return b.String()
+}
+
+// formatMount writes m as a --mount value.
+func formatMount(m mount.Mount) string {
+	fields := []string{"type=" + string(m.Type)}
+	if m.Source != "" {
+		fields = append(fields, "source="+m.Source)
+	}
+	fields = append(fields, "target="+m.Target)
+	if m.ReadOnly {
+		fields = append(fields, "readonly")
+	}
+	if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+		fields = append(fields, "bind-propagation="+string(m.BindOptions.Propagation))
+	}
+	if m.VolumeOptions != nil && m.VolumeOptions.NoCopy {
+		fields = append(fields, "volume-nocopy")
+	}
+	if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+		fields = append(fields, "tmpfs-size="+formatBytes(m.TmpfsOptions.SizeBytes))
+	}
+	return strings.Join(fields, ",")
+}
+
+// formatBytes writes a size in the largest binary unit that divides it, as
+// docker run -m reads it.
+func formatBytes(n int64) string {
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}} {
+		if n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "b"
+}
+
+func sortedPorts[V any](m map[nat.Port]V) []nat.Port {
+	ports := make([]nat.Port, 0, len(m))
+	for p := range m {
+		ports = append(ports, p)
+	}
+	nat.Sort(ports, func(a, b nat.Port) bool {
+		if a.Int() != b.Int() {
+			return a.Int() < b.Int()
+		}
+		return a.Proto() < b.Proto()
+	})
+	return ports
+}
+
+// FormatPortSpec writes a published port as docker run -p reads it:
+// [hostIP:][hostPort:]containerPort[/proto], with an IPv6 host IP in
+// brackets and the protocol left out for tcp.
+func FormatPortSpec(port nat.Port, b nat.PortBinding) string {
+	spec := port.Port()
+	if port.Proto() != "tcp" {
+		spec = string(port)
+	}
+	if b.HostPort != "" || b.HostIP != "" {
+		spec = b.HostPort + ":" + spec
+	}
+	switch {
+	case strings.Contains(b.HostIP, ":"):
+		spec = "[" + b.HostIP + "]:" + spec
+	case b.HostIP != "":
+		spec = b.HostIP + ":" + spec
+	}
+	return spec
+}
+
+// SortedKeys returns the keys of m in order.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
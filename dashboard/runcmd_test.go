@@ -0,0 +1,278 @@
+package dashboard
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  string
+	}{
+		{in: "", want: nil},
+		{in: "  a  b\tc\n", want: []string{"a", "b", "c"}},
+		{in: `'a b' "c d"`, want: []string{"a b", "c d"}},
+		{in: `"say 'hi'"`, want: []string{"say 'hi'"}},
+		{in: `'say "hi"'`, want: []string{`say "hi"`}},
+		{in: `'it'\''s'`, want: []string{"it's"}},
+		{in: `"a \"b\" \$c \n"`, want: []string{`a "b" $c \n`}},
+		{in: `'\n'`, want: []string{`\n`}},
+		{in: `a\ b c`, want: []string{"a b", "c"}},
+		{in: "a \\\n  b", want: []string{"a", "b"}},
+		{in: `"" x ''`, want: []string{"", "x", ""}},
+		{in: `pre"mid"'end'`, want: []string{"premidend"}},
+		{in: `$HOME *`, want: []string{"$HOME", "*"}},
+		{in: `'open`, err: "unterminated single quote"},
+		{in: `"open`, err: "unterminated double quote"},
+	}
+	for _, tt := range tests {
+		got, err := SplitCommandLine(tt.in)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("SplitCommandLine(%q) error = %v, want %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SplitCommandLine(%q): %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SplitCommandLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteCommandLineRoundTrip(t *testing.T) {
+	tests := [][]string{
+		{"docker", "run", "nginx"},
+		{""},
+		{"a b", "it's", `say "hi"`},
+		{"$HOME", "*.go", "a;b", "x|y", "back\\slash"},
+		{"line\nbreak", "tab\there"},
+		{"--env=A=1", "user@host:/path,opt+x"},
+	}
+	for _, words := range tests {
+		line := QuoteCommandLine(words)
+		got, err := SplitCommandLine(line)
+		if err != nil {
+			t.Errorf("split %q: %v", line, err)
+			continue
+		}
+		if !slices.Equal(got, words) {
+			t.Errorf("%q quoted as %q splits into %q", words, line, got)
+		}
+	}
+	if got := QuoteCommandLine([]string{"run", "--name", "web", "a b"}); got != "run --name web 'a b'" {
+		t.Errorf("only words that need it are quoted: %q", got)
+	}
+}
+
+func TestParseRunCommand(t *testing.T) {
+	cmd, err := ParseRunCommand(`docker run -itd --rm=false --name=web -p8080:80 -p 127.0.0.1:5353:53/udp ` +
+		`-e A=1 --env "B=two words" -l tier=front -v data:/data -v /cache --mount type=tmpfs,target=/tmp,tmpfs-size=64m ` +
+		`--restart on-failure:3 -m 512m --cpus=1.5 -c 512 --cap-add NET_ADMIN -w /app -u 1000 ` +
+		`--entrypoint "" nginx:1.27 sh -c 'echo "hi"; exec nginx'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmd.Unsupported) != 0 {
+		t.Errorf("unsupported = %q", cmd.Unsupported)
+	}
+	cfg, host := cmd.Spec.Config, cmd.Spec.HostConfig
+	if cmd.Spec.Name != "web" || cfg.Image != "nginx:1.27" || !cfg.Tty || !cfg.OpenStdin || host.AutoRemove {
+		t.Errorf("name %q, image %q, tty %v, stdin %v, rm %v", cmd.Spec.Name, cfg.Image, cfg.Tty, cfg.OpenStdin, host.AutoRemove)
+	}
+	if want := []string{"sh", "-c", `echo "hi"; exec nginx`}; !slices.Equal(cfg.Cmd, want) {
+		t.Errorf("cmd = %q, want %q", cfg.Cmd, want)
+	}
+	if want := []string{""}; !slices.Equal(cfg.Entrypoint, want) {
+		t.Errorf("entrypoint = %q, want %q", cfg.Entrypoint, want)
+	}
+	if want := []string{"A=1", "B=two words"}; !slices.Equal(cfg.Env, want) {
+		t.Errorf("env = %q, want %q", cfg.Env, want)
+	}
+	if b := host.PortBindings["53/udp"]; len(b) != 1 || b[0].HostIP != "127.0.0.1" || b[0].HostPort != "5353" {
+		t.Errorf("53/udp bound to %+v", b)
+	}
+	if b := host.PortBindings["80/tcp"]; len(b) != 1 || b[0].HostPort != "8080" {
+		t.Errorf("80/tcp bound to %+v", b)
+	}
+	if !slices.Equal(host.Binds, []string{"data:/data"}) || len(cfg.Volumes) != 1 {
+		t.Errorf("binds %q, volumes %v", host.Binds, cfg.Volumes)
+	}
+	if len(host.Mounts) != 1 || host.Mounts[0].Type != mount.TypeTmpfs || host.Mounts[0].TmpfsOptions.SizeBytes != 64<<20 {
+		t.Errorf("mounts = %+v", host.Mounts)
+	}
+	if host.RestartPolicy.Name != dockerContainer.RestartPolicyOnFailure || host.RestartPolicy.MaximumRetryCount != 3 {
+		t.Errorf("restart = %+v", host.RestartPolicy)
+	}
+	if host.Memory != 512<<20 || host.NanoCPUs != 1500000000 || host.CPUShares != 512 {
+		t.Errorf("memory %d, cpus %d, shares %d", host.Memory, host.NanoCPUs, host.CPUShares)
+	}
+	if cfg.WorkingDir != "/app" || cfg.User != "1000" || cfg.Labels["tier"] != "front" || !slices.Equal(host.CapAdd, []string{"NET_ADMIN"}) {
+		t.Errorf("workdir %q, user %q, labels %v, cap_add %v", cfg.WorkingDir, cfg.User, cfg.Labels, host.CapAdd)
+	}
+}
+
+func TestParseRunCommandUnsupported(t *testing.T) {
+	cmd, err := ParseRunCommand(`run --gpus all --log-driver=json-file --network a --network b ` +
+		`--mount type=volume,source=data,target=/data,volume-driver=local,volume-opt=o=bind nginx`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--gpus all",
+		"--log-driver=json-file",
+		"--network b",
+		"--mount volume-driver=local",
+		"--mount volume-opt=o=bind",
+	}
+	if !slices.Equal(cmd.Unsupported, want) {
+		t.Errorf("unsupported = %q, want %q", cmd.Unsupported, want)
+	}
+	m := cmd.Spec.HostConfig.Mounts
+	if len(m) != 1 || m[0].Source != "data" || m[0].Target != "/data" {
+		t.Errorf("the mount is still imported: %+v", m)
+	}
+}
+
+func TestParseRunCommandErrors(t *testing.T) {
+	tests := []struct {
+		line, err string
+	}{
+		{"docker run", "no image given"},
+		{"docker run -d", "no image given"},
+		{"docker run --name", "flag --name needs a value"},
+		{"docker run -Z nginx", "unknown flag -Z"},
+		{"docker run --rm=maybe nginx", `flag --rm=maybe: "maybe" is not true or false`},
+		{"docker run -m lots nginx", "flag -m"},
+		{"docker run --cpus=-1 nginx", "bad CPU count"},
+		{"docker run --restart always:2 nginx", "flag --restart"},
+		{"docker run --mount type=npipe,target=/x nginx", "unsupported mount type"},
+		{"docker run --mount source=data nginx", "mount has no target"},
+		{"docker run --mount target=/x,readonly=maybe nginx", "mount option readonly"},
+		{"docker run -p 80:80:80:80 nginx", "flag --publish"},
+		{`docker run "nginx`, "unterminated double quote"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRunCommand(tt.line)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseRunCommand(%q) error = %v, want %q", tt.line, err, tt.err)
+		}
+	}
+}
+
+func TestParseRunCommandEndOfFlags(t *testing.T) {
+	cmd, err := ParseRunCommand("docker container create -t -- alpine -t ls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Spec.Config.Image != "alpine" || !slices.Equal(cmd.Spec.Config.Cmd, []string{"-t", "ls"}) {
+		t.Errorf("image %q, cmd %q", cmd.Spec.Config.Image, cmd.Spec.Config.Cmd)
+	}
+}
+
+// TestRunCommandRoundTrip parses command lines, formats the result as a
+// container and parses that again, which must give the same container.
+func TestRunCommandRoundTrip(t *testing.T) {
+	tests := []string{
+		"docker run nginx",
+		"docker run -itd --name web -p 8080:80 -p [::1]:9090:90/udp --expose 7000 nginx",
+		`docker run --entrypoint "" alpine sh -c 'echo "it'\''s"'`,
+		"docker run --entrypoint /bin/sh alpine -c date",
+		"docker run --rm --init --privileged --read-only -u 1000:1000 -w /work alpine",
+		`docker run -e A=1 -e "B=x y" -l "note=a b" -l tier=front alpine`,
+		"docker run -v data:/data:ro -v /cache --mount type=bind,source=/etc,target=/host/etc,readonly,bind-propagation=rslave alpine",
+		"docker run --mount type=volume,source=v,target=/v,volume-nocopy --mount type=tmpfs,target=/t,tmpfs-size=1g alpine",
+		"docker run --network backend --restart unless-stopped -m 256m --memory-swap -1 --cpus 0.5 --cpu-shares 256 alpine",
+		"docker run --cap-add NET_ADMIN --cap-drop MKNOD --add-host db:10.0.0.2 --dns 1.1.1.1 alpine",
+	}
+	for _, line := range tests {
+		first, err := ParseRunCommand(line)
+		if err != nil {
+			t.Errorf("%s: %v", line, err)
+			continue
+		}
+		// Flags the parser does not import cannot survive the trip.
+		for _, u := range first.Unsupported {
+			if !strings.HasPrefix(u, "--memory-swap") && !strings.HasPrefix(u, "--add-host") && !strings.HasPrefix(u, "--dns") {
+				t.Errorf("%s: unexpected unsupported flag %q", line, u)
+			}
+		}
+		info := types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{Name: "/" + first.Spec.Name, HostConfig: first.Spec.HostConfig},
+			Config:            first.Spec.Config,
+		}
+		formatted := FormatRunCommand(info, nil)
+		second, err := ParseRunCommand(formatted)
+		if err != nil {
+			t.Errorf("%s: parse formatted %q: %v", line, formatted, err)
+			continue
+		}
+		if !reflect.DeepEqual(first.Spec, second.Spec) {
+			t.Errorf("%s: formatted as\n%s\nparses to\n%+v %+v\nwant\n%+v %+v", line, formatted,
+				second.Spec.Config, second.Spec.HostConfig, first.Spec.Config, first.Spec.HostConfig)
+		}
+	}
+}
+
+func TestFormatRunCommandLeavesOutImageDefaults(t *testing.T) {
+	image := &dockerContainer.Config{
+		Env:          []string{"PATH=/usr/bin"},
+		Cmd:          []string{"nginx", "-g", "daemon off;"},
+		Entrypoint:   []string{"/docker-entrypoint.sh"},
+		WorkingDir:   "/srv",
+		ExposedPorts: nat.PortSet{"80/tcp": {}},
+		Volumes:      map[string]struct{}{"/var/cache": {}},
+	}
+	cfg := *image
+	cfg.Image = "nginx"
+	cfg.Env = append(slices.Clone(image.Env), "MODE=prod")
+	info := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{Name: "/web", HostConfig: &dockerContainer.HostConfig{}},
+		Config:            &cfg,
+	}
+	want := "docker run --detach \\\n  --name web \\\n  --env MODE=prod \\\n  nginx"
+	if got := FormatRunCommand(info, image); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatPortSpec(t *testing.T) {
+	tests := []struct {
+		port    nat.Port
+		binding nat.PortBinding
+		want    string
+	}{
+		{"80/tcp", nat.PortBinding{}, "80"},
+		{"80/tcp", nat.PortBinding{HostPort: "8080"}, "8080:80"},
+		{"53/udp", nat.PortBinding{HostPort: "5353"}, "5353:53/udp"},
+		{"80/tcp", nat.PortBinding{HostIP: "127.0.0.1", HostPort: "8080"}, "127.0.0.1:8080:80"},
+		{"80/tcp", nat.PortBinding{HostIP: "::1", HostPort: "8080"}, "[::1]:8080:80"},
+		// A host IP without a host port keeps the empty port.
+		{"80/tcp", nat.PortBinding{HostIP: "127.0.0.1"}, "127.0.0.1::80"},
+		{"53/udp", nat.PortBinding{HostIP: "fe80::1"}, "[fe80::1]::53/udp"},
+	}
+	for _, tt := range tests {
+		got := FormatPortSpec(tt.port, tt.binding)
+		if got != tt.want {
+			t.Errorf("FormatPortSpec(%s, %+v) = %q, want %q", tt.port, tt.binding, got, tt.want)
+			continue
+		}
+		// docker run -p reads the spec back to the same binding.
+		mappings, err := nat.ParsePortSpec(got)
+		if err != nil || len(mappings) != 1 || mappings[0].Port != tt.port || mappings[0].Binding != tt.binding {
+			t.Errorf("ParsePortSpec(%q) = %+v, %v", got, mappings, err)
+		}
+	}
+}
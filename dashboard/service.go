@@ -0,0 +1,543 @@
+// Package dashboard contains the Docker operations used by the desktop UI.
+// It has no Fyne dependency, so it can be driven by other front-ends or by a
+// fake DockerService in tests.
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerImage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// DockerService is everything the dashboard needs from a Docker daemon.
+type DockerService interface {
+	ListContainers(ctx context.Context, f filters.Args) ([]Container, error)
+	GetContainer(ctx context.Context, id string) (Container, error)
+	StartContainer(ctx context.Context, id string) error
+	StopContainer(ctx context.Context, id string) error
+	RestartContainer(ctx context.Context, id string) error
+	RemoveContainer(ctx context.Context, id string) error
+	InspectContainer(ctx context.Context, id string) (types.ContainerJSON, error)
+	ContainerStatsOnce(ctx context.Context, id string) (types.StatsJSON, error)
+	StreamStats(ctx context.Context, id string, handle func(types.StatsJSON)) error
+	ContainerLogs(ctx context.Context, id string, opts dockerContainer.LogsOptions) (io.ReadCloser, error)
+	StreamLogs(ctx context.Context, id string, opts LogOptions, handle func(LogLine)) error
+	StreamMergedLogs(ctx context.Context, sources []LogSource, opts LogOptions, handle func(LogLine)) error
+	RunContainer(ctx context.Context, spec ContainerSpec) (string, error)
+	RunCommandFor(ctx context.Context, id string) (string, error)
+	Exec(ctx context.Context, id string, opts ExecOptions) (*Session, error)
+	Attach(ctx context.Context, id, detachKeys string) (*Session, error)
+
+	ListImages(ctx context.Context, f filters.Args) ([]Image, error)
+	PullImage(ctx context.Context, ref string) error
+	PullImageWithProgress(ctx context.Context, ref string, opts PullOptions, handle func(*PullProgress)) (*PullProgress, error)
+	PushImage(ctx context.Context, ref string, progress func(string)) error
+	SearchImages(ctx context.Context, term string, limit int) ([]registry.SearchResult, error)
+	RegistryLogin(ctx context.Context, cred RegistryCredential) (string, error)
+	RemoveImage(ctx context.Context, id string) error
+	BuildImage(ctx context.Context, spec BuildSpec, handle func(BuildProgress)) (string, error)
+
+	ListVolumes(ctx context.Context, f filters.Args) ([]Volume, error)
+	GetVolume(ctx context.Context, name string) (Volume, error)
+	CreateVolume(ctx context.Context, name string) (Volume, error)
+	RemoveVolume(ctx context.Context, name string) error
+
+	ListNetworks(ctx context.Context, f filters.Args) ([]Network, error)
+	GetNetwork(ctx context.Context, id string) (Network, error)
+	CreateNetwork(ctx context.Context, spec NetworkSpec) (string, error)
+	RemoveNetwork(ctx context.Context, id string) error
+
+	ComposeUp(ctx context.Context, p *ComposeProject, progress func(string)) error
+	ComposeDown(ctx context.Context, project string, removeVolumes bool, progress func(string)) error
+	ComposeStatus(ctx context.Context, p *ComposeProject) ([]ServiceStatus, error)
+	ExportCompose(ctx context.Context, ids []string) ([]byte, error)
+
+	InspectRaw(ctx context.Context, kind, id string) ([]byte, error)
+	WatchEvents(ctx context.Context, handle func(Event), lost func(error)) error
+}
+
+// ErrNotFound matches errors for actions whose target was removed after it
+// was listed. Use errors.Is to test for it.
+var ErrNotFound = errors.New("resource no longer exists")
+
+// NotFoundError reports which resource an action could not find.
+type NotFoundError struct {
+	Kind string // "container", "image", "volume" or "network"
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s no longer exists", e.Kind, shortID(e.ID))
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Container is a row of the container list.
+type Container struct {
+	ID      string
+	Name    string
+	Image   string
+	ImageID string
+	Command string
+	State   string
+	Status  string
+	Created time.Time
+	Ports   []types.Port
+	Labels  map[string]string
+}
+
+// ShortID returns the 12 character ID the docker CLI prints.
+func (c Container) ShortID() string {
+	return shortID(c.ID)
+}
+
+// Image is a row of the image list.
+type Image struct {
+	ID       string
+	RepoTags []string
+	Size     int64
+	Created  time.Time
+	// Containers counts the containers using the image, or is -1 when
+	// unknown.
+	Containers int64
+	Labels     map[string]string
+}
+
+// ShortID returns the 12 character ID without the "sha256:" prefix.
+func (i Image) ShortID() string {
+	return shortID(i.ID)
+}
+
+// Volume is a row of the volume list.
+type Volume struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Scope      string
+	CreatedAt  string
+	Labels     map[string]string
+	// Containers counts the containers mounting the volume, or is -1 when
+	// unknown.
+	Containers int
+}
+
+// Network is a row of the network list.
+type Network struct {
+	ID       string
+	Name     string
+	Driver   string
+	Scope    string
+	Internal bool
+	Created  time.Time
+	Labels   map[string]string
+	// Containers counts the containers attached to the network, or is -1
+	// when unknown.
+	Containers int
+}
+
+// ShortID returns the 12 character network ID.
+func (n Network) ShortID() string {
+	return shortID(n.ID)
+}
+
+// ContainerSpec describes a container to create with RunContainer.
+type ContainerSpec struct {
+	Name             string
+	Config           *dockerContainer.Config
+	HostConfig       *dockerContainer.HostConfig
+	NetworkingConfig *dockerNetwork.NetworkingConfig
+}
+
+// NetworkSpec describes a network to create with CreateNetwork.
+type NetworkSpec struct {
+	Name    string
+	Driver  string
+	Options map[string]string
+}
+
+// Service implements DockerService on top of the Docker Engine API client.
+type Service struct {
+	cli  client.APIClient
+	auth *RegistryAuth
+	// finished caches when exited containers stopped, for
+	// ExitedWithinFilter.
+	finished finishTimes
+}
+
+var _ DockerService = (*Service)(nil)
+
+// NewService wraps an API client. The client is not closed by the service.
+func NewService(cli client.APIClient) *Service {
+	return &Service{cli: cli}
+}
+
+// ListContainers lists the containers, running or not, matching the Docker
+// filter arguments f, which may also hold ExitedWithinFilter.
+func (s *Service) ListContainers(ctx context.Context, f filters.Args) ([]Container, error) {
+	f, within, err := splitExitedWithin(f)
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	list, err := s.cli.ContainerList(ctx, dockerContainer.ListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	result := make([]Container, len(list))
+	for i, c := range list {
+		result[i] = containerFromSummary(c)
+	}
+	if within > 0 {
+		result, err = s.exitedSince(ctx, result, time.Now().Add(-within))
+		if err != nil {
+			return nil, fmt.Errorf("list containers: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// GetContainer returns the list row for a single container.
+func (s *Service) GetContainer(ctx context.Context, id string) (Container, error) {
+	list, err := s.cli.ContainerList(ctx, dockerContainer.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", id)),
+	})
+	if err != nil {
+		return Container{}, resourceError("get", "container", id, err)
+	}
+	// The id filter matches prefixes, so insist on the exact container.
+	for _, c := range list {
+		if c.ID == id {
+			return containerFromSummary(c), nil
+		}
+	}
+	return Container{}, &NotFoundError{Kind: "container", ID: id}
+}
+
+func (s *Service) StartContainer(ctx context.Context, id string) error {
+	if err := s.cli.ContainerStart(ctx, id, dockerContainer.StartOptions{}); err != nil {
+		return resourceError("start", "container", id, err)
+	}
+	return nil
+}
+
+func (s *Service) StopContainer(ctx context.Context, id string) error {
+	if err := s.cli.ContainerStop(ctx, id, dockerContainer.StopOptions{}); err != nil {
+		return resourceError("stop", "container", id, err)
+	}
+	return nil
+}
+
+func (s *Service) RestartContainer(ctx context.Context, id string) error {
+	if err := s.cli.ContainerRestart(ctx, id, dockerContainer.StopOptions{}); err != nil {
+		return resourceError("restart", "container", id, err)
+	}
+	return nil
+}
+
+// RemoveContainer force-removes a container, stopping it first if needed.
+func (s *Service) RemoveContainer(ctx context.Context, id string) error {
+	if err := s.cli.ContainerRemove(ctx, id, dockerContainer.RemoveOptions{Force: true}); err != nil {
+		return resourceError("remove", "container", id, err)
+	}
+	return nil
+}
+
+func (s *Service) InspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	info, err := s.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return types.ContainerJSON{}, resourceError("inspect", "container", id, err)
+	}
+	return info, nil
+}
+
+// ContainerStatsOnce takes a single stats sample.
+func (s *Service) ContainerStatsOnce(ctx context.Context, id string) (types.StatsJSON, error) {
+	resp, err := s.cli.ContainerStatsOneShot(ctx, id)
+	if err != nil {
+		return types.StatsJSON{}, resourceError("read stats of", "container", id, err)
+	}
+	defer resp.Body.Close()
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return types.StatsJSON{}, fmt.Errorf("decode stats %s: %w", shortID(id), err)
+	}
+	return stats, nil
+}
+
+// ContainerLogs returns the raw log stream; the caller must close it.
+func (s *Service) ContainerLogs(ctx context.Context, id string, opts dockerContainer.LogsOptions) (io.ReadCloser, error) {
+	rc, err := s.cli.ContainerLogs(ctx, id, opts)
+	if err != nil {
+		return nil, resourceError("read logs of", "container", id, err)
+	}
+	return rc, nil
+}
+
+// RunContainer pulls the image, creates the container and starts it. It
+// returns the new container ID.
+func (s *Service) RunContainer(ctx context.Context, spec ContainerSpec) (string, error) {
+	if spec.Config == nil || spec.Config.Image == "" {
+		return "", fmt.Errorf("run container: no image given")
+	}
+	if err := s.PullImage(ctx, spec.Config.Image); err != nil {
+		return "", err
+	}
+	hostConfig := spec.HostConfig
+	if hostConfig == nil {
+		hostConfig = &dockerContainer.HostConfig{}
+	}
+	resp, err := s.cli.ContainerCreate(ctx, spec.Config, hostConfig, spec.NetworkingConfig, nil, spec.Name)
+	if err != nil {
+		return "", fmt.Errorf("create container: %w", err)
+	}
+	if err := s.StartContainer(ctx, resp.ID); err != nil {
+		return resp.ID, err
+	}
+	return resp.ID, nil
+}
+
+// ListImages lists the images matching the Docker filter arguments f.
+func (s *Service) ListImages(ctx context.Context, f filters.Args) ([]Image, error) {
+	list, err := s.cli.ImageList(ctx, dockerImage.ListOptions{Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("list images: %w", err)
+	}
+	usage := s.containerUsage(ctx)
+	result := make([]Image, len(list))
+	for i, img := range list {
+		// The daemon only counts containers when asked to, which this
+		// client version cannot do.
+		if img.Containers < 0 && usage != nil {
+			img.Containers = int64(usage.images[img.ID])
+		}
+		result[i] = Image{
+			ID:         img.ID,
+			RepoTags:   img.RepoTags,
+			Size:       img.Size,
+			Created:    time.Unix(img.Created, 0),
+			Containers: img.Containers,
+			Labels:     img.Labels,
+		}
+	}
+	return result, nil
+}
+
+// PullImage pulls ref and waits for the pull to finish.
+func (s *Service) PullImage(ctx context.Context, ref string) error {
+	_, err := s.PullImageWithProgress(ctx, ref, PullOptions{}, nil)
+	return err
+}
+
+// RemoveImage force-removes an image.
+func (s *Service) RemoveImage(ctx context.Context, id string) error {
+	if _, err := s.cli.ImageRemove(ctx, id, dockerImage.RemoveOptions{Force: true}); err != nil {
+		return resourceError("remove", "image", id, err)
+	}
+	return nil
+}
+
+// ListVolumes lists the volumes matching the Docker filter arguments f.
+func (s *Service) ListVolumes(ctx context.Context, f filters.Args) ([]Volume, error) {
+	resp, err := s.cli.VolumeList(ctx, volume.ListOptions{Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("list volumes: %w", err)
+	}
+	usage := s.containerUsage(ctx)
+	result := make([]Volume, 0, len(resp.Volumes))
+	for _, v := range resp.Volumes {
+		if v == nil {
+			continue
+		}
+		result = append(result, volumeFromAPI(*v, usage))
+	}
+	return result, nil
+}
+
+func (s *Service) GetVolume(ctx context.Context, name string) (Volume, error) {
+	v, err := s.cli.VolumeInspect(ctx, name)
+	if err != nil {
+		return Volume{}, resourceError("get", "volume", name, err)
+	}
+	return volumeFromAPI(v, s.containerUsage(ctx)), nil
+}
+
+func (s *Service) CreateVolume(ctx context.Context, name string) (Volume, error) {
+	v, err := s.cli.VolumeCreate(ctx, volume.CreateOptions{Name: name})
+	if err != nil {
+		return Volume{}, fmt.Errorf("create volume %s: %w", name, err)
+	}
+	return volumeFromAPI(v, nil), nil
+}
+
+// RemoveVolume force-removes a volume.
+func (s *Service) RemoveVolume(ctx context.Context, name string) error {
+	if err := s.cli.VolumeRemove(ctx, name, true); err != nil {
+		return resourceError("remove", "volume", name, err)
+	}
+	return nil
+}
+
+// ListNetworks lists the networks matching the Docker filter arguments f.
+func (s *Service) ListNetworks(ctx context.Context, f filters.Args) ([]Network, error) {
+	list, err := s.cli.NetworkList(ctx, dockerNetwork.ListOptions{Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("list networks: %w", err)
+	}
+	usage := s.containerUsage(ctx)
+	result := make([]Network, len(list))
+	for i, n := range list {
+		result[i] = networkFromAPI(n, usage)
+	}
+	return result, nil
+}
+
+func (s *Service) GetNetwork(ctx context.Context, id string) (Network, error) {
+	n, err := s.cli.NetworkInspect(ctx, id, dockerNetwork.InspectOptions{})
+	if err != nil {
+		return Network{}, resourceError("get", "network", id, err)
+	}
+	return networkFromAPI(n, s.containerUsage(ctx)), nil
+}
+
+// CreateNetwork creates a network and returns its ID.
+func (s *Service) CreateNetwork(ctx context.Context, spec NetworkSpec) (string, error) {
+	resp, err := s.cli.NetworkCreate(ctx, spec.Name, dockerNetwork.CreateOptions{
+		Driver:  spec.Driver,
+		Options: spec.Options,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create network %s: %w", spec.Name, err)
+	}
+	return resp.ID, nil
+}
+
+func (s *Service) RemoveNetwork(ctx context.Context, id string) error {
+	if err := s.cli.NetworkRemove(ctx, id); err != nil {
+		return resourceError("remove", "network", id, err)
+	}
+	return nil
+}
+
+func containerFromSummary(c types.Container) Container {
+	name := ""
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	return Container{
+		ID:      c.ID,
+		Name:    name,
+		Image:   c.Image,
+		ImageID: c.ImageID,
+		Command: c.Command,
+		State:   c.State,
+		Status:  c.Status,
+		Created: time.Unix(c.Created, 0),
+		Ports:   c.Ports,
+		Labels:  c.Labels,
+	}
+}
+
+func volumeFromAPI(v volume.Volume, usage *containerUsage) Volume {
+	containers := -1
+	if usage != nil {
+		containers = usage.volumes[v.Name]
+	}
+	return Volume{
+		Name:       v.Name,
+		Driver:     v.Driver,
+		Mountpoint: v.Mountpoint,
+		Scope:      v.Scope,
+		CreatedAt:  v.CreatedAt,
+		Labels:     v.Labels,
+		Containers: containers,
+	}
+}
+
+func networkFromAPI(n dockerNetwork.Inspect, usage *containerUsage) Network {
+	containers := -1
+	if usage != nil {
+		containers = usage.networks[n.Name]
+	}
+	return Network{
+		ID:         n.ID,
+		Name:       n.Name,
+		Driver:     n.Driver,
+		Scope:      n.Scope,
+		Internal:   n.Internal,
+		Created:    n.Created,
+		Labels:     n.Labels,
+		Containers: containers,
+	}
+}
+
+// containerUsage counts the containers using each image (by ID), volume
+// and network (by name).
+type containerUsage struct {
+	images   map[string]int
+	volumes  map[string]int
+	networks map[string]int
+}
+
+// containerUsage lists all containers to count what they use. It returns
+// nil if they cannot be listed; the counts are then reported as unknown
+// rather than failing the listing they decorate.
+func (s *Service) containerUsage(ctx context.Context) *containerUsage {
+	list, err := s.cli.ContainerList(ctx, dockerContainer.ListOptions{All: true})
+	if err != nil {
+		return nil
+	}
+	u := &containerUsage{images: map[string]int{}, volumes: map[string]int{}, networks: map[string]int{}}
+	for _, c := range list {
+		u.images[c.ImageID]++
+		for _, m := range c.Mounts {
+			if m.Type == mount.TypeVolume && m.Name != "" {
+				u.volumes[m.Name]++
+			}
+		}
+		if c.NetworkSettings != nil {
+			for name := range c.NetworkSettings.Networks {
+				u.networks[name]++
+			}
+		}
+	}
+	return u
+}
+
+// resourceError wraps an API error for an action on a single resource,
+// turning the daemon's "no such ..." responses into a NotFoundError.
+func resourceError(action, kind, id string, err error) error {
+	if errdefs.IsNotFound(err) {
+		return &NotFoundError{Kind: kind, ID: id}
+	}
+	return fmt.Errorf("%s %s %s: %w", action, kind, shortID(id), err)
+}
+
+// shortID trims the digest algorithm prefix from a full hex ID and shortens
+// it to 12 characters. Names (such as volume names) are returned unchanged.
+func shortID(id string) string {
+	hex := id
+	if i := strings.IndexByte(hex, ':'); i >= 0 {
+		hex = hex[i+1:]
+	}
+	if len(hex) != 64 || strings.Trim(hex, "0123456789abcdef") != "" {
+		return id
+	}
+	return hex[:12]
+}
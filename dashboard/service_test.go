@@ -0,0 +1,153 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	dockerImage "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// fakeClient serves a fixed set of containers and images. Calls it does not
+// implement panic on the nil embedded client.
+type fakeClient struct {
+	client.APIClient
+	containers []types.Container
+	images     []dockerImage.Summary
+	started    []string
+}
+
+func (f *fakeClient) ContainerList(_ context.Context, opts dockerContainer.ListOptions) ([]types.Container, error) {
+	var list []types.Container
+	for _, c := range f.containers {
+		// Like the daemon, the id filter matches prefixes.
+		if ids := opts.Filters.Get("id"); len(ids) > 0 && !strings.HasPrefix(c.ID, ids[0]) {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+func (f *fakeClient) ContainerStart(_ context.Context, id string, _ dockerContainer.StartOptions) error {
+	for _, c := range f.containers {
+		if c.ID == id {
+			f.started = append(f.started, id)
+			return nil
+		}
+	}
+	return errdefs.NotFound(errors.New("No such container: " + id))
+}
+
+func (f *fakeClient) ContainerStop(context.Context, string, dockerContainer.StopOptions) error {
+	return errdefs.System(errors.New("daemon is shutting down"))
+}
+
+func (f *fakeClient) ImageList(context.Context, dockerImage.ListOptions) ([]dockerImage.Summary, error) {
+	return f.images, nil
+}
+
+var (
+	webID = strings.Repeat("a", 64)
+	dbID  = strings.Repeat("a", 12) + strings.Repeat("b", 52)
+)
+
+func newFakeService() (*Service, *fakeClient) {
+	cli := &fakeClient{
+		containers: []types.Container{
+			{ID: webID, Names: []string{"/web"}, Image: "nginx", ImageID: "sha256:nginx", State: "running", Created: 1700000000},
+			{ID: dbID, Names: []string{"/db"}, Image: "postgres", ImageID: "sha256:postgres", State: "exited"},
+		},
+		images: []dockerImage.Summary{
+			{ID: "sha256:nginx", RepoTags: []string{"nginx:latest"}, Containers: -1},
+			{ID: "sha256:unused", Containers: -1},
+		},
+	}
+	return NewService(cli), cli
+}
+
+func TestServiceListContainers(t *testing.T) {
+	svc, _ := newFakeService()
+	list, err := svc.ListContainers(context.Background(), filters.NewArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d containers, want 2", len(list))
+	}
+	web := list[0]
+	if web.Name != "web" || web.Image != "nginx" || web.State != "running" || web.Created.Unix() != 1700000000 {
+		t.Errorf("web = %+v", web)
+	}
+	if web.ShortID() != "aaaaaaaaaaaa" {
+		t.Errorf("ShortID = %q", web.ShortID())
+	}
+}
+
+func TestServiceGetContainer(t *testing.T) {
+	svc, _ := newFakeService()
+	// Both IDs share the web container's ID as a prefix; only an exact
+	// match counts.
+	c, err := svc.GetContainer(context.Background(), webID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "web" {
+		t.Errorf("got %q, want web", c.Name)
+	}
+
+	_, err = svc.GetContainer(context.Background(), strings.Repeat("c", 64))
+	var nf *NotFoundError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &nf) || nf.Kind != "container" {
+		t.Errorf("missing container: err = %v, want a container NotFoundError", err)
+	}
+}
+
+func TestServiceActionErrors(t *testing.T) {
+	svc, cli := newFakeService()
+	ctx := context.Background()
+
+	if err := svc.StartContainer(ctx, dbID); err != nil {
+		t.Fatal(err)
+	}
+	if len(cli.started) != 1 || cli.started[0] != dbID {
+		t.Errorf("started = %v", cli.started)
+	}
+
+	err := svc.StartContainer(ctx, strings.Repeat("c", 64))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("start of a removed container: err = %v, want ErrNotFound", err)
+	}
+	if want := "container cccccccccccc no longer exists"; err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+
+	err = svc.StopContainer(ctx, webID)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("stop: err = %v, want a plain failure", err)
+	}
+	if want := "stop container aaaaaaaaaaaa: daemon is shutting down"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
+
+func TestServiceListImagesCountsContainers(t *testing.T) {
+	svc, _ := newFakeService()
+	images, err := svc.ListImages(context.Background(), filters.NewArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, img := range images {
+		got[img.ID] = img.Containers
+	}
+	if got["sha256:nginx"] != 1 || got["sha256:unused"] != 0 {
+		t.Errorf("container counts = %v", got)
+	}
+}
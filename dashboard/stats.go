@@ -0,0 +1,245 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+)
+
+// StatsSample is one point of a container's resource usage, with counters
+// turned into per-second rates.
+type StatsSample struct {
+	Time time.Time
+
+	// CPUCores is the number of CPUs' worth of time used; 1.5 means one and
+	// a half cores were busy on average since the previous reading.
+	CPUCores float64
+	// CPUPercent is the `docker stats` figure: 100% per fully used core.
+	CPUPercent float64
+	// CPUHostPercent is usage as a share of all the host's CPUs (0-100).
+	CPUHostPercent float64
+	// CPULimitPercent is usage as a share of the container's CPU limit
+	// (0-100). Without a limit it equals CPUHostPercent.
+	CPULimitPercent float64
+
+	// MemoryUsage excludes the inactive page cache, like `docker stats`.
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+
+	NetRxRate float64 // bytes per second
+	NetTxRate float64 // bytes per second
+
+	BlockReadRate  float64 // bytes per second
+	BlockWriteRate float64 // bytes per second
+
+	PIDs uint64
+}
+
+// StatsCalculator turns a stream of raw stats into samples. Rates need two
+// readings, so the first sample after creation or Reset reports zero rates.
+type StatsCalculator struct {
+	// CPULimit is the container's CPU limit in cores (see CPULimitCores);
+	// zero means unlimited.
+	CPULimit float64
+
+	prev *types.StatsJSON
+}
+
+// Reset forgets the previous reading, e.g. after the stream was paused.
+func (c *StatsCalculator) Reset() {
+	c.prev = nil
+}
+
+// Next computes the sample for s and remembers it for the next rates.
+func (c *StatsCalculator) Next(s types.StatsJSON) StatsSample {
+	sample := StatsSample{
+		Time:        s.Read,
+		MemoryUsage: memoryUsage(s.MemoryStats),
+		MemoryLimit: s.MemoryStats.Limit,
+		PIDs:        s.PidsStats.Current,
+	}
+	c.fillCPU(&sample, s)
+	if sample.MemoryLimit > 0 {
+		sample.MemoryPercent = float64(sample.MemoryUsage) / float64(sample.MemoryLimit) * 100.0
+	}
+
+	if c.prev != nil {
+		if secs := s.Read.Sub(c.prev.Read).Seconds(); secs > 0 {
+			prevRx, prevTx := networkTotals(*c.prev)
+			rx, tx := networkTotals(s)
+			sample.NetRxRate = rate(prevRx, rx, secs)
+			sample.NetTxRate = rate(prevTx, tx, secs)
+
+			prevRead, prevWrite := blockTotals(*c.prev)
+			read, write := blockTotals(s)
+			sample.BlockReadRate = rate(prevRead, read, secs)
+			sample.BlockWriteRate = rate(prevWrite, write, secs)
+		}
+	}
+	c.prev = &s
+	return sample
+}
+
+func (c *StatsCalculator) fillCPU(sample *StatsSample, s types.StatsJSON) {
+	pre := s.PreCPUStats
+	// One-shot readings carry no precpu_stats; fall back to our own, and
+	// without either there is nothing to compare against.
+	if pre.SystemUsage == 0 && c.prev != nil {
+		pre = c.prev.CPUStats
+	}
+	if pre.SystemUsage == 0 {
+		return
+	}
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(pre.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(pre.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return
+	}
+
+	// cgroup v2 hosts leave percpu_usage empty, so prefer online_cpus.
+	onlineCPUs := float64(s.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if onlineCPUs == 0 {
+		return
+	}
+
+	share := cpuDelta / systemDelta
+	sample.CPUCores = share * onlineCPUs
+	sample.CPUPercent = sample.CPUCores * 100.0
+	sample.CPUHostPercent = share * 100.0
+	limit := c.CPULimit
+	if limit <= 0 || limit > onlineCPUs {
+		limit = onlineCPUs
+	}
+	sample.CPULimitPercent = sample.CPUCores / limit * 100.0
+}
+
+// CPULimitCores returns how many CPUs a container may use according to its
+// resources: --cpus (NanoCPUs), --cpu-quota/--cpu-period and --cpuset-cpus,
+// whichever is tightest. It returns 0 when none of them is set.
+func CPULimitCores(r dockerContainer.Resources) float64 {
+	limit := 0.0
+	tighten := func(cores float64) {
+		if cores > 0 && (limit == 0 || cores < limit) {
+			limit = cores
+		}
+	}
+	if r.NanoCPUs > 0 {
+		tighten(float64(r.NanoCPUs) / 1e9)
+	}
+	if r.CPUQuota > 0 {
+		period := r.CPUPeriod
+		if period <= 0 {
+			period = 100000 // kernel default, in microseconds
+		}
+		tighten(float64(r.CPUQuota) / float64(period))
+	}
+	if n := cpusetSize(r.CpusetCpus); n > 0 {
+		tighten(float64(n))
+	}
+	return limit
+}
+
+// cpusetSize counts the CPUs in a cpuset list such as "0-3,6".
+func cpusetSize(cpuset string) int {
+	n := 0
+	for _, part := range strings.Split(cpuset, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			if _, err := strconv.Atoi(lo); err == nil {
+				n++
+			}
+			continue
+		}
+		from, err1 := strconv.Atoi(lo)
+		to, err2 := strconv.Atoi(hi)
+		if err1 == nil && err2 == nil && to >= from {
+			n += to - from + 1
+		}
+	}
+	return n
+}
+
+// memoryUsage mirrors `docker stats`: the inactive file cache can be
+// reclaimed at any time, so it does not count as used.
+func memoryUsage(m dockerContainer.MemoryStats) uint64 {
+	// cgroup v1
+	if v, ok := m.Stats["total_inactive_file"]; ok && v < m.Usage {
+		return m.Usage - v
+	}
+	// cgroup v2
+	if v := m.Stats["inactive_file"]; v < m.Usage {
+		return m.Usage - v
+	}
+	// Windows reports the private working set instead.
+	if m.Usage == 0 {
+		return m.PrivateWorkingSet
+	}
+	return m.Usage
+}
+
+func networkTotals(s types.StatsJSON) (rx, tx uint64) {
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+func blockTotals(s types.StatsJSON) (read, write uint64) {
+	// cgroup v1 reports "Read"/"Write", cgroup v2 "read"/"write".
+	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+		switch {
+		case strings.EqualFold(e.Op, "read"):
+			read += e.Value
+		case strings.EqualFold(e.Op, "write"):
+			write += e.Value
+		}
+	}
+	return read, write
+}
+
+// rate is the per-second increase of a counter. A counter that went down
+// (the container restarted) yields zero rather than a huge negative rate.
+func rate(prev, cur uint64, secs float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / secs
+}
+
+// StreamStats follows a container's stats until ctx is cancelled or the
+// container stops, calling handle for each reading (about once a second).
+func (s *Service) StreamStats(ctx context.Context, id string, handle func(types.StatsJSON)) error {
+	resp, err := s.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		return resourceError("stream stats of", "container", id, err)
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var stats types.StatsJSON
+		if err := dec.Decode(&stats); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("decode stats %s: %w", shortID(id), err)
+		}
+		handle(stats)
+	}
+}
@@ -0,0 +1,141 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+)
+
+func loadStats(t *testing.T, name string) types.StatsJSON {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s types.StatsJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return s
+}
+
+func assertClose(t *testing.T, what string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", what, got, want)
+	}
+}
+
+func TestStatsCalculatorCgroupV1(t *testing.T) {
+	var calc StatsCalculator
+	s := calc.Next(loadStats(t, "stats_cgroupv1.json"))
+
+	// 0.2s of CPU in a 1s window on a 4 CPU host.
+	assertClose(t, "CPUCores", s.CPUCores, 0.2)
+	assertClose(t, "CPUPercent", s.CPUPercent, 20)
+	assertClose(t, "CPUHostPercent", s.CPUHostPercent, 5)
+	assertClose(t, "CPULimitPercent", s.CPULimitPercent, 5)
+
+	// 100MiB used minus 20MiB total_inactive_file.
+	if s.MemoryUsage != 80<<20 {
+		t.Errorf("MemoryUsage = %d, want %d", s.MemoryUsage, 80<<20)
+	}
+	assertClose(t, "MemoryPercent", s.MemoryPercent, 15.625)
+	if s.PIDs != 7 {
+		t.Errorf("PIDs = %d, want 7", s.PIDs)
+	}
+	// First reading: no rates yet.
+	if s.NetRxRate != 0 || s.BlockReadRate != 0 {
+		t.Errorf("first sample has rates: net %v, block %v", s.NetRxRate, s.BlockReadRate)
+	}
+}
+
+func TestStatsCalculatorPercpuFallback(t *testing.T) {
+	// Older daemons don't send online_cpus; count percpu_usage instead.
+	raw := loadStats(t, "stats_cgroupv1.json")
+	raw.CPUStats.OnlineCPUs = 0
+	var calc StatsCalculator
+	assertClose(t, "CPUPercent", calc.Next(raw).CPUPercent, 20)
+}
+
+func TestStatsCalculatorCgroupV2(t *testing.T) {
+	calc := StatsCalculator{CPULimit: 2}
+	first := calc.Next(loadStats(t, "stats_cgroupv2_1.json"))
+
+	// percpu_usage is empty on cgroup v2; online_cpus must be used.
+	// 1s of CPU in a 1s window on an 8 CPU host, limited to 2 CPUs.
+	assertClose(t, "CPUCores", first.CPUCores, 1)
+	assertClose(t, "CPUPercent", first.CPUPercent, 100)
+	assertClose(t, "CPUHostPercent", first.CPUHostPercent, 12.5)
+	assertClose(t, "CPULimitPercent", first.CPULimitPercent, 50)
+
+	// 200MiB used minus 50MiB inactive_file.
+	if first.MemoryUsage != 150<<20 {
+		t.Errorf("MemoryUsage = %d, want %d", first.MemoryUsage, 150<<20)
+	}
+	assertClose(t, "MemoryPercent", first.MemoryPercent, 14.6484375)
+
+	second := calc.Next(loadStats(t, "stats_cgroupv2_2.json"))
+	assertClose(t, "CPULimitPercent", second.CPULimitPercent, 50)
+	// Counters grew over 2 seconds, summed over eth0 and eth1.
+	assertClose(t, "NetRxRate", second.NetRxRate, 2<<20)
+	assertClose(t, "NetTxRate", second.NetTxRate, 512<<10)
+	assertClose(t, "BlockReadRate", second.BlockReadRate, 1<<20)
+	assertClose(t, "BlockWriteRate", second.BlockWriteRate, 2<<20)
+	if second.PIDs != 25 {
+		t.Errorf("PIDs = %d, want 25", second.PIDs)
+	}
+}
+
+func TestStatsCalculatorOneShot(t *testing.T) {
+	// One-shot readings have empty precpu_stats.
+	var calc StatsCalculator
+	alone := calc.Next(loadStats(t, "stats_oneshot_cgroupv2.json"))
+	if alone.CPUPercent != 0 {
+		t.Errorf("CPUPercent without a previous reading = %v, want 0", alone.CPUPercent)
+	}
+
+	calc.Reset()
+	calc.Next(loadStats(t, "stats_cgroupv2_1.json"))
+	s := calc.Next(loadStats(t, "stats_oneshot_cgroupv2.json"))
+	assertClose(t, "CPUPercent", s.CPUPercent, 100)
+}
+
+func TestStatsCalculatorCounterReset(t *testing.T) {
+	// A restarted container starts its counters from zero again.
+	var calc StatsCalculator
+	calc.Next(loadStats(t, "stats_cgroupv2_2.json"))
+	restarted := loadStats(t, "stats_cgroupv2_1.json")
+	restarted.Read = restarted.Read.Add(10 * time.Second)
+	s := calc.Next(restarted)
+	if s.NetRxRate != 0 || s.BlockWriteRate != 0 {
+		t.Errorf("rates after counter reset: net %v, block %v", s.NetRxRate, s.BlockWriteRate)
+	}
+}
+
+func TestCPULimitCores(t *testing.T) {
+	tests := []struct {
+		name string
+		res  dockerContainer.Resources
+		want float64
+	}{
+		{"unlimited", dockerContainer.Resources{}, 0},
+		{"cpus", dockerContainer.Resources{NanoCPUs: 1500000000}, 1.5},
+		{"quota", dockerContainer.Resources{CPUQuota: 50000, CPUPeriod: 100000}, 0.5},
+		{"quota default period", dockerContainer.Resources{CPUQuota: 200000}, 2},
+		{"cpuset", dockerContainer.Resources{CpusetCpus: "0-3,6"}, 5},
+		{"tightest wins", dockerContainer.Resources{NanoCPUs: 3000000000, CpusetCpus: "0,1"}, 2},
+		{"bad cpuset ignored", dockerContainer.Resources{CpusetCpus: "x-y"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "CPULimitCores", CPULimitCores(tt.res), tt.want)
+		})
+	}
+}
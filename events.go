@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/docker/docker/api/types/events"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Live Updates (Docker Events)
+// =============================================================================
+
+// eventHandler handles an event from the stream of svc, the service the
+// watcher was started on, which it must use rather than dockerService: the
+// global may already point at a new client.
+type eventHandler func(svc dashboard.DockerService, ev dashboard.Event)
+
+var (
+	// Per-resource handlers registered by the tabs with onEvent.
+	eventHandlers = map[events.Type][]eventHandler{}
+
+	// Cancels the running event watcher, if any, and is closed once it
+	// has stopped.
+	stopEventWatcher context.CancelFunc
+	eventWatcherDone chan struct{}
+)
+
+// startEventWatcher (re)starts the background subscription to the Docker
+// event stream on the current dockerService. Call it again whenever the
+// client is replaced. An old watcher is stopped, and waited for, first, so
+// no handler still runs against the old client.
+func startEventWatcher() {
+	if stopEventWatcher != nil {
+		stopEventWatcher()
+		<-eventWatcherDone
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	stopEventWatcher, eventWatcherDone = cancel, done
+	svc := dockerService
+	go func() {
+		defer close(done)
+		err := svc.WatchEvents(ctx, func(ev dashboard.Event) { dispatchEvent(svc, ev) }, func(err error) {
+			log.Println("Docker events stream lost, reconnecting:", err)
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Println("Error watching Docker events:", err)
+		}
+	}()
+}
+
+// onEvent registers handle for events about resources of type t. Every
+// handler also receives the resync events sent after a reconnect.
+func onEvent(t events.Type, handle eventHandler) {
+	eventHandlers[t] = append(eventHandlers[t], handle)
+}
+
+func dispatchEvent(svc dashboard.DockerService, ev dashboard.Event) {
+	if ev.Resync {
+		for _, handlers := range eventHandlers {
+			for _, handle := range handlers {
+				handle(svc, ev)
+			}
+		}
+		return
+	}
+	for _, handle := range eventHandlers[ev.Type] {
+		handle(svc, ev)
+	}
+}
+
+// The apply functions patch single rows when the tab lists everything.
+// With filters set, a changed resource may start or stop matching them, so
+// the list is reloaded instead. They run on the watcher goroutine and only
+// change a tab through its table, which guards its state.
+
+func applyContainerEvent(svc dashboard.DockerService, ev dashboard.Event, list *resourceTable[dashboard.Container]) {
+	if ev.Resync || containerFilters.get().Len() > 0 {
+		updateContainerList(svc, list)
+		return
+	}
+	if ev.Action == events.ActionDestroy {
+		list.remove(ev.ID)
+		return
+	}
+	c, err := svc.GetContainer(context.Background(), ev.ID)
+	switch {
+	case errors.Is(err, dashboard.ErrNotFound):
+		list.remove(ev.ID)
+	case err != nil:
+		log.Println("Error refreshing container:", err)
+	default:
+		list.upsert(c)
+	}
+}
+
+func applyImageEvent(svc dashboard.DockerService, ev dashboard.Event, list *resourceTable[dashboard.Image]) {
+	// Pull, tag and load events name the image by reference rather than ID,
+	// so only deletions can be applied without reloading.
+	if ev.Resync || ev.Action != events.ActionDelete {
+		updateImagesList(svc, list)
+		return
+	}
+	list.remove(ev.ID)
+}
+
+func applyVolumeEvent(svc dashboard.DockerService, ev dashboard.Event, list *resourceTable[dashboard.Volume]) {
+	if ev.Resync || volumeFilters.get().Len() > 0 {
+		updateVolumesList(svc, list)
+		return
+	}
+	if ev.Action == events.ActionDestroy {
+		list.remove(ev.ID)
+		return
+	}
+	v, err := svc.GetVolume(context.Background(), ev.ID)
+	if err != nil {
+		log.Println("Error refreshing volume:", err)
+		return
+	}
+	list.upsert(v)
+}
+
+func applyNetworkEvent(svc dashboard.DockerService, ev dashboard.Event, list *resourceTable[dashboard.Network]) {
+	if ev.Resync || networkFilters.get().Len() > 0 {
+		updateNetworksList(svc, list)
+		return
+	}
+	if ev.Action == events.ActionDestroy {
+		list.remove(ev.ID)
+		return
+	}
+	n, err := svc.GetNetwork(context.Background(), ev.ID)
+	if err != nil {
+		log.Println("Error refreshing network:", err)
+		return
+	}
+	list.upsert(n)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+
+	"sprint/dashboard"
+)
+
+func newTestContainerList() *resourceTable[dashboard.Container] {
+	return newResourceTable("containers",
+		func(c dashboard.Container) string { return c.ID },
+		containerColumns(newContainerUsage()), defaultContainerColumns)
+}
+
+func containerNames(list *resourceTable[dashboard.Container]) []string {
+	var names []string
+	for _, c := range list.snapshot() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestApplyContainerEvent(t *testing.T) {
+	svc := &fakeService{containers: []dashboard.Container{{ID: "web", Name: "web"}, {ID: "db", Name: "db"}}}
+	list := newTestContainerList()
+	if err := updateContainerList(svc, list); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new container is fetched on its own and listed first.
+	svc.containers = append(svc.containers, dashboard.Container{ID: "cache", Name: "cache"})
+	applyContainerEvent(svc, dashboard.Event{Type: events.ContainerEventType, Action: events.ActionCreate, ID: "cache"}, list)
+	if got := containerNames(list); len(got) != 3 || got[0] != "cache" {
+		t.Errorf("after create: %v", got)
+	}
+
+	// A changed container is replaced in place.
+	svc.containers[0].State = "exited"
+	applyContainerEvent(svc, dashboard.Event{Type: events.ContainerEventType, Action: events.ActionDie, ID: "web"}, list)
+	if c, ok := list.lookup("web"); !ok || c.State != "exited" {
+		t.Errorf("after die: web = %+v, %v", c, ok)
+	}
+
+	// Destroyed containers, and ones gone by the time they are fetched,
+	// are dropped.
+	applyContainerEvent(svc, dashboard.Event{Type: events.ContainerEventType, Action: events.ActionDestroy, ID: "db"}, list)
+	svc.containers = svc.containers[2:]
+	applyContainerEvent(svc, dashboard.Event{Type: events.ContainerEventType, Action: events.ActionStop, ID: "web"}, list)
+	if got := containerNames(list); len(got) != 1 || got[0] != "cache" {
+		t.Errorf("after destroy: %v", got)
+	}
+	if svc.lists != 1 {
+		t.Errorf("list reloaded %d times, want once", svc.lists)
+	}
+}
+
+func TestApplyContainerEventReloads(t *testing.T) {
+	svc := &fakeService{containers: []dashboard.Container{{ID: "web", Name: "web"}}}
+	list := newTestContainerList()
+
+	// A resync may follow missed events, so the whole list is reloaded.
+	applyContainerEvent(svc, dashboard.Event{Resync: true}, list)
+	if svc.lists != 1 || len(list.snapshot()) != 1 {
+		t.Errorf("resync: %d reloads, %d rows", svc.lists, len(list.snapshot()))
+	}
+
+	// With a filter set, a changed container may stop matching it.
+	containerFilters.set(filters.NewArgs(filters.Arg("status", "running")))
+	defer containerFilters.set(filters.NewArgs())
+	applyContainerEvent(svc, dashboard.Event{Type: events.ContainerEventType, Action: events.ActionStart, ID: "web"}, list)
+	if svc.lists != 2 {
+		t.Errorf("filtered: %d reloads, want 2", svc.lists)
+	}
+}
+
+// watchService sends one event when watched, then waits for cancellation.
+type watchService struct {
+	fakeService
+	name    string
+	started func()
+	mu      sync.Mutex
+	stopped bool
+}
+
+func (w *watchService) WatchEvents(ctx context.Context, handle func(dashboard.Event), _ func(error)) error {
+	if w.started != nil {
+		w.started()
+	}
+	handle(dashboard.Event{Type: events.VolumeEventType, ID: w.name})
+	<-ctx.Done()
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	return ctx.Err()
+}
+
+func (w *watchService) isStopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stopped
+}
+
+func TestStartEventWatcherReplacesWatcher(t *testing.T) {
+	saved := eventHandlers
+	defer func() { eventHandlers = saved }()
+	eventHandlers = map[events.Type][]eventHandler{}
+	got := make(chan string, 2)
+	onEvent(events.VolumeEventType, func(svc dashboard.DockerService, ev dashboard.Event) {
+		// Handlers get the service whose stream the event came from.
+		if svc.(*watchService).name != ev.ID {
+			t.Errorf("event from %s handled with %s", ev.ID, svc.(*watchService).name)
+		}
+		got <- ev.ID
+	})
+
+	old := &watchService{name: "old"}
+	dockerService = old
+	startEventWatcher()
+	<-got
+
+	// The old watcher has stopped before the new one starts.
+	var stoppedFirst bool
+	dockerService = &watchService{name: "new", started: func() { stoppedFirst = old.isStopped() }}
+	startEventWatcher()
+	if id := <-got; id != "new" {
+		t.Errorf("event from %s, want new", id)
+	}
+	if !stoppedFirst {
+		t.Error("the new watcher started before the old one stopped")
+	}
+	stopEventWatcher()
+	<-eventWatcherDone
+	stopEventWatcher = nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Exec Terminal
+// =============================================================================
+
+var execShells = []struct {
+	label string
+	cmd   []string
+}{
+	{"sh", []string{"/bin/sh"}},
+	{"bash", []string{"/bin/bash"}},
+}
+
+const execCustomShell = "Custom command"
+
+// showExecDialog asks for the command, user and working directory, then
+// opens a terminal running it in the container.
+func showExecDialog(id string) {
+	if id == "" {
+		return
+	}
+	info, err := dockerService.InspectContainer(context.Background(), id)
+	if err != nil {
+		showActionError("Error starting exec:", err)
+		return
+	}
+	name := trimName(info.Name)
+	if info.State == nil || !info.State.Running {
+		dialog.ShowInformation("Exec", name+" is not running", mainWindow)
+		return
+	}
+
+	options := make([]string, 0, len(execShells)+1)
+	for _, s := range execShells {
+		options = append(options, s.label)
+	}
+	options = append(options, execCustomShell)
+	customEntry := widget.NewEntry()
+	customEntry.SetPlaceHolder("e.g. /bin/ash -l")
+	customEntry.Disable()
+	shellSelect := widget.NewSelect(options, func(s string) {
+		if s == execCustomShell {
+			customEntry.Enable()
+		} else {
+			customEntry.Disable()
+		}
+	})
+	shellSelect.SetSelected(options[0])
+	userEntry := widget.NewEntry()
+	userEntry.SetPlaceHolder("container default")
+	workdirEntry := widget.NewEntry()
+	workdirEntry.SetPlaceHolder("container default")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Shell", shellSelect),
+		widget.NewFormItem("Command", customEntry),
+		widget.NewFormItem("User", userEntry),
+		widget.NewFormItem("Working dir", workdirEntry),
+	}
+	d := dialog.NewForm("Exec in "+name, "Open", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		var cmd []string
+		for _, s := range execShells {
+			if s.label == shellSelect.Selected {
+				cmd = s.cmd
+			}
+		}
+		if shellSelect.Selected == execCustomShell {
+			cmd, err = dashboard.SplitCommandLine(customEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			if len(cmd) == 0 {
+				dialog.ShowError(fmt.Errorf("enter a command to run"), mainWindow)
+				return
+			}
+		}
+		openExecTerminal(id, name, dashboard.ExecOptions{
+			Cmd:        cmd,
+			User:       strings.TrimSpace(userEntry.Text),
+			WorkingDir: strings.TrimSpace(workdirEntry.Text),
+		})
+	}, mainWindow)
+	d.Resize(fyne.NewSize(450, 300))
+	d.Show()
+}
+
+func openExecTerminal(id, name string, opts dashboard.ExecOptions) {
+	term := newTerminal()
+	rows, cols := term.Dimensions()
+	opts.Rows, opts.Cols = uint(rows), uint(cols)
+	ctx, cancel := context.WithCancel(context.Background())
+	sess, err := dockerService.Exec(ctx, id, opts)
+	if err != nil {
+		cancel()
+		term.Close()
+		log.Println("Error starting exec:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+
+	win := appInstance.NewWindow(fmt.Sprintf("Exec: %s (%s)", name, strings.Join(opts.Cmd, " ")))
+	status := widget.NewLabel("Connected")
+	term.SetInput(sess)
+	term.SetOnResize(func(rows, cols int) {
+		if err := sess.Resize(ctx, uint(rows), uint(cols)); err != nil && ctx.Err() == nil {
+			log.Println("Error resizing exec terminal:", err)
+		}
+	})
+	win.SetContent(container.NewBorder(nil, status, nil, nil, term))
+	win.Resize(fyne.NewSize(900, 550))
+	win.SetOnClosed(func() {
+		cancel()
+		sess.Close()
+		term.Close()
+	})
+	win.Show()
+	win.Canvas().Focus(term)
+	// The window may have been laid out before the resize callback was set.
+	rows, cols = term.Dimensions()
+	sess.Resize(ctx, uint(rows), uint(cols))
+
+	go func() {
+		err := sess.Copy(term, term)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			status.SetText("Disconnected: " + err.Error())
+			return
+		}
+		code, err := sess.ExitCode(ctx)
+		if err != nil {
+			status.SetText("Session ended")
+			return
+		}
+		status.SetText(fmt.Sprintf("Process exited with code %d", code))
+	}()
+}
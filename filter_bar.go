@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types/filters"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Server-side Filter Bars
+// =============================================================================
+
+// filterField is a Docker filter offered by a tab's filter bar.
+type filterField struct {
+	key   string // Docker filter name, e.g. "status"
+	label string
+	// options are suggested values; nil means free text only.
+	options []string
+	// multi splits the text on commas into several values, which Docker
+	// requires all to match, e.g. two labels.
+	multi bool
+}
+
+var containerFilterFields = []filterField{
+	{key: "status", label: "Status", options: []string{"created", "restarting", "running", "removing", "paused", "exited", "dead"}},
+	{key: "name", label: "Name"},
+	{key: "label", label: "Label (key or key=value)", multi: true},
+	{key: "ancestor", label: "Image (ancestor)"},
+	{key: "health", label: "Health", options: []string{"starting", "healthy", "unhealthy", "none"}},
+	{key: dashboard.ExitedWithinFilter, label: "Exited within (e.g. 24h)", options: []string{"1h", "24h", "168h"}},
+}
+
+var imageFilterFields = []filterField{
+	{key: "reference", label: "Reference (e.g. nginx:*)"},
+	{key: "label", label: "Label (key or key=value)", multi: true},
+	{key: "dangling", label: "Dangling", options: []string{"true", "false"}},
+	{key: "until", label: "Created before (e.g. 24h)", options: []string{"24h", "168h", "720h"}},
+}
+
+var volumeFilterFields = []filterField{
+	{key: "name", label: "Name"},
+	{key: "driver", label: "Driver", options: []string{"local"}},
+	{key: "label", label: "Label (key or key=value)", multi: true},
+	{key: "dangling", label: "Dangling (unused)", options: []string{"true", "false"}},
+}
+
+var networkFilterFields = []filterField{
+	{key: "name", label: "Name"},
+	{key: "driver", label: "Driver", options: []string{"bridge", "host", "overlay", "macvlan", "ipvlan", "null"}},
+	{key: "scope", label: "Scope", options: []string{"local", "swarm", "global"}},
+	{key: "type", label: "Type", options: []string{"custom", "builtin"}},
+	{key: "label", label: "Label (key or key=value)", multi: true},
+	{key: "dangling", label: "Dangling (unused)", options: []string{"true", "false"}},
+}
+
+// filterArgs holds the Docker filters a tab lists its resources with. The
+// filter bar sets them on the UI goroutine while the event watcher reads
+// them, so they are only used through get and set.
+type filterArgs struct {
+	mu   sync.Mutex
+	args filters.Args
+}
+
+func newFilterArgs() *filterArgs {
+	return &filterArgs{args: filters.NewArgs()}
+}
+
+// get returns a copy of the filters.
+func (f *filterArgs) get() filters.Args {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.args.Clone()
+}
+
+func (f *filterArgs) set(args filters.Args) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.args = args
+}
+
+// filterBar edits the Docker filters a tab lists its resources with, and
+// keeps named presets of them in preferences under "filters.<kind>".
+type filterBar struct {
+	kind   string
+	fields []filterField
+	inputs []*widget.Entry
+	// args receives the filters on apply; load then relists the tab and
+	// reports errors such as an unknown filter value.
+	args *filterArgs
+	load func() error
+
+	presets     []dashboard.FilterPreset
+	presetList  *widget.Select
+	item        *widget.AccordionItem
+	accordion   *widget.Accordion
+	applyingSet bool
+}
+
+func newFilterBar(kind string, fields []filterField, args *filterArgs, load func() error) *filterBar {
+	b := &filterBar{kind: kind, fields: fields, args: args, load: load}
+	cells := make([]fyne.CanvasObject, len(fields))
+	for i, f := range fields {
+		var entry *widget.Entry
+		if f.options != nil {
+			se := widget.NewSelectEntry(f.options)
+			entry, cells[i] = &se.Entry, se
+		} else {
+			entry = widget.NewEntry()
+			cells[i] = entry
+		}
+		entry.SetPlaceHolder(f.label)
+		entry.OnSubmitted = func(string) { b.apply() }
+		b.inputs = append(b.inputs, entry)
+	}
+
+	b.presets = loadFilterPresets(kind)
+	b.presetList = widget.NewSelect(presetNames(b.presets), func(name string) {
+		if b.applyingSet || name == "" {
+			return
+		}
+		if i := slices.IndexFunc(b.presets, func(p dashboard.FilterPreset) bool { return p.Name == name }); i >= 0 {
+			b.setArgs(b.presets[i].Filters)
+			b.apply()
+		}
+	})
+	b.presetList.PlaceHolder = "Presets"
+	applyBtn := widget.NewButton("Apply", b.apply)
+	clearBtn := widget.NewButton("Clear", func() {
+		b.setArgs(filters.NewArgs())
+		b.apply()
+	})
+	saveBtn := widget.NewButton("Save Preset...", b.showSavePreset)
+	deleteBtn := widget.NewButton("Delete Preset", b.deletePreset)
+
+	grid := container.NewGridWithColumns((len(fields)+1)/2, cells...)
+	buttons := container.NewHBox(applyBtn, clearBtn, widget.NewSeparator(), b.presetList, saveBtn, deleteBtn)
+	b.item = widget.NewAccordionItem("", container.NewVBox(grid, buttons))
+	b.accordion = widget.NewAccordion(b.item)
+	b.updateTitle()
+	return b
+}
+
+// current builds the filters from the inputs.
+func (b *filterBar) current() filters.Args {
+	args := filters.NewArgs()
+	for i, f := range b.fields {
+		values := []string{b.inputs[i].Text}
+		if f.multi {
+			values = strings.Split(b.inputs[i].Text, ",")
+		}
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				args.Add(f.key, v)
+			}
+		}
+	}
+	return args
+}
+
+// setArgs fills the inputs from args. Keys the bar has no field for are
+// ignored.
+func (b *filterBar) setArgs(args filters.Args) {
+	for i, f := range b.fields {
+		b.inputs[i].SetText(strings.Join(args.Get(f.key), ", "))
+	}
+}
+
+// apply lists the tab's resources with the filters entered.
+func (b *filterBar) apply() {
+	b.args.set(b.current())
+	b.updateTitle()
+	if err := b.load(); err != nil {
+		dialog.ShowError(err, mainWindow)
+	}
+}
+
+func (b *filterBar) updateTitle() {
+	b.item.Title = "Docker Filters"
+	if n := b.args.get().Len(); n > 0 {
+		b.item.Title = fmt.Sprintf("Docker Filters (%d active)", n)
+	}
+	b.accordion.Refresh()
+}
+
+func (b *filterBar) showSavePreset() {
+	name := widget.NewEntry()
+	name.SetPlaceHolder("e.g. Exited in last day")
+	if selected := b.presetList.Selected; selected != "" {
+		name.SetText(selected)
+	}
+	dialog.ShowForm("Save Filter Preset", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+	}, func(ok bool) {
+		preset := dashboard.FilterPreset{Name: strings.TrimSpace(name.Text), Filters: b.current()}
+		if !ok || preset.Name == "" {
+			return
+		}
+		// Saving under an existing name replaces that preset.
+		if i := slices.IndexFunc(b.presets, func(p dashboard.FilterPreset) bool { return p.Name == preset.Name }); i >= 0 {
+			b.presets[i] = preset
+		} else {
+			b.presets = append(b.presets, preset)
+		}
+		b.savePresets(preset.Name)
+	}, mainWindow)
+}
+
+func (b *filterBar) deletePreset() {
+	name := b.presetList.Selected
+	if name == "" {
+		return
+	}
+	dialog.ShowConfirm("Delete Preset", fmt.Sprintf("Delete the filter preset %q?", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		b.presets = slices.DeleteFunc(b.presets, func(p dashboard.FilterPreset) bool { return p.Name == name })
+		b.savePresets("")
+	}, mainWindow)
+}
+
+// savePresets stores the presets and shows selected in the preset list
+// without applying it again.
+func (b *filterBar) savePresets(selected string) {
+	if err := saveFilterPresets(b.kind, b.presets); err != nil {
+		log.Println("Error saving filter presets:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	b.applyingSet = true
+	b.presetList.Options = presetNames(b.presets)
+	if selected == "" {
+		b.presetList.ClearSelected()
+	} else {
+		b.presetList.SetSelected(selected)
+	}
+	b.applyingSet = false
+	b.presetList.Refresh()
+}
+
+func filterPresetsPref(kind string) string {
+	return "filters." + kind + ".presets"
+}
+
+// loadFilterPresets returns the saved presets of a tab, or its defaults on
+// first use.
+func loadFilterPresets(kind string) []dashboard.FilterPreset {
+	raw := appInstance.Preferences().String(filterPresetsPref(kind))
+	if raw == "" {
+		return dashboard.DefaultFilterPresets(kind)
+	}
+	var presets []dashboard.FilterPreset
+	if err := json.Unmarshal([]byte(raw), &presets); err != nil {
+		log.Println("Error loading filter presets:", err)
+		return dashboard.DefaultFilterPresets(kind)
+	}
+	return presets
+}
+
+func saveFilterPresets(kind string, presets []dashboard.FilterPreset) error {
+	raw, err := json.Marshal(presets)
+	if err != nil {
+		return err
+	}
+	appInstance.Preferences().SetString(filterPresetsPref(kind), string(raw))
+	return nil
+}
+
+func presetNames(presets []dashboard.FilterPreset) []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.Name
+	}
+	return names
+}
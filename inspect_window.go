@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Inspect Viewer
+// =============================================================================
+
+// showInspectWindow shows the full inspect output of a container, image,
+// volume or network as a searchable tree and as raw JSON.
+func showInspectWindow(kind, id string) {
+	if id == "" {
+		return
+	}
+	raw, err := dockerService.InspectRaw(context.Background(), kind, id)
+	if err != nil {
+		showActionError("Error inspecting "+kind+":", err)
+		return
+	}
+	tree, err := dashboard.ParseJSONTree(raw)
+	if err != nil {
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	name := strings.TrimPrefix(tree.PlainValue(".Name"), "/")
+	if kind == "image" {
+		name = tree.PlainValue(".RepoTags[0]")
+	}
+	if name == "" {
+		name = id
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, raw, "", "  "); err != nil {
+		pretty.Write(raw)
+	}
+
+	title := strings.ToUpper(kind[:1]) + kind[1:]
+	win := appInstance.NewWindow(fmt.Sprintf("Inspect %s: %s", title, name))
+	treeView := newInspectTree(win, tree)
+
+	rawGrid := widget.NewTextGridFromString(pretty.String())
+	saveBtn := widget.NewButton("Save...", func() {
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if w == nil {
+				return
+			}
+			_, err = w.Write(pretty.Bytes())
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName(safeFileName(kind+"-"+name) + ".json")
+		save.Show()
+	})
+	copyBtn := widget.NewButton("Copy All", func() {
+		win.Clipboard().SetContent(pretty.String())
+	})
+	rawView := container.NewBorder(container.NewHBox(saveBtn, copyBtn), nil, nil, nil, container.NewScroll(rawGrid))
+
+	win.SetContent(container.NewAppTabs(
+		container.NewTabItem("Tree", treeView),
+		container.NewTabItem("Raw JSON", rawView),
+	))
+	win.Resize(fyne.NewSize(900, 700))
+	win.Show()
+}
+
+// newInspectTree builds the tree tab: a collapsible view of the document with
+// search and buttons copying the selected node's path or value.
+func newInspectTree(win fyne.Window, doc *dashboard.JSONTree) fyne.CanvasObject {
+	var selected string
+	tree := widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			if n := doc.Node(uid); n != nil {
+				return n.Children
+			}
+			return nil
+		},
+		func(uid widget.TreeNodeID) bool {
+			n := doc.Node(uid)
+			return n != nil && n.Kind != dashboard.JSONScalar
+		},
+		func(branch bool) fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(uid widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(inspectNodeLabel(doc.Node(uid)))
+		},
+	)
+	pathLabel := widget.NewLabel("")
+	pathLabel.Truncation = fyne.TextTruncateEllipsis
+	tree.OnSelected = func(uid widget.TreeNodeID) {
+		selected = uid
+		pathLabel.SetText(uid)
+	}
+	tree.OpenBranch("")
+
+	copyPathBtn := widget.NewButton("Copy Path", func() {
+		if selected != "" {
+			win.Clipboard().SetContent(selected)
+		}
+	})
+	copyValueBtn := widget.NewButton("Copy Value", func() {
+		if selected != "" {
+			win.Clipboard().SetContent(doc.PlainValue(selected))
+		}
+	})
+	expandBtn := widget.NewButton("Expand All", func() { tree.OpenAllBranches() })
+	collapseBtn := widget.NewButton("Collapse All", func() { tree.CloseAllBranches() })
+
+	// Search opens the branches leading to every match and steps through
+	// them in document order.
+	var matches []string
+	current := -1
+	matchLabel := widget.NewLabel("")
+	show := func(i int) {
+		current = i
+		path := matches[i]
+		for _, ancestor := range doc.Ancestors(path) {
+			tree.OpenBranch(ancestor)
+		}
+		tree.Select(path)
+		tree.ScrollTo(path)
+		matchLabel.SetText(fmt.Sprintf("%d / %d", i+1, len(matches)))
+	}
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search keys and values")
+	searchEntry.OnChanged = func(query string) {
+		matches = doc.Search(query)
+		current = -1
+		switch {
+		case query == "":
+			matchLabel.SetText("")
+		case len(matches) == 0:
+			matchLabel.SetText("No matches")
+		default:
+			show(0)
+		}
+	}
+	step := func(dir int) {
+		if len(matches) > 0 {
+			show((current + dir + len(matches)) % len(matches))
+		}
+	}
+	searchEntry.OnSubmitted = func(string) { step(1) }
+	prevBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { step(-1) })
+	nextBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { step(1) })
+
+	top := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(prevBtn, nextBtn, matchLabel), searchEntry),
+		container.NewHBox(expandBtn, collapseBtn, copyPathBtn, copyValueBtn),
+	)
+	return container.NewBorder(top, pathLabel, nil, nil, tree)
+}
+
+func inspectNodeLabel(n *dashboard.JSONNode) string {
+	if n == nil {
+		return ""
+	}
+	switch n.Kind {
+	case dashboard.JSONObject:
+		return fmt.Sprintf("%s {%d}", n.Key, len(n.Children))
+	case dashboard.JSONArray:
+		return fmt.Sprintf("%s [%d]", n.Key, len(n.Children))
+	}
+	return n.Key + ": " + n.Value
+}
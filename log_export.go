@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Log Export
+// =============================================================================
+
+var logFormats = []struct {
+	label  string
+	format dashboard.LogFormat
+}{
+	{"Plain text", dashboard.LogFormatText},
+	{"JSON lines", dashboard.LogFormatJSON},
+}
+
+func logFormatLabels() []string {
+	labels := make([]string, len(logFormats))
+	for i, f := range logFormats {
+		labels[i] = f.label
+	}
+	return labels
+}
+
+func logFormatByLabel(label string) dashboard.LogFormat {
+	for _, f := range logFormats {
+		if f.label == label {
+			return f.format
+		}
+	}
+	return dashboard.LogFormatText
+}
+
+// logFileName suggests a file name such as "web-20240102-150405.log.gz".
+func logFileName(name string, format dashboard.LogFormat, compress bool) string {
+	file := fmt.Sprintf("%s-%s%s", safeFileName(name), time.Now().Format("20060102-150405"), format.Ext())
+	if compress {
+		file += ".gz"
+	}
+	return file
+}
+
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// showSaveLogsDialog writes a time range of the log to a file. The range is
+// read again from the daemon, so it is not limited to the lines the window
+// keeps in memory.
+func showSaveLogsDialog(win fyne.Window, name string, stream logStreamFunc, since, until string) {
+	sinceEntry := widget.NewEntry()
+	sinceEntry.SetText(since)
+	sinceEntry.SetPlaceHolder("start of log")
+	untilEntry := widget.NewEntry()
+	untilEntry.SetText(until)
+	untilEntry.SetPlaceHolder("now")
+	formatSelect := widget.NewSelect(logFormatLabels(), nil)
+	formatSelect.SetSelected(logFormats[0].label)
+	gzipCheck := widget.NewCheck("Compress (gzip)", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Since", sinceEntry),
+		widget.NewFormItem("Until", untilEntry),
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("", gzipCheck),
+	}
+	dialog.ShowForm("Save Logs", "Choose File...", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		opts := dashboard.LogOptions{
+			Tail:       "all",
+			Since:      sinceEntry.Text,
+			Until:      untilEntry.Text,
+			Timestamps: true,
+		}
+		format := logFormatByLabel(formatSelect.Selected)
+		compress := gzipCheck.Checked
+
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if w == nil {
+				return
+			}
+			go func() {
+				n, err := writeLogs(w, stream, opts, format, compress)
+				if err != nil {
+					log.Println("Error saving logs:", err)
+					dialog.ShowError(err, win)
+					return
+				}
+				dialog.ShowInformation("Save Logs", fmt.Sprintf("Saved %d lines to %s", n, w.URI().Path()), win)
+			}()
+		}, win)
+		save.SetFileName(logFileName(name, format, compress))
+		save.Show()
+	}, win)
+}
+
+// writeLogs streams the log into w and closes it.
+func writeLogs(w io.WriteCloser, stream logStreamFunc, opts dashboard.LogOptions, format dashboard.LogFormat, compress bool) (int, error) {
+	lw := dashboard.NewLogWriter(w, format, compress)
+	n := 0
+	var writeErr error
+	streamErr := stream(context.Background(), opts, func(line dashboard.LogLine) {
+		if writeErr != nil {
+			return
+		}
+		writeErr = lw.Write(line)
+		n++
+	})
+	return n, errors.Join(streamErr, writeErr, lw.Close(), w.Close())
+}
+
+// logCapture follows a log and writes it to rotating files until stopped.
+type logCapture struct {
+	writer *dashboard.RotatingLogWriter
+	dir    string
+	stop   context.CancelFunc
+	done   chan struct{}
+	err    error // set once done is closed
+}
+
+// startLogCapture begins a capture. With since empty only new lines are
+// written; otherwise the capture starts at since.
+func startLogCapture(stream logStreamFunc, ropts dashboard.RotateOptions, since string) (*logCapture, error) {
+	w, err := dashboard.NewRotatingLogWriter(ropts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &logCapture{writer: w, dir: ropts.Dir, stop: cancel, done: make(chan struct{})}
+
+	opts := dashboard.LogOptions{Tail: "0", Timestamps: true, Follow: true}
+	if since != "" {
+		opts.Tail = "all"
+		opts.Since = since
+	}
+	go func() {
+		var writeErr error
+		err := stream(ctx, opts, func(line dashboard.LogLine) {
+			if writeErr != nil {
+				return
+			}
+			if writeErr = w.Write(line); writeErr != nil {
+				cancel()
+			}
+		})
+		if ctx.Err() != nil && writeErr == nil {
+			err = nil
+		}
+		c.err = errors.Join(err, writeErr, w.Close())
+		if c.err != nil {
+			log.Println("Error capturing logs:", c.err)
+		}
+		close(c.done)
+	}()
+	return c, nil
+}
+
+// Stop ends the capture and waits for the last file to be closed.
+func (c *logCapture) Stop() error {
+	c.stop()
+	<-c.done
+	return c.err
+}
+
+// Running reports whether the capture is still following the log.
+func (c *logCapture) Running() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *logCapture) String() string {
+	files := c.writer.Files()
+	current := ""
+	if len(files) > 0 {
+		current = ", current " + filepath.Base(files[len(files)-1])
+	}
+	return fmt.Sprintf("%d lines in %d files%s", c.writer.Lines(), len(files), current)
+}
+
+// showCaptureDialog asks where and how to capture; onStart receives the
+// running capture.
+func showCaptureDialog(win fyne.Window, name string, stream logStreamFunc, onStart func(*logCapture)) {
+	home, _ := os.UserHomeDir()
+	dirEntry := widget.NewEntry()
+	dirEntry.SetText(filepath.Join(home, "docker-dashboard-logs", safeFileName(name)))
+	browseBtn := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err == nil && uri != nil {
+				dirEntry.SetText(uri.Path())
+			}
+		}, win)
+	})
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetText("100")
+	ageEntry := widget.NewEntry()
+	ageEntry.SetPlaceHolder("no time limit")
+	sinceEntry := widget.NewEntry()
+	sinceEntry.SetPlaceHolder("only new lines")
+	formatSelect := widget.NewSelect(logFormatLabels(), nil)
+	formatSelect.SetSelected(logFormats[0].label)
+	gzipCheck := widget.NewCheck("Compress (gzip)", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Folder", container.NewBorder(nil, nil, nil, browseBtn, dirEntry)),
+		widget.NewFormItem("Rotate at size (MB)", sizeEntry),
+		widget.NewFormItem("Rotate after (minutes)", ageEntry),
+		widget.NewFormItem("Start from", sinceEntry),
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("", gzipCheck),
+	}
+	d := dialog.NewForm("Capture to Disk", "Start", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		ropts := dashboard.RotateOptions{
+			Dir:      dirEntry.Text,
+			Prefix:   safeFileName(name),
+			Format:   logFormatByLabel(formatSelect.Selected),
+			Compress: gzipCheck.Checked,
+		}
+		if s := strings.TrimSpace(sizeEntry.Text); s != "" {
+			mb, err := strconv.ParseFloat(s, 64)
+			if err != nil || mb <= 0 {
+				dialog.ShowError(fmt.Errorf("invalid file size %q", s), win)
+				return
+			}
+			ropts.MaxBytes = int64(mb * 1024 * 1024)
+		}
+		if s := strings.TrimSpace(ageEntry.Text); s != "" {
+			minutes, err := strconv.ParseFloat(s, 64)
+			if err != nil || minutes <= 0 {
+				dialog.ShowError(fmt.Errorf("invalid rotation time %q", s), win)
+				return
+			}
+			ropts.MaxAge = time.Duration(minutes * float64(time.Minute))
+		}
+		c, err := startLogCapture(stream, ropts, strings.TrimSpace(sinceEntry.Text))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		onStart(c)
+	}, win)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
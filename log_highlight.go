@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Log Highlighting
+// =============================================================================
+
+const prefHighlightRules = "logs.highlightRules"
+
+// highlightColors maps the colour names stored in highlight rules to theme
+// colours, in the order offered by the rules editor.
+var highlightColors = []struct {
+	name  string
+	color fyne.ThemeColorName
+}{
+	{"red", colorNameLogRed},
+	{"orange", colorNameLogOrange},
+	{"yellow", colorNameLogYellow},
+	{"green", colorNameLogGreen},
+	{"blue", colorNameLogBlue},
+	{"purple", colorNameLogPurple},
+	{"gray", colorNameLogGray},
+}
+
+func highlightColor(name string) fyne.ThemeColorName {
+	for _, c := range highlightColors {
+		if c.name == name {
+			return c.color
+		}
+	}
+	return theme.ColorNameForeground
+}
+
+// loadHighlightRules returns the saved rules, or the defaults on first use.
+func loadHighlightRules() []dashboard.HighlightRule {
+	raw := appInstance.Preferences().String(prefHighlightRules)
+	if raw == "" {
+		return dashboard.DefaultHighlightRules()
+	}
+	var rules []dashboard.HighlightRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		log.Println("Error loading highlight rules:", err)
+		return dashboard.DefaultHighlightRules()
+	}
+	return rules
+}
+
+func saveHighlightRules(rules []dashboard.HighlightRule) error {
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	appInstance.Preferences().SetString(prefHighlightRules, string(raw))
+	return nil
+}
+
+// logSegments renders text as rich text runs: highlight rules colour their
+// matches, and search matches are drawn bold in the primary colour on top.
+func logSegments(text string, base fyne.ThemeColorName, rules []dashboard.CompiledHighlightRule, search *dashboard.LogMatcher) []widget.RichTextSegment {
+	colors := make([]fyne.ThemeColorName, len(text))
+	bold := make([]bool, len(text))
+	for i := range colors {
+		colors[i] = base
+	}
+	for _, rule := range rules {
+		c := highlightColor(rule.Color)
+		for _, loc := range rule.FindAll(text) {
+			for i := loc[0]; i < loc[1]; i++ {
+				colors[i] = c
+			}
+		}
+	}
+	for _, loc := range search.FindAll(text) {
+		for i := loc[0]; i < loc[1]; i++ {
+			colors[i] = theme.ColorNamePrimary
+			bold[i] = true
+		}
+	}
+
+	var segments []widget.RichTextSegment
+	start := 0
+	for i := 1; i <= len(text); i++ {
+		if i < len(text) && colors[i] == colors[start] && bold[i] == bold[start] {
+			continue
+		}
+		segments = append(segments, logSegment(text[start:i], colors[start], bold[start]))
+		start = i
+	}
+	return segments
+}
+
+func logSegment(text string, color fyne.ThemeColorName, bold bool) *widget.TextSegment {
+	return &widget.TextSegment{
+		Text: text,
+		Style: widget.RichTextStyle{
+			Inline:    true,
+			ColorName: color,
+			TextStyle: fyne.TextStyle{Monospace: true, Bold: bold},
+		},
+	}
+}
+
+// showHighlightRulesDialog edits the highlight rules; onSave receives the
+// new rules after they were stored.
+func showHighlightRulesDialog(parent fyne.Window, onSave func([]dashboard.HighlightRule)) {
+	rules := loadHighlightRules()
+	colorNames := make([]string, len(highlightColors))
+	for i, c := range highlightColors {
+		colorNames[i] = c.name
+	}
+
+	rows := container.NewVBox()
+	type ruleRow struct {
+		pattern      *widget.Entry
+		regex, cased *widget.Check
+		color        *widget.Select
+		box          *fyne.Container
+	}
+	var ruleRows []*ruleRow
+	addRow := func(rule dashboard.HighlightRule) {
+		r := &ruleRow{
+			pattern: widget.NewEntry(),
+			regex:   widget.NewCheck("Regex", nil),
+			cased:   widget.NewCheck("Match case", nil),
+			color:   widget.NewSelect(colorNames, nil),
+		}
+		r.pattern.SetPlaceHolder("text or pattern")
+		r.pattern.SetText(rule.Pattern)
+		r.regex.SetChecked(rule.Regex)
+		r.cased.SetChecked(rule.CaseSensitive)
+		r.color.SetSelected(rule.Color)
+		if r.color.Selected == "" {
+			r.color.SetSelected(colorNames[0])
+		}
+		removeBtn := widget.NewButton("Remove", nil)
+		r.box = container.NewBorder(nil, nil, nil,
+			container.NewHBox(r.regex, r.cased, r.color, removeBtn), r.pattern)
+		removeBtn.OnTapped = func() {
+			rows.Remove(r.box)
+			for i, other := range ruleRows {
+				if other == r {
+					ruleRows = append(ruleRows[:i], ruleRows[i+1:]...)
+					break
+				}
+			}
+		}
+		ruleRows = append(ruleRows, r)
+		rows.Add(r.box)
+	}
+	for _, rule := range rules {
+		addRow(rule)
+	}
+
+	addBtn := widget.NewButton("Add Rule", func() {
+		addRow(dashboard.HighlightRule{Color: colorNames[0]})
+	})
+	content := container.NewBorder(nil, addBtn, nil, nil, container.NewVScroll(rows))
+
+	d := dialog.NewCustomConfirm("Highlight Rules", "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		var updated []dashboard.HighlightRule
+		for _, r := range ruleRows {
+			updated = append(updated, dashboard.HighlightRule{
+				Pattern:       r.pattern.Text,
+				Regex:         r.regex.Checked,
+				CaseSensitive: r.cased.Checked,
+				Color:         r.color.Selected,
+			})
+		}
+		if _, err := dashboard.CompileHighlightRules(updated); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		if err := saveHighlightRules(updated); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		onSave(updated)
+	}, parent)
+	d.Resize(fyne.NewSize(700, 400))
+	d.Show()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// segmentRun is the text and style of one rich text run.
+type segmentRun struct {
+	text  string
+	color fyne.ThemeColorName
+	bold  bool
+}
+
+func segmentRuns(segments []widget.RichTextSegment) []segmentRun {
+	var runs []segmentRun
+	for _, s := range segments {
+		ts := s.(*widget.TextSegment)
+		runs = append(runs, segmentRun{ts.Text, ts.Style.ColorName, ts.Style.TextStyle.Bold})
+	}
+	return runs
+}
+
+func TestLogSegments(t *testing.T) {
+	rules, err := dashboard.CompileHighlightRules([]dashboard.HighlightRule{
+		{Pattern: "ERROR", CaseSensitive: true, Color: "red"},
+		{Pattern: "disk", Color: "blue"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := dashboard.NewLogMatcher("full", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fg := theme.ColorNameForeground
+
+	tests := []struct {
+		name   string
+		text   string
+		rules  []dashboard.CompiledHighlightRule
+		search *dashboard.LogMatcher
+		want   []segmentRun
+	}{
+		{"plain", "all good", nil, nil, []segmentRun{{"all good", fg, false}}},
+		{"rules", "ERROR: disk full", rules, nil, []segmentRun{
+			{"ERROR", colorNameLogRed, false}, {": ", fg, false}, {"disk", colorNameLogBlue, false}, {" full", fg, false},
+		}},
+		// Search matches are drawn over the rules.
+		{"search", "ERROR: disk full", rules, search, []segmentRun{
+			{"ERROR", colorNameLogRed, false}, {": ", fg, false}, {"disk", colorNameLogBlue, false}, {" ", fg, false},
+			{"full", theme.ColorNamePrimary, true},
+		}},
+		// A later rule wins where rules overlap.
+		{"overlap", "ERROR disk", append(rules, mustCompile(t, dashboard.HighlightRule{Pattern: "OR d", Color: "green"})), nil, []segmentRun{
+			{"ERR", colorNameLogRed, false}, {"OR d", colorNameLogGreen, false}, {"isk", colorNameLogBlue, false},
+		}},
+		{"empty", "", rules, search, nil},
+	}
+	for _, tt := range tests {
+		got := segmentRuns(logSegments(tt.text, fg, tt.rules, tt.search))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: runs = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogSegmentsStderr(t *testing.T) {
+	got := segmentRuns(logSegments("oops", theme.ColorNameError, nil, nil))
+	if len(got) != 1 || got[0].color != theme.ColorNameError {
+		t.Errorf("runs = %v, want one run in the error colour", got)
+	}
+}
+
+func mustCompile(t *testing.T, rules ...dashboard.HighlightRule) dashboard.CompiledHighlightRule {
+	t.Helper()
+	compiled, err := dashboard.CompileHighlightRules(rules)
+	if err != nil || len(compiled) != 1 {
+		t.Fatalf("compile %v: %v", rules, err)
+	}
+	return compiled[0]
+}
+
+func TestHighlightRulesPreference(t *testing.T) {
+	defer appInstance.Preferences().RemoveValue(prefHighlightRules)
+
+	if got := loadHighlightRules(); len(got) != len(dashboard.DefaultHighlightRules()) {
+		t.Errorf("first use = %v, want the defaults", got)
+	}
+	saved := []dashboard.HighlightRule{{Pattern: "db", Color: "green"}}
+	if err := saveHighlightRules(saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadHighlightRules(); len(got) != 1 || got[0] != saved[0] {
+		t.Errorf("loaded %v, want %v", got, saved)
+	}
+	// A broken preference falls back to the defaults.
+	appInstance.Preferences().SetString(prefHighlightRules, "{")
+	if got := loadHighlightRules(); len(got) != len(dashboard.DefaultHighlightRules()) {
+		t.Errorf("broken preference = %v, want the defaults", got)
+	}
+	if highlightColor("no such colour") != theme.ColorNameForeground {
+		t.Error("an unknown colour is not the foreground")
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Structured (JSON) Logs
+// =============================================================================
+
+const prefJSONColumns = "logs.jsonColumns"
+
+var defaultJSONColumns = []string{"time", "level", "msg"}
+
+// logTable shows log lines as a table with one column per chosen JSON field.
+// Plain text lines keep their text in the message column.
+type logTable struct {
+	table  *widget.Table
+	status *widget.Label
+	raw    *widget.Label
+	rawBox *fyne.Container
+
+	// mu guards the fields below, which the log view's redraw ticker
+	// replaces while the table draws them.
+	mu      sync.Mutex
+	columns []string
+	filters []dashboard.FieldFilter
+	lines   []dashboard.LogLine // as handed over by the log view
+	rows    []dashboard.LogLine // lines passing the field filters
+}
+
+func newLogTable(parent fyne.Window) (*logTable, fyne.CanvasObject) {
+	t := &logTable{
+		columns: appInstance.Preferences().StringListWithFallback(prefJSONColumns, defaultJSONColumns),
+		status:  widget.NewLabel(""),
+		raw:     widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true}),
+	}
+	t.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return len(t.rows), len(t.columns)
+		},
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.TextStyle.Monospace = true
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			t.renderCell(id, obj.(*widget.Label))
+		},
+	)
+	t.table.ShowHeaderColumn = false
+	t.table.CreateHeader = func() fyne.CanvasObject {
+		return widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	}
+	t.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		t.mu.Lock()
+		if id.Col < 0 || id.Col >= len(t.columns) {
+			t.mu.Unlock()
+			return
+		}
+		title := t.columns[id.Col]
+		t.mu.Unlock()
+		obj.(*widget.Label).SetText(title)
+	}
+	t.table.OnSelected = func(id widget.TableCellID) {
+		t.mu.Lock()
+		if id.Row < 0 || id.Row >= len(t.rows) {
+			t.mu.Unlock()
+			return
+		}
+		line := t.rows[id.Row]
+		t.mu.Unlock()
+		t.showRaw(line)
+	}
+	t.setColumnWidths()
+
+	filterEntry := widget.NewEntry()
+	filterEntry.SetPlaceHolder("Field filters, e.g. level=error service!=worker")
+	filterEntry.OnChanged = func(query string) {
+		filters, err := dashboard.ParseFieldFilters(query)
+		if err != nil {
+			t.status.SetText(err.Error())
+			return
+		}
+		t.mu.Lock()
+		t.filters = filters
+		t.mu.Unlock()
+		t.table.UnselectAll()
+		t.refresh()
+	}
+	columnsBtn := widget.NewButton("Columns...", func() { t.showColumnsDialog(parent) })
+
+	// The raw view opens below the table for the selected line.
+	rawMin := canvas.NewRectangle(nil)
+	rawMin.SetMinSize(fyne.NewSize(0, 180))
+	closeRaw := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		t.table.UnselectAll()
+		t.rawBox.Hide()
+	})
+	t.rawBox = container.NewBorder(
+		container.NewBorder(nil, nil, widget.NewLabel("Raw line"), closeRaw),
+		nil, nil, nil,
+		container.NewStack(rawMin, container.NewScroll(t.raw)),
+	)
+	t.rawBox.Hide()
+
+	top := container.NewBorder(nil, nil, nil, container.NewHBox(columnsBtn, t.status), filterEntry)
+	return t, container.NewBorder(top, t.rawBox, nil, nil, t.table)
+}
+
+// setLines replaces the lines the table draws from. When follow is set the
+// table keeps the newest line in view.
+func (t *logTable) setLines(lines []dashboard.LogLine, follow bool) {
+	t.mu.Lock()
+	t.lines = lines
+	t.mu.Unlock()
+	t.refresh()
+	if follow {
+		t.table.ScrollToBottom()
+	}
+}
+
+func (t *logTable) refresh() {
+	t.mu.Lock()
+	var rows []dashboard.LogLine
+	jsonLines := 0
+	for _, line := range t.lines {
+		if line.Fields != nil {
+			jsonLines++
+		}
+		if dashboard.MatchFieldFilters(t.filters, line) {
+			rows = append(rows, line)
+		}
+	}
+	t.rows = rows
+	status := fmt.Sprintf("%d of %d lines (%d JSON)", len(rows), len(t.lines), jsonLines)
+	t.mu.Unlock()
+	t.status.SetText(status)
+	t.table.Refresh()
+}
+
+func (t *logTable) setColumns(columns []string) {
+	t.mu.Lock()
+	t.columns = columns
+	t.mu.Unlock()
+	appInstance.Preferences().SetStringList(prefJSONColumns, columns)
+	t.setColumnWidths()
+	t.table.Refresh()
+}
+
+func (t *logTable) setColumnWidths() {
+	t.mu.Lock()
+	columns := slices.Clone(t.columns)
+	t.mu.Unlock()
+	for i, c := range columns {
+		width := float32(160)
+		switch c {
+		case "time":
+			width = 230
+		case "level":
+			width = 80
+		case "msg":
+			width = 520
+		}
+		t.table.SetColumnWidth(i, width)
+	}
+}
+
+// textColumn is where plain text lines show their text. Call it with mu held.
+func (t *logTable) textColumn() int {
+	if i := slices.Index(t.columns, "msg"); i >= 0 {
+		return i
+	}
+	return len(t.columns) - 1
+}
+
+func (t *logTable) renderCell(id widget.TableCellID, label *widget.Label) {
+	t.mu.Lock()
+	if id.Row < 0 || id.Row >= len(t.rows) || id.Col < 0 || id.Col >= len(t.columns) {
+		t.mu.Unlock()
+		return
+	}
+	line := t.rows[id.Row]
+	column := t.columns[id.Col]
+	isText := id.Col == t.textColumn()
+	t.mu.Unlock()
+
+	text, ok := line.Field(column)
+	switch {
+	case column == "time" && !ok && !line.Time.IsZero():
+		text = formatLogTime(line.Time)
+	case column == "container" && !ok:
+		text = line.Container
+	case line.Fields == nil && isText:
+		text = line.Text
+	}
+
+	label.Importance = widget.MediumImportance
+	switch {
+	case column == "level":
+		label.Importance = levelImportance(text)
+	case line.Fields == nil && line.Stream == dashboard.Stderr:
+		label.Importance = widget.DangerImportance
+	}
+	label.SetText(strings.ReplaceAll(text, "\n", " "))
+}
+
+func levelImportance(level string) widget.Importance {
+	switch strings.ToLower(level) {
+	case "error", "err", "fatal", "panic", "critical", "crit":
+		return widget.DangerImportance
+	case "warn", "warning":
+		return widget.WarningImportance
+	case "debug", "trace":
+		return widget.LowImportance
+	}
+	return widget.MediumImportance
+}
+
+// showRaw opens the raw view for line, pretty-printing JSON lines.
+func (t *logTable) showRaw(line dashboard.LogLine) {
+	text := line.Text
+	if line.Fields != nil {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(strings.TrimSpace(line.Text)), "", "  "); err == nil {
+			text = buf.String()
+		}
+	}
+	t.raw.SetText(text)
+	t.rawBox.Show()
+}
+
+// showColumnsDialog picks the table columns from the well-known ones, the
+// fields seen so far and any typed-in field paths.
+func (t *logTable) showColumnsDialog(parent fyne.Window) {
+	t.mu.Lock()
+	lines, columns := t.lines, slices.Clone(t.columns)
+	t.mu.Unlock()
+	options := slices.Clone(defaultJSONColumns)
+	options = append(options, "container")
+	for _, name := range append(dashboard.JSONFieldNames(lines), columns...) {
+		if !slices.Contains(options, name) {
+			options = append(options, name)
+		}
+	}
+	showColumnPicker(parent, options, columns, defaultJSONColumns, true, t.setColumns)
+}
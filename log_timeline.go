@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types/filters"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Log Timeline
+// =============================================================================
+
+// timelineColors are handed out to the containers of a timeline in order.
+var timelineColors = []fyne.ThemeColorName{
+	colorNameLogBlue,
+	colorNameLogGreen,
+	colorNameLogPurple,
+	colorNameLogOrange,
+	colorNameLogYellow,
+	theme.ColorNamePrimary,
+	colorNameLogGray,
+}
+
+// showLogTimelinePicker asks which containers to merge into a timeline,
+// starting with the selected container checked.
+func showLogTimelinePicker() {
+	containers, err := dockerService.ListContainers(context.Background(), filters.NewArgs())
+	if err != nil {
+		log.Println("Error fetching containers:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	labels := make([]string, len(containers))
+	var checked []string
+	for i, c := range containers {
+		labels[i] = fmt.Sprintf("%s (%s, %s)", c.Name, c.Image, c.State)
+		if c.ID == selectedContainerID {
+			checked = append(checked, labels[i])
+		}
+	}
+	group := widget.NewCheckGroup(labels, nil)
+	group.SetSelected(checked)
+
+	d := dialog.NewCustomConfirm("Log Timeline", "Open", "Cancel", container.NewVScroll(group), func(ok bool) {
+		if !ok || len(group.Selected) == 0 {
+			return
+		}
+		var sources []dashboard.LogSource
+		for i, c := range containers {
+			if slices.Contains(group.Selected, labels[i]) {
+				sources = append(sources, dashboard.LogSource{ID: c.ID, Name: c.Name})
+			}
+		}
+		showLogTimeline(sources)
+	}, mainWindow)
+	d.Resize(fyne.NewSize(500, 450))
+	d.Show()
+}
+
+// showLogTimeline opens a log window merging the containers' logs, each line
+// prefixed with its container's name in that container's colour.
+func showLogTimeline(sources []dashboard.LogSource) {
+	colors := make(map[string]fyne.ThemeColorName, len(sources))
+	names := make([]string, len(sources))
+	for i, src := range sources {
+		colors[src.Name] = timelineColors[i%len(timelineColors)]
+		names[i] = src.Name
+	}
+	showLogsInWindow("Log Timeline: "+strings.Join(names, ", "), "timeline",
+		func(ctx context.Context, opts dashboard.LogOptions, handle func(dashboard.LogLine)) error {
+			return dockerService.StreamMergedLogs(ctx, sources, opts, handle)
+		}, colors)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Container Logs
+// =============================================================================
+
+const (
+	// maxLogLines bounds how many lines a log window keeps in memory.
+	maxLogLines = 10000
+	// logRefreshInterval batches redraws while a container is chatty.
+	logRefreshInterval = 250 * time.Millisecond
+)
+
+// logStreamFunc reads log lines into handle; it is StreamLogs for a single
+// container and StreamMergedLogs for a timeline.
+type logStreamFunc func(ctx context.Context, opts dashboard.LogOptions, handle func(dashboard.LogLine)) error
+
+// logView is the state behind one log window.
+type logView struct {
+	stream logStreamFunc
+	// colors assigns each container in a merged timeline its prefix colour;
+	// it is nil for a single container.
+	colors     map[string]fyne.ThemeColorName
+	buf        *dashboard.LogBuffer
+	list       *widget.List
+	status     *widget.Label
+	matchLabel *widget.Label
+	table      *logTable
+	dirty      atomic.Bool
+	stop       context.CancelFunc
+
+	// mu guards the fields below: the redraw ticker replaces them while
+	// the UI goroutine draws and searches them. Widgets are refreshed only
+	// after releasing it, as the list calls back into the view to draw.
+	mu sync.Mutex
+	// lines is the snapshot currently shown: the whole buffer, or only the
+	// matching lines when onlyMatching is set.
+	lines        []dashboard.LogLine
+	rules        []dashboard.CompiledHighlightRule
+	search       *dashboard.LogMatcher
+	onlyMatching bool
+	// matches are indexes into lines; current is the position in matches
+	// the user navigated to, or -1.
+	matches    []int
+	current    int
+	timestamps bool
+	following  bool
+	// capture is the running capture to disk, if any.
+	capture *logCapture
+	// gen identifies the current stream; lines a cancelled stream decoded
+	// before it stopped are dropped rather than added after the reset.
+	gen int
+}
+
+// logStyle is what drawing a line depends on besides the line itself.
+type logStyle struct {
+	timestamps bool
+	rules      []dashboard.CompiledHighlightRule
+	search     *dashboard.LogMatcher
+}
+
+func viewContainerLogs(id string) {
+	if id == "" {
+		return
+	}
+	info, err := dockerService.InspectContainer(context.Background(), id)
+	if err != nil {
+		showActionError("Error fetching logs:", err)
+		return
+	}
+	name := trimName(info.Name)
+	showLogsInWindow("Logs: "+name, name, func(ctx context.Context, opts dashboard.LogOptions, handle func(dashboard.LogLine)) error {
+		return dockerService.StreamLogs(ctx, id, opts, handle)
+	}, nil)
+}
+
+// showLogsInWindow opens a log window; name is used for saved files.
+func showLogsInWindow(title, name string, stream logStreamFunc, colors map[string]fyne.ThemeColorName) {
+	win := appInstance.NewWindow(title)
+	v := &logView{
+		stream:     stream,
+		colors:     colors,
+		buf:        dashboard.NewLogBuffer(maxLogLines),
+		status:     widget.NewLabel(""),
+		matchLabel: widget.NewLabel(""),
+		current:    -1,
+	}
+	v.setRules(loadHighlightRules())
+	v.list = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.lines)
+		},
+		func() fyne.CanvasObject {
+			rt := widget.NewRichText()
+			rt.Truncation = fyne.TextTruncateEllipsis
+			return rt
+		},
+		func(i int, obj fyne.CanvasObject) {
+			// The lines may have been replaced by a shorter snapshot since
+			// the list asked for their number.
+			v.mu.Lock()
+			if i < 0 || i >= len(v.lines) {
+				v.mu.Unlock()
+				return
+			}
+			line := v.lines[i]
+			style := logStyle{timestamps: v.timestamps, rules: v.rules, search: v.search}
+			v.mu.Unlock()
+			v.renderLine(line, style, obj.(*widget.RichText))
+		},
+	)
+
+	tailEntry := widget.NewEntry()
+	tailEntry.SetText("100")
+	sinceEntry := widget.NewEntry()
+	sinceEntry.SetPlaceHolder("e.g. 10m or 2024-01-02T15:04:05Z")
+	untilEntry := widget.NewEntry()
+	untilEntry.SetPlaceHolder("e.g. 5m or 2024-01-02T16:00:00Z")
+	timestampsCheck := widget.NewCheck("Timestamps", nil)
+	followCheck := widget.NewCheck("Follow", nil)
+
+	reload := func() {
+		v.start(dashboard.LogOptions{
+			Tail:       tailEntry.Text,
+			Since:      sinceEntry.Text,
+			Until:      untilEntry.Text,
+			Timestamps: timestampsCheck.Checked,
+			Follow:     followCheck.Checked,
+		})
+	}
+	timestampsCheck.OnChanged = func(bool) { reload() }
+	followCheck.OnChanged = func(bool) { reload() }
+	reloadBtn := widget.NewButton("Reload", reload)
+	saveBtn := widget.NewButton("Save Logs...", func() {
+		showSaveLogsDialog(win, name, stream, sinceEntry.Text, untilEntry.Text)
+	})
+
+	// Capture to disk keeps running until stopped or the window closes.
+	captureStatus := widget.NewLabel("")
+	var captureBtn *widget.Button
+	captureBtn = widget.NewButton("Capture to Disk...", func() {
+		if c := v.setCapture(nil); c != nil {
+			if err := c.Stop(); err != nil {
+				dialog.ShowError(err, win)
+			}
+			captureStatus.SetText("Capture stopped: " + c.String())
+			captureBtn.SetText("Capture to Disk...")
+			return
+		}
+		showCaptureDialog(win, name, stream, func(c *logCapture) {
+			v.setCapture(c)
+			captureBtn.SetText("Stop Capture")
+			captureStatus.SetText("Capturing to " + c.dir)
+		})
+	})
+
+	// Search bar
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search")
+	regexCheck := widget.NewCheck("Regex", nil)
+	caseCheck := widget.NewCheck("Match case", nil)
+	onlyMatchingCheck := widget.NewCheck("Only matching lines", nil)
+	updateSearch := func() {
+		m, err := dashboard.NewLogMatcher(searchEntry.Text, regexCheck.Checked, caseCheck.Checked)
+		if err != nil {
+			v.matchLabel.SetText(err.Error())
+			return
+		}
+		v.mu.Lock()
+		v.search = m
+		v.onlyMatching = onlyMatchingCheck.Checked
+		v.current = -1
+		v.mu.Unlock()
+		v.redraw()
+	}
+	searchEntry.OnChanged = func(string) { updateSearch() }
+	regexCheck.OnChanged = func(bool) { updateSearch() }
+	caseCheck.OnChanged = func(bool) { updateSearch() }
+	onlyMatchingCheck.OnChanged = func(bool) { updateSearch() }
+	searchEntry.OnSubmitted = func(string) { v.jump(1) }
+	prevBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { v.jump(-1) })
+	nextBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { v.jump(1) })
+	rulesBtn := widget.NewButton("Highlight Rules...", func() {
+		showHighlightRulesDialog(win, func(rules []dashboard.HighlightRule) {
+			v.setRules(rules)
+			v.list.Refresh()
+		})
+	})
+
+	options := widget.NewForm(
+		widget.NewFormItem("Tail (lines or \"all\")", tailEntry),
+		widget.NewFormItem("Since", sinceEntry),
+		widget.NewFormItem("Until", untilEntry),
+	)
+	searchBar := container.NewBorder(nil, nil, nil,
+		container.NewHBox(regexCheck, caseCheck, onlyMatchingCheck, prevBtn, nextBtn, v.matchLabel, rulesBtn),
+		searchEntry)
+	controls := container.NewVBox(
+		options,
+		container.NewHBox(timestampsCheck, followCheck, reloadBtn, saveBtn, captureBtn, v.status),
+		captureStatus,
+		searchBar,
+	)
+
+	var tableView fyne.CanvasObject
+	v.table, tableView = newLogTable(win)
+	views := container.NewAppTabs(
+		container.NewTabItem("Lines", v.list),
+		container.NewTabItem("Table", tableView),
+	)
+
+	win.SetContent(container.NewBorder(controls, nil, nil, nil, views))
+	win.Resize(fyne.NewSize(1000, 650))
+
+	ticker := time.NewTicker(logRefreshInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if v.dirty.Swap(false) {
+					v.redraw()
+				}
+				v.mu.Lock()
+				c := v.capture
+				v.mu.Unlock()
+				if c != nil {
+					if c.Running() {
+						captureStatus.SetText("Capturing to " + c.dir + ": " + c.String())
+					} else {
+						captureStatus.SetText("Capture ended: " + c.String())
+					}
+				}
+			}
+		}
+	}()
+	win.SetOnClosed(func() {
+		ticker.Stop()
+		close(done)
+		if v.stop != nil {
+			v.stop()
+		}
+		if c := v.setCapture(nil); c != nil {
+			c.Stop()
+		}
+	})
+	win.Show()
+	reload()
+}
+
+// start (re)reads the log with the given options, replacing what is shown.
+func (v *logView) start(opts dashboard.LogOptions) {
+	if v.stop != nil {
+		v.stop()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	v.stop = cancel
+	v.mu.Lock()
+	v.gen++
+	gen := v.gen
+	v.timestamps = opts.Timestamps
+	v.following = opts.Follow
+	v.buf.Reset()
+	v.mu.Unlock()
+	v.dirty.Store(true)
+	if opts.Follow {
+		v.status.SetText("Following...")
+	} else {
+		v.status.SetText("Loading...")
+	}
+
+	go func() {
+		err := v.stream(ctx, opts, func(line dashboard.LogLine) {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			if gen != v.gen {
+				return
+			}
+			v.buf.Append(line)
+			v.dirty.Store(true)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			v.status.SetText("Error: " + err.Error())
+		case opts.Follow:
+			v.status.SetText("Stream ended")
+		default:
+			v.status.SetText("")
+		}
+		v.dirty.Store(true)
+	}()
+}
+
+// setCapture replaces the running capture, returning the previous one.
+func (v *logView) setCapture(c *logCapture) *logCapture {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	prev := v.capture
+	v.capture = c
+	return prev
+}
+
+func (v *logView) setRules(rules []dashboard.HighlightRule) {
+	compiled, err := dashboard.CompileHighlightRules(rules)
+	if err != nil {
+		log.Println("Error compiling highlight rules:", err)
+		return
+	}
+	v.mu.Lock()
+	v.rules = compiled
+	v.mu.Unlock()
+}
+
+// redraw takes a new snapshot of the buffer and re-applies the search.
+func (v *logView) redraw() {
+	all := v.buf.Lines()
+	v.mu.Lock()
+	lines := all
+	if v.onlyMatching && v.search != nil {
+		lines = nil
+		for _, i := range v.search.Filter(all) {
+			lines = append(lines, all[i])
+		}
+	}
+	v.lines = lines
+	v.matches = v.search.Filter(lines)
+	if v.current >= len(v.matches) {
+		v.current = -1
+	}
+	label := v.matchText()
+	follow := v.following && v.current < 0
+	v.mu.Unlock()
+
+	v.matchLabel.SetText(label)
+	v.list.Refresh()
+	v.table.setLines(lines, follow)
+	if dropped := v.buf.Dropped(); dropped > 0 {
+		v.status.SetText(fmt.Sprintf("Showing last %d lines (%d older dropped)", len(all), dropped))
+	}
+	if follow {
+		v.list.ScrollToBottom()
+	}
+}
+
+// jump moves to the next (dir > 0) or previous matching line, wrapping around.
+func (v *logView) jump(dir int) {
+	v.mu.Lock()
+	if len(v.matches) == 0 {
+		v.mu.Unlock()
+		return
+	}
+	switch {
+	case v.current < 0 && dir > 0:
+		v.current = 0
+	case v.current < 0:
+		v.current = len(v.matches) - 1
+	default:
+		v.current = (v.current + dir + len(v.matches)) % len(v.matches)
+	}
+	line := v.matches[v.current]
+	label := v.matchText()
+	v.mu.Unlock()
+
+	v.list.ScrollTo(line)
+	v.list.Select(line)
+	v.matchLabel.SetText(label)
+}
+
+// matchText is the text of the match label. Call it with mu held.
+func (v *logView) matchText() string {
+	switch {
+	case v.search == nil:
+		return ""
+	case v.current >= 0:
+		return fmt.Sprintf("%d / %d", v.current+1, len(v.matches))
+	default:
+		return fmt.Sprintf("%d matches", len(v.matches))
+	}
+}
+
+// renderLine fills a list row. stderr lines are drawn in the error colour,
+// then highlight rules and search matches are applied on top. Timeline lines
+// start with their container's name.
+func (v *logView) renderLine(line dashboard.LogLine, style logStyle, rt *widget.RichText) {
+	base := theme.ColorNameForeground
+	if line.Stream == dashboard.Stderr {
+		base = theme.ColorNameError
+	}
+	var segments []widget.RichTextSegment
+	if style.timestamps && !line.Time.IsZero() {
+		segments = append(segments, logSegment(formatLogTime(line.Time)+"  ", theme.ColorNameDisabled, false))
+	}
+	if v.colors != nil {
+		segments = append(segments, logSegment(line.Container+" | ", v.colors[line.Container], true))
+	}
+	segments = append(segments, logSegments(line.Text, base, style.rules, style.search)...)
+	rt.Segments = segments
+	rt.Refresh()
+}
+
+func formatLogTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05.000")
+}
@@ -2,12 +2,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"image/color"
-	"io"
 	"log"
-	"strconv"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -17,14 +14,11 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
-	"github.com/docker/docker/api/types"
 	dockerContainer "github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
-	dockerImage "github.com/docker/docker/api/types/image"
-	dockerNetwork "github.com/docker/docker/api/types/network"
-	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
+
+	"sprint/dashboard"
 )
 
 var (
@@ -34,15 +28,29 @@ var (
 	tlsCertPath = ""
 	tlsKeyPath  = ""
 
-	// Global Docker client and main window (for dialogs)
-	dockerCli  *client.Client
+	// Main window (for dialogs)
 	mainWindow fyne.Window
 
-	// Global selection indices (only declared once)
-	selectedContainerIndex = -1
-	selectedImageIndex     = -1
-	selectedVolumeIndex    = -1
-	selectedNetworkIndex   = -1
+	// Docker operations used by the tabs
+	dockerService dashboard.DockerService
+
+	// Registry credentials attached to pulls, pushes and searches
+	registryAuth *dashboard.RegistryAuth
+
+	// Global selections, tracked by Docker ID (volumes by name) so that an
+	// action always targets the row the user picked, even if the list has
+	// changed since. Empty means nothing is selected.
+	selectedContainerID = ""
+	selectedImageID     = ""
+	selectedVolumeName  = ""
+	selectedNetworkID   = ""
+
+	// Docker filters each tab lists its resources with, set by the tab's
+	// filter bar. Empty lists everything.
+	containerFilters = newFilterArgs()
+	imageFilters     = newFilterArgs()
+	volumeFilters    = newFilterArgs()
+	networkFilters   = newFilterArgs()
 
 	// Global app instance
 	appInstance fyne.App
@@ -50,6 +58,17 @@ var (
 
 type iPhoneLikeTheme struct{}
 
+// Extra colour names used by log highlight rules.
+const (
+	colorNameLogRed    fyne.ThemeColorName = "logRed"
+	colorNameLogOrange fyne.ThemeColorName = "logOrange"
+	colorNameLogYellow fyne.ThemeColorName = "logYellow"
+	colorNameLogGreen  fyne.ThemeColorName = "logGreen"
+	colorNameLogBlue   fyne.ThemeColorName = "logBlue"
+	colorNameLogPurple fyne.ThemeColorName = "logPurple"
+	colorNameLogGray   fyne.ThemeColorName = "logGray"
+)
+
 func (i *iPhoneLikeTheme) Color(n fyne.ThemeColorName, v fyne.ThemeVariant) color.Color {
 	switch n {
 	case theme.ColorNameBackground:
@@ -72,6 +91,21 @@ func (i *iPhoneLikeTheme) Color(n fyne.ThemeColorName, v fyne.ThemeVariant) colo
 		return color.RGBA{0, 122, 255, 255}
 	case theme.ColorNameScrollBar:
 		return color.RGBA{180, 180, 180, 180}
+	case colorNameLogRed:
+		return color.RGBA{255, 59, 48, 255}
+	case colorNameLogOrange:
+		return color.RGBA{255, 149, 0, 255}
+	case colorNameLogYellow:
+		// Darker than iOS yellow so it stays readable on white
+		return color.RGBA{196, 150, 0, 255}
+	case colorNameLogGreen:
+		return color.RGBA{52, 168, 83, 255}
+	case colorNameLogBlue:
+		return color.RGBA{0, 122, 255, 255}
+	case colorNameLogPurple:
+		return color.RGBA{175, 82, 222, 255}
+	case colorNameLogGray:
+		return color.RGBA{142, 142, 147, 255}
 	default:
 		return theme.DefaultTheme().Color(n, v)
 	}
@@ -109,28 +143,36 @@ func createDockerClient() error {
 	if tlsCAPath != "" && tlsCertPath != "" && tlsKeyPath != "" {
 		opts = append(opts, client.WithTLSClientConfig(tlsCAPath, tlsCertPath, tlsKeyPath))
 	}
-	var err error
-	dockerCli, err = client.NewClientWithOpts(opts...)
-	return err
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return err
+	}
+	svc := dashboard.NewService(cli)
+	svc.SetRegistryAuth(registryAuth)
+	dockerService = svc
+	return nil
 }
 
 func main() {
-	appInstance = fyneApp.New()
+	// A unique ID is required for preferences to persist between sessions.
+	appInstance = fyneApp.NewWithID("io.github.cardoza1991.docker-dashboard")
 	appInstance.Settings().SetTheme(&iPhoneLikeTheme{})
 
 	mainWindow = appInstance.NewWindow("Docker Dashboard")
 	mainWindow.Resize(fyne.NewSize(1200, 800))
 
 	// Create Docker client.
+	registryAuth = newRegistryAuth()
 	if err := createDockerClient(); err != nil {
 		log.Fatal("Error creating Docker client:", err)
 	}
 
 	// Build tabs.
-	containersTab := buildContainersTab(dockerCli)
-	imagesTab := buildImagesTab(dockerCli)
-	volumesTab := buildVolumesTab(dockerCli)
-	networksTab := buildNetworksTab(dockerCli)
+	containersTab := buildContainersTab()
+	imagesTab := buildImagesTab()
+	volumesTab := buildVolumesTab()
+	networksTab := buildNetworksTab()
+	projectsTab := buildProjectsTab()
 	settingsTab := buildSettingsTab()
 
 	tabs := container.NewAppTabs(
@@ -138,269 +180,42 @@ func main() {
 		container.NewTabItem("Images", imagesTab),
 		container.NewTabItem("Volumes", volumesTab),
 		container.NewTabItem("Networks", networksTab),
+		container.NewTabItem("Projects", projectsTab),
 		container.NewTabItem("Settings", settingsTab),
 	)
 	tabs.SetTabLocation(container.TabLocationTop)
 
+	// Keep the lists in sync with changes made outside the dashboard.
+	startEventWatcher()
+
 	mainWindow.SetContent(tabs)
 	mainWindow.ShowAndRun()
 }
 
 // =============================================================================
-// Container Stats (Non-Streaming, One-Shot)
-// =============================================================================
-
-func showContainerStats(index int, cli *client.Client) {
-	if index == -1 {
-		return
-	}
-	containers, err := dockerCli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
-		return
-	}
-	// Get one-shot stats.
-	stats, err := dockerCli.ContainerStatsOneShot(context.Background(), containers[index].ID)
-	if err != nil {
-		log.Println("Error fetching container stats:", err)
-		return
-	}
-	defer stats.Body.Close()
-
-	var statsJSON types.StatsJSON
-	decoder := json.NewDecoder(stats.Body)
-	if err := decoder.Decode(&statsJSON); err != nil {
-		log.Println("Error decoding stats:", err)
-		return
-	}
-
-	cpuDelta := float64(statsJSON.CPUStats.CPUUsage.TotalUsage - statsJSON.PreCPUStats.CPUUsage.TotalUsage)
-	systemDelta := float64(statsJSON.CPUStats.SystemUsage - statsJSON.PreCPUStats.SystemUsage)
-	cpuPercent := 0.0
-	if systemDelta > 0 && cpuDelta > 0 {
-		cpuPercent = (cpuDelta / systemDelta) * float64(len(statsJSON.CPUStats.CPUUsage.PercpuUsage)) * 100.0
-	}
-
-	memUsed := statsJSON.MemoryStats.Usage
-	memLimit := statsJSON.MemoryStats.Limit
-	memPercent := 0.0
-	if memLimit > 0 {
-		memPercent = (float64(memUsed) / float64(memLimit)) * 100.0
-	}
-
-	statsText := fmt.Sprintf("CPU Usage: %.2f%%\nMemory Usage: %d / %d (%.2f%%)",
-		cpuPercent, memUsed, memLimit, memPercent)
-
-	win := appInstance.NewWindow("Container Stats")
-	lbl := widget.NewLabel(statsText)
-	lbl.Wrapping = fyne.TextWrapWord
-	win.SetContent(container.NewScroll(lbl))
-	win.Resize(fyne.NewSize(300, 200))
-	win.Show()
-}
-
+// Selection Helpers
 // =============================================================================
-// Advanced Container Creation (Accordion Form)
-// (Not hooked up to any tab by default.)
-func showAdvancedContainerForm(parent fyne.Window) {
-	// 1. Basic Section
-	imageEntry := widget.NewEntry()
-	imageEntry.SetText("alpine")
-	imageHelp := widget.NewLabel("The Docker image name, e.g. 'alpine:latest'")
-
-	cmdEntry := widget.NewEntry()
-	cmdEntry.SetText("echo hello world")
-	cmdHelp := widget.NewLabel("Command to run in the container.")
-
-	basicForm := container.NewVBox(
-		widget.NewForm(
-			widget.NewFormItem("Image", imageEntry),
-			widget.NewFormItem("Command", cmdEntry),
-		),
-		imageHelp,
-		cmdHelp,
-	)
-
-	// 2. Environment & Ports Section
-	envContainer := container.NewVBox()
-	envHelp := widget.NewLabel("Set environment variables (key=value). Add rows as needed.")
-	addEnvBtn := widget.NewButton("Add Env", func() {
-		row := newEnvVarRow(envContainer)
-		envContainer.Add(row)
-	})
-
-	portsContainer := container.NewVBox()
-	portsHelp := widget.NewLabel("Map host ports to container ports (hostPort -> containerPort).")
-	addPortBtn := widget.NewButton("Add Port", func() {
-		row := newPortMapRow(portsContainer)
-		portsContainer.Add(row)
-	})
-
-	envPortsSection := container.NewVBox(
-		envHelp,
-		addEnvBtn,
-		envContainer,
-		widget.NewSeparator(),
-		portsHelp,
-		addPortBtn,
-		portsContainer,
-	)
 
-	// 3. Advanced Section
-	memoryEntry := widget.NewEntry()
-	memoryEntry.SetPlaceHolder("e.g. 256 (MB)")
-	memoryHelp := widget.NewLabel("Memory limit in MB. Leave blank for unlimited.")
-
-	cpuSharesEntry := widget.NewEntry()
-	cpuSharesEntry.SetPlaceHolder("e.g. 1024")
-	cpuHelp := widget.NewLabel("Relative CPU weight. 1024 is default for one CPU.")
-
-	privilegedCheck := widget.NewCheck("Privileged Mode", func(bool) {})
-	privilegedHelp := widget.NewLabel("Grants extended privileges to this container.")
-
-	advancedForm := container.NewVBox(
-		widget.NewForm(
-			widget.NewFormItem("Memory (MB)", memoryEntry),
-			widget.NewFormItem("CPU Shares", cpuSharesEntry),
-		),
-		memoryHelp,
-		cpuHelp,
-		widget.NewSeparator(),
-		privilegedCheck,
-		privilegedHelp,
-	)
-
-	accordion := widget.NewAccordion(
-		widget.NewAccordionItem("Basic", basicForm),
-		widget.NewAccordionItem("Environment & Ports", envPortsSection),
-		widget.NewAccordionItem("Advanced", advancedForm),
-	)
-	for _, item := range accordion.Items {
-		item.Open = true
-	}
-
-	submitBtn := widget.NewButton("Create Container", func() {
-		image := imageEntry.Text
-		cmdParts := strings.Fields(cmdEntry.Text)
-		envVars := gatherEnvVars(envContainer)
-		// (Ports gathering omitted for brevity.)
-		var memoryLimit int64
-		if memoryEntry.Text != "" {
-			mb, err := strconv.ParseInt(memoryEntry.Text, 10, 64)
-			if err == nil && mb > 0 {
-				memoryLimit = mb * 1024 * 1024
-			}
+// indexOf returns the index of the first item matching pred, or -1.
+func indexOf[T any](items []T, pred func(T) bool) int {
+	for i, item := range items {
+		if pred(item) {
+			return i
 		}
-		var cpuShares int64
-		if cpuSharesEntry.Text != "" {
-			shares, err := strconv.ParseInt(cpuSharesEntry.Text, 10, 64)
-			if err == nil && shares > 0 {
-				cpuShares = shares
-			}
-		}
-		privileged := privilegedCheck.Checked
-
-		_, err := dockerCli.ImagePull(context.Background(), image, dockerImage.PullOptions{})
-		if err != nil {
-			dialog.ShowError(err, parent)
-			return
-		}
-
-		resp, err := dockerCli.ContainerCreate(context.Background(),
-			&dockerContainer.Config{
-				Image: image,
-				Cmd:   cmdParts,
-				Env:   envVars,
-			},
-			&dockerContainer.HostConfig{
-				Privileged: privileged,
-				Resources: dockerContainer.Resources{
-					Memory:    memoryLimit,
-					CPUShares: cpuShares,
-				},
-			},
-			nil, nil, "",
-		)
-		if err != nil {
-			dialog.ShowError(err, parent)
-			return
-		}
-
-		if err := dockerCli.ContainerStart(context.Background(), resp.ID, dockerContainer.StartOptions{}); err != nil {
-			dialog.ShowError(err, parent)
-			return
-		}
-		dialog.ShowInformation("Success", "Container created and started!", parent)
-		parent.Close()
-	})
-
-	content := container.NewVBox(accordion, submitBtn)
-	parent.SetContent(content)
+	}
+	return -1
 }
 
-// =============================================================================
-// Helper Functions for Advanced Form
-// =============================================================================
-
-func newEnvVarRow(parent *fyne.Container) fyne.CanvasObject {
-	keyEntry := widget.NewEntry()
-	keyEntry.SetPlaceHolder("KEY")
-	valEntry := widget.NewEntry()
-	valEntry.SetPlaceHolder("value")
-	rowBox := container.NewHBox(keyEntry, valEntry)
-	removeBtn := widget.NewButton("Remove", func() {
-		parent.Remove(rowBox)
-	})
-	rowBox.Add(removeBtn)
-	return rowBox
-}
-
-func gatherEnvVars(envContainer *fyne.Container) []string {
-	var result []string
-	for _, child := range envContainer.Objects {
-		if row, ok := child.(*fyne.Container); ok && len(row.Objects) >= 2 {
-			if keyE, ok1 := row.Objects[0].(*widget.Entry); ok1 && keyE.Text != "" {
-				var valText string
-				if valE, ok2 := row.Objects[1].(*widget.Entry); ok2 {
-					valText = valE.Text
-				}
-				result = append(result, fmt.Sprintf("%s=%s", keyE.Text, valText))
-			}
-		}
-	}
-	return result
+// trimName strips the leading slash the API puts in front of container names.
+func trimName(name string) string {
+	return strings.TrimPrefix(name, "/")
 }
 
-func newPortMapRow(parent *fyne.Container) fyne.CanvasObject {
-	hostEntry := widget.NewEntry()
-	hostEntry.SetPlaceHolder("hostPort")
-	containerEntry := widget.NewEntry()
-	containerEntry.SetPlaceHolder("containerPort")
-	rowBox := container.NewHBox(hostEntry, containerEntry)
-	removeBtn := widget.NewButton("Remove", func() {
-		parent.Remove(rowBox)
-	})
-	rowBox.Add(removeBtn)
-	return rowBox
-}
-
-func gatherPortBindings(portsContainer *fyne.Container) (nat.PortMap, error) {
-	portBindings := nat.PortMap{}
-	for _, child := range portsContainer.Objects {
-		if row, ok := child.(*fyne.Container); ok && len(row.Objects) >= 2 {
-			hostE, ok1 := row.Objects[0].(*widget.Entry)
-			contE, ok2 := row.Objects[1].(*widget.Entry)
-			if !ok1 || !ok2 || hostE.Text == "" || contE.Text == "" {
-				continue
-			}
-			containerPort := contE.Text + "/tcp"
-			port := nat.Port(containerPort)
-			portBindings[port] = append(portBindings[port], nat.PortBinding{
-				HostIP:   "",
-				HostPort: hostE.Text,
-			})
-		}
-	}
-	return portBindings, nil
+// showActionError logs err and tells the user, so that a failed action
+// (including one on a resource that has vanished) never fails silently.
+func showActionError(msg string, err error) {
+	log.Println(msg, err)
+	dialog.ShowError(err, mainWindow)
 }
 
 // =============================================================================
@@ -433,404 +248,359 @@ func buildSettingsTab() fyne.CanvasObject {
 			dialog.ShowError(err, mainWindow)
 			return
 		}
+		startEventWatcher()
 		dialog.ShowInformation("Settings", "Docker client updated successfully", mainWindow)
 	}
 	form.OnCancel = func() {}
-	return form
+
+	registryList, registryButtons := buildRegistriesSection()
+	heading := widget.NewLabelWithStyle("Registries", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	return container.NewBorder(container.NewVBox(form, widget.NewSeparator(), heading), registryButtons, nil, nil, registryList)
 }
 
 // =============================================================================
 // Containers Tab
 // =============================================================================
 
-func buildContainersTab(cli *client.Client) fyne.CanvasObject {
-	var containerData []string
+func buildContainersTab() fyne.CanvasObject {
+	usage := newContainerUsage()
+	containerList := newResourceTable("containers",
+		func(c dashboard.Container) string { return c.ID },
+		containerColumns(usage), defaultContainerColumns)
+	containerList.onSelected = func(c dashboard.Container) {
+		selectedContainerID = c.ID
+		fmt.Println("Selected container:", formatContainerRow(c))
+	}
+
+	bulkButtons := newContainerActionButtons("Checked", containerList.selected,
+		func(n int) string { return fmt.Sprintf("%d checked containers", n) },
+		func() { updateContainerList(dockerService, containerList) })
+	selectionBar := containerList.selectionBar(bulkButtons...)
+	tableToolbar := containerList.toolbar()
+
+	// The grouped view shares the list's data and selection.
+	groupView := newContainerGroupView()
+	groupButtons := newGroupActionButtons(groupView)
+	var labelEntry *widget.SelectEntry
+	refreshGroups := func() {
+		containers := containerList.snapshot()
+		if labelEntry != nil {
+			labelEntry.SetOptions(dashboard.LabelKeys(containers))
+		}
+		groupView.setContainers(containers)
+	}
+	viewSelect, labelEntry := newContainerViewSelector(func(keys []string) {
+		groupView.setKeys(keys)
+		if keys == nil {
+			groupView.tree.Hide()
+			containerList.selectKey(selectedContainerID)
+			containerList.table.Show()
+			tableToolbar.Show()
+			selectionBar.Show()
+			for _, b := range groupButtons {
+				b.Hide()
+			}
+			return
+		}
+		containerList.table.Hide()
+		tableToolbar.Hide()
+		selectionBar.Hide()
+		groupView.tree.Show()
+		for _, b := range groupButtons {
+			b.Show()
+		}
+		refreshGroups()
+		groupView.tree.OpenAllBranches()
+	})
 
-	containerList := widget.NewList(
-		func() int { return len(containerData) },
-		func() fyne.CanvasObject { return widget.NewLabel("") },
-		func(i int, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(containerData[i])
-		},
-	)
-	containerList.OnSelected = func(id int) {
-		selectedContainerIndex = id
-		fmt.Println("Selected container:", containerData[id])
+	reload := func() error {
+		err := updateContainerList(dockerService, containerList)
+		refreshGroups()
+		return err
 	}
-
-	refreshBtn := widget.NewButton("Refresh", func() {
-		updateContainerList(&containerData, containerList, cli)
-	})
+	filterBar := newFilterBar("containers", containerFilterFields, containerFilters, reload)
+	refreshBtn := widget.NewButton("Refresh", func() { reload() })
 	startBtn := widget.NewButton("Start", func() {
-		startSelectedContainer(selectedContainerIndex, cli, &containerData, containerList)
+		startSelectedContainer(selectedContainerID, containerList)
 	})
 	stopBtn := widget.NewButton("Stop", func() {
-		stopSelectedContainer(selectedContainerIndex, cli, &containerData, containerList)
+		stopSelectedContainer(selectedContainerID, containerList)
 	})
 	logsBtn := widget.NewButton("Logs", func() {
-		viewContainerLogs(selectedContainerIndex, cli)
+		viewContainerLogs(selectedContainerID)
 	})
 	removeBtn := widget.NewButton("Remove", func() {
-		removeSelectedContainer(selectedContainerIndex, cli, &containerData, containerList)
+		removeSelectedContainer(selectedContainerID, containerList)
 	})
 	inspectBtn := widget.NewButton("Inspect", func() {
-		inspectSelectedContainer(selectedContainerIndex, cli)
+		inspectSelectedContainer(selectedContainerID)
 	})
 	statsBtn := widget.NewButton("Stats", func() {
-		showContainerStats(selectedContainerIndex, cli)
+		showContainerStats(selectedContainerID)
+	})
+	timelineBtn := widget.NewButton("Log Timeline", func() {
+		showLogTimelinePicker()
+	})
+	copyRunBtn := widget.NewButton("Copy as docker run", func() {
+		copyRunCommand(selectedContainerID)
+	})
+	exportBtn := widget.NewButton("Export as compose.yaml", func() {
+		// Start from the selected group's containers in the grouped view.
+		ids := []string{selectedContainerID}
+		if g := groupView.selectedGroup(); g != nil && groupView.grouped() {
+			ids = nil
+			for _, c := range g.All() {
+				ids = append(ids, c.ID)
+			}
+		}
+		showComposeExportPicker(ids)
+	})
+	execBtn := widget.NewButton("Exec", func() {
+		showExecDialog(selectedContainerID)
+	})
+	attachBtn := widget.NewButton("Attach", func() {
+		showAttachWindow(selectedContainerID)
 	})
 	runAlpineBtn := widget.NewButton("Run Alpine", func() {
-		runAlpineContainer(cli, &containerData, containerList)
+		runAlpineContainer(containerList)
 	})
 	runCustomBtn := widget.NewButton("Run Custom Container", func() {
-		showCustomContainerForm(cli, &containerData, containerList)
+		showAdvancedContainerForm(func() { updateContainerList(dockerService, containerList) })
 	})
 
 	topRow := container.NewHBox(refreshBtn, startBtn, stopBtn, logsBtn, removeBtn)
-	midRow := container.NewHBox(inspectBtn, statsBtn, runAlpineBtn, runCustomBtn)
-	containerBox := container.NewVBox(containerList, topRow, midRow)
-	updateContainerList(&containerData, containerList, cli)
+	midRow := container.NewHBox(inspectBtn, copyRunBtn, exportBtn, statsBtn, timelineBtn, execBtn, attachBtn, runAlpineBtn, runCustomBtn)
+	containerBox := container.NewBorder(
+		container.NewVBox(groupViewToolbar(viewSelect, labelEntry, groupButtons), filterBar.accordion, tableToolbar),
+		container.NewVBox(selectionBar, topRow, midRow), nil, nil,
+		container.NewStack(containerList.table, groupView.tree),
+	)
+	updateContainerList(dockerService, containerList)
+	refreshGroups()
+	onEvent(events.ContainerEventType, func(svc dashboard.DockerService, ev dashboard.Event) {
+		applyContainerEvent(svc, ev, containerList)
+		refreshGroups()
+	})
+	poller := &usagePoller{usage: usage, table: containerList}
+	containerList.onColumnsChanged = poller.update
+	poller.update()
 	return containerBox
 }
 
-func updateContainerList(data *[]string, list *widget.List, cli *client.Client) {
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
+func formatContainerRow(c dashboard.Container) string {
+	return fmt.Sprintf("ID:%s | Image:%s | Status:%s", c.ShortID(), c.Image, c.Status)
+}
+
+// updateContainerList relists the containers of svc with the tab's filters.
+func updateContainerList(svc dashboard.DockerService, list *resourceTable[dashboard.Container]) error {
+	containers, err := svc.ListContainers(context.Background(), containerFilters.get())
 	if err != nil {
 		log.Println("Error fetching containers:", err)
-		return
+		return err
 	}
-	*data = make([]string, len(containers))
-	for i, c := range containers {
-		(*data)[i] = fmt.Sprintf("ID:%s | Image:%s | Status:%s", c.ID[:12], c.Image, c.Status)
-	}
-	list.Refresh()
+	list.setItems(containers)
+	return nil
 }
 
-func startSelectedContainer(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
-		return
-	}
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
+func startSelectedContainer(id string, list *resourceTable[dashboard.Container]) {
+	if id == "" {
 		return
 	}
-	if err = cli.ContainerStart(context.Background(), containers[index].ID, dockerContainer.StartOptions{}); err != nil {
-		log.Println("Error starting container:", err)
+	if err := dockerService.StartContainer(context.Background(), id); err != nil {
+		showActionError("Error starting container:", err)
 	}
-	updateContainerList(data, list, cli)
+	updateContainerList(dockerService, list)
 }
 
-func stopSelectedContainer(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
+func stopSelectedContainer(id string, list *resourceTable[dashboard.Container]) {
+	if id == "" {
 		return
 	}
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
-		return
-	}
-	if err = cli.ContainerStop(context.Background(), containers[index].ID, dockerContainer.StopOptions{}); err != nil {
-		log.Println("Error stopping container:", err)
+	if err := dockerService.StopContainer(context.Background(), id); err != nil {
+		showActionError("Error stopping container:", err)
 	}
-	updateContainerList(data, list, cli)
+	updateContainerList(dockerService, list)
 }
 
-func viewContainerLogs(index int, cli *client.Client) {
-	if index == -1 {
-		return
-	}
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
+func removeSelectedContainer(id string, list *resourceTable[dashboard.Container]) {
+	if id == "" {
 		return
 	}
-	reader, err := cli.ContainerLogs(context.Background(), containers[index].ID, dockerContainer.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Tail:       "100",
-	})
-	if err != nil {
-		log.Println("Error fetching logs:", err)
+	if err := dockerService.RemoveContainer(context.Background(), id); err != nil {
+		showActionError("Error removing container:", err)
 		return
 	}
-	defer reader.Close()
-	logsData, err := io.ReadAll(reader)
-	if err != nil {
-		log.Println("Error reading logs:", err)
-		return
-	}
-	showLogsInWindow(string(logsData))
+	updateContainerList(dockerService, list)
 }
 
-func showLogsInWindow(logData string) {
-	win := appInstance.NewWindow("Logs")
-	lbl := widget.NewLabel(logData)
-	lbl.Wrapping = fyne.TextWrapWord
-	scroll := container.NewScroll(lbl)
-	scroll.SetMinSize(fyne.NewSize(600, 400))
-	win.SetContent(scroll)
-	win.Show()
-}
-
-func removeSelectedContainer(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
-		return
-	}
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
-		return
-	}
-	if err = cli.ContainerRemove(context.Background(), containers[index].ID, dockerContainer.RemoveOptions{Force: true}); err != nil {
-		log.Println("Error removing container:", err)
-		return
-	}
-	updateContainerList(data, list, cli)
+func inspectSelectedContainer(id string) {
+	showInspectWindow("container", id)
 }
 
-func inspectSelectedContainer(index int, cli *client.Client) {
-	if index == -1 {
-		return
-	}
-	containers, err := cli.ContainerList(context.Background(), dockerContainer.ListOptions{All: true})
-	if err != nil || index >= len(containers) {
+// copyRunCommand copies a docker run command recreating the container to
+// the clipboard and shows it.
+func copyRunCommand(id string) {
+	if id == "" {
 		return
 	}
-	info, err := cli.ContainerInspect(context.Background(), containers[index].ID)
+	cmd, err := dockerService.RunCommandFor(context.Background(), id)
 	if err != nil {
-		log.Println("Error inspecting container:", err)
+		showActionError("Error building docker run command:", err)
 		return
 	}
-	content := fmt.Sprintf("ID: %s\nImage: %s\nCmd: %v\nState: %v\n", info.ID, info.Image, info.Config.Cmd, info.State)
-	win := appInstance.NewWindow("Inspect Container")
-	win.SetContent(container.NewScroll(widget.NewLabel(content)))
-	win.Resize(fyne.NewSize(600, 400))
-	win.Show()
+	mainWindow.Clipboard().SetContent(cmd)
+	grid := widget.NewTextGridFromString(cmd)
+	d := dialog.NewCustom("Copied docker run command", "Close", container.NewScroll(grid), mainWindow)
+	d.Resize(fyne.NewSize(700, 450))
+	d.Show()
 }
 
-func runAlpineContainer(cli *client.Client, data *[]string, list *widget.List) {
-	image := "alpine"
-	cmd := []string{"echo", "Hello from Alpine!"}
-	if _, err := cli.ImagePull(context.Background(), image, dockerImage.PullOptions{}); err != nil {
-		log.Println("Error pulling Alpine image:", err)
-		return
-	}
-	resp, err := cli.ContainerCreate(context.Background(), &dockerContainer.Config{Image: image, Cmd: cmd}, &dockerContainer.HostConfig{}, nil, nil, "")
+func runAlpineContainer(list *resourceTable[dashboard.Container]) {
+	_, err := dockerService.RunContainer(context.Background(), dashboard.ContainerSpec{
+		Config: &dockerContainer.Config{
+			Image: "alpine",
+			Cmd:   []string{"echo", "Hello from Alpine!"},
+		},
+	})
 	if err != nil {
-		log.Println("Error creating Alpine container:", err)
-		return
-	}
-	if err = cli.ContainerStart(context.Background(), resp.ID, dockerContainer.StartOptions{}); err != nil {
-		log.Println("Error starting Alpine container:", err)
+		log.Println("Error running Alpine container:", err)
 		return
 	}
-	updateContainerList(data, list, cli)
-}
-
-func showCustomContainerForm(cli *client.Client, data *[]string, list *widget.List) {
-	win := appInstance.NewWindow("Run Custom Container")
-	win.Resize(fyne.NewSize(400, 300))
-	imageEntry := widget.NewEntry()
-	imageEntry.SetText("alpine")
-	cmdEntry := widget.NewEntry()
-	cmdEntry.SetText("echo hello world")
-	envEntry := widget.NewEntry()
-	envEntry.SetText("KEY=value,FOO=bar")
-	portsEntry := widget.NewEntry()
-	portsEntry.SetText("8080:80")
-	form := widget.NewForm(
-		widget.NewFormItem("Image", imageEntry),
-		widget.NewFormItem("Command", cmdEntry),
-		widget.NewFormItem("Env (comma-separated)", envEntry),
-		widget.NewFormItem("Ports (comma-separated, e.g. 8080:80)", portsEntry),
-	)
-	form.OnSubmit = func() {
-		image := imageEntry.Text
-		cmdParts := strings.Fields(cmdEntry.Text)
-		var envVars []string
-		if envEntry.Text != "" {
-			envVars = strings.Split(envEntry.Text, ",")
-		}
-		portBindings := nat.PortMap{}
-		if portsEntry.Text != "" {
-			pairs := strings.Split(portsEntry.Text, ",")
-			for _, p := range pairs {
-				p = strings.TrimSpace(p)
-				parts := strings.Split(p, ":")
-				if len(parts) == 2 {
-					hostPort := parts[0]
-					containerPort := parts[1]
-					port := nat.Port(containerPort + "/tcp")
-					portBindings[port] = []nat.PortBinding{{HostIP: "", HostPort: hostPort}}
-				}
-			}
-		}
-		if _, err := cli.ImagePull(context.Background(), image, dockerImage.PullOptions{}); err != nil {
-			dialog.ShowError(err, win)
-			return
-		}
-		resp, err := cli.ContainerCreate(context.Background(),
-			&dockerContainer.Config{
-				Image: image,
-				Cmd:   cmdParts,
-				Env:   envVars,
-			},
-			&dockerContainer.HostConfig{PortBindings: portBindings},
-			nil, nil, "",
-		)
-		if err != nil {
-			dialog.ShowError(err, win)
-			return
-		}
-		if err := cli.ContainerStart(context.Background(), resp.ID, dockerContainer.StartOptions{}); err != nil {
-			dialog.ShowError(err, win)
-			return
-		}
-		updateContainerList(data, list, cli)
-		win.Close()
-	}
-	win.SetContent(form)
-	win.Show()
+	updateContainerList(dockerService, list)
 }
 
 // =============================================================================
 // Images Tab
 // =============================================================================
 
-func buildImagesTab(cli *client.Client) fyne.CanvasObject {
-	var imagesData []string
-	imagesList := widget.NewList(
-		func() int { return len(imagesData) },
-		func() fyne.CanvasObject {
-			lbl := widget.NewLabel("")
-			lbl.Wrapping = fyne.TextWrapWord
-			return lbl
-		},
-		func(i int, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(imagesData[i])
-		},
-	)
-	imagesList.OnSelected = func(id int) {
-		selectedImageIndex = id
-		fmt.Println("Selected image:", imagesData[id])
-	}
-	scrollableImagesList := container.NewScroll(imagesList)
-	scrollableImagesList.SetMinSize(fyne.NewSize(1000, 500))
-	refreshBtn := widget.NewButton("Refresh", func() {
-		updateImagesList(&imagesData, imagesList, cli)
-	})
+func buildImagesTab() fyne.CanvasObject {
+	imagesList := newResourceTable("images",
+		func(img dashboard.Image) string { return img.ID },
+		imageColumns(), defaultImageColumns)
+	imagesList.onSelected = func(img dashboard.Image) {
+		selectedImageID = img.ID
+		fmt.Println("Selected image:", formatImageRow(img))
+	}
+	reload := func() error { return updateImagesList(dockerService, imagesList) }
+	refresh := func() { reload() }
+	filterBar := newFilterBar("images", imageFilterFields, imageFilters, reload)
+	refreshBtn := widget.NewButton("Refresh", refresh)
 	pullBtn := widget.NewButton("Pull Image", func() {
-		showPullImageDialog(cli, &imagesData, imagesList)
+		showPullImageDialog(imagesList)
+	})
+	buildBtn := widget.NewButton("Build Image", func() {
+		showBuildImageDialog(imagesList)
 	})
 	removeBtn := widget.NewButton("Remove Image", func() {
-		removeSelectedImage(selectedImageIndex, cli, &imagesData, imagesList)
+		removeSelectedImage(selectedImageID, imagesList)
+	})
+	pushBtn := widget.NewButton("Push Image", func() {
+		if img, ok := imagesList.lookup(selectedImageID); ok {
+			showPushImageDialog(img)
+		}
+	})
+	inspectBtn := widget.NewButton("Inspect Image", func() {
+		showInspectWindow("image", selectedImageID)
+	})
+	bulkRemoveBtn := newBulkRemoveButton(imagesList, "images", func(img dashboard.Image) bulkTarget {
+		return bulkTarget{id: img.ID, name: imageName(img)}
+	}, func(ctx context.Context, id string) error { return dockerService.RemoveImage(ctx, id) }, refresh)
+	topRow := container.NewHBox(refreshBtn, pullBtn, buildBtn, pushBtn, removeBtn, inspectBtn)
+	box := container.NewBorder(container.NewVBox(filterBar.accordion, imagesList.toolbar()), container.NewVBox(imagesList.selectionBar(bulkRemoveBtn), topRow), nil, nil, imagesList.table)
+	updateImagesList(dockerService, imagesList)
+	onEvent(events.ImageEventType, func(svc dashboard.DockerService, ev dashboard.Event) {
+		applyImageEvent(svc, ev, imagesList)
 	})
-	topRow := container.NewHBox(refreshBtn, pullBtn, removeBtn)
-	box := container.NewVBox(scrollableImagesList, topRow)
-	updateImagesList(&imagesData, imagesList, cli)
 	return box
 }
 
-func updateImagesList(data *[]string, list *widget.List, cli *client.Client) {
-	images, err := cli.ImageList(context.Background(), dockerImage.ListOptions{})
-	if err != nil {
-		log.Println("Error fetching images:", err)
-		return
-	}
-	*data = make([]string, len(images))
-	for i, img := range images {
-		shortID := ""
-		if len(img.ID) > 12 {
-			shortID = img.ID[7:19]
-		}
-		(*data)[i] = fmt.Sprintf("ID:%s | Tags:%v | Size:%d", shortID, img.RepoTags, img.Size)
-	}
-	list.Refresh()
+func formatImageRow(img dashboard.Image) string {
+	return fmt.Sprintf("ID:%s | Tags:%v | Size:%d", img.ShortID(), img.RepoTags, img.Size)
 }
 
-func showPullImageDialog(cli *client.Client, data *[]string, list *widget.List) {
-	win := appInstance.NewWindow("Pull Image")
-	entry := widget.NewEntry()
-	entry.SetText("alpine")
-	form := widget.NewForm(
-		widget.NewFormItem("Image Name (e.g. alpine:latest)", entry),
-	)
-	form.OnSubmit = func() {
-		imageName := entry.Text
-		_, err := cli.ImagePull(context.Background(), imageName, dockerImage.PullOptions{})
-		if err != nil {
-			dialog.ShowError(err, win)
-			return
-		}
-		updateImagesList(data, list, cli)
-		win.Close()
+// imageName returns an image's first tag, or its short ID when untagged.
+func imageName(img dashboard.Image) string {
+	if len(img.RepoTags) > 0 {
+		return img.RepoTags[0]
 	}
-	win.SetContent(form)
-	win.Resize(fyne.NewSize(300, 150))
-	win.Show()
+	return img.ShortID()
 }
 
-func removeSelectedImage(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
-		return
+// updateImagesList relists the images of svc with the tab's filters.
+func updateImagesList(svc dashboard.DockerService, list *resourceTable[dashboard.Image]) error {
+	images, err := svc.ListImages(context.Background(), imageFilters.get())
+	if err != nil {
+		log.Println("Error fetching images:", err)
+		return err
 	}
-	images, err := cli.ImageList(context.Background(), dockerImage.ListOptions{})
-	if err != nil || index >= len(images) {
+	list.setItems(images)
+	return nil
+}
+
+func removeSelectedImage(id string, list *resourceTable[dashboard.Image]) {
+	if id == "" {
 		return
 	}
-	_, err = cli.ImageRemove(context.Background(), images[index].ID, dockerImage.RemoveOptions{Force: true})
-	if err != nil {
-		log.Println("Error removing image:", err)
+	if err := dockerService.RemoveImage(context.Background(), id); err != nil {
+		showActionError("Error removing image:", err)
 		return
 	}
-	updateImagesList(data, list, cli)
+	updateImagesList(dockerService, list)
 }
 
 // =============================================================================
 // Volumes Tab
 // =============================================================================
 
-func buildVolumesTab(cli *client.Client) fyne.CanvasObject {
-	var volumesData []string
-	volumesList := widget.NewList(
-		func() int { return len(volumesData) },
-		func() fyne.CanvasObject { return widget.NewLabel("") },
-		func(i int, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(volumesData[i])
-		},
-	)
-	volumesList.OnSelected = func(id int) {
-		selectedVolumeIndex = id
-		fmt.Println("Selected volume:", volumesData[id])
-	}
-	refreshBtn := widget.NewButton("Refresh", func() {
-		updateVolumesList(&volumesData, volumesList, cli)
-	})
+func buildVolumesTab() fyne.CanvasObject {
+	volumesList := newResourceTable("volumes",
+		func(v dashboard.Volume) string { return v.Name },
+		volumeColumns(), defaultVolumeColumns)
+	volumesList.onSelected = func(v dashboard.Volume) {
+		selectedVolumeName = v.Name
+		fmt.Println("Selected volume:", formatVolumeRow(v))
+	}
+	reload := func() error { return updateVolumesList(dockerService, volumesList) }
+	refresh := func() { reload() }
+	filterBar := newFilterBar("volumes", volumeFilterFields, volumeFilters, reload)
+	refreshBtn := widget.NewButton("Refresh", refresh)
 	createBtn := widget.NewButton("Create Volume", func() {
-		showCreateVolumeDialog(cli, &volumesData, volumesList)
+		showCreateVolumeDialog(volumesList)
 	})
 	removeBtn := widget.NewButton("Remove Volume", func() {
-		removeSelectedVolume(selectedVolumeIndex, cli, &volumesData, volumesList)
+		removeSelectedVolume(selectedVolumeName, volumesList)
+	})
+	inspectBtn := widget.NewButton("Inspect Volume", func() {
+		showInspectWindow("volume", selectedVolumeName)
+	})
+	bulkRemoveBtn := newBulkRemoveButton(volumesList, "volumes", func(v dashboard.Volume) bulkTarget {
+		return bulkTarget{id: v.Name, name: v.Name}
+	}, func(ctx context.Context, id string) error { return dockerService.RemoveVolume(ctx, id) }, refresh)
+	topRow := container.NewHBox(refreshBtn, createBtn, removeBtn, inspectBtn)
+	box := container.NewBorder(container.NewVBox(filterBar.accordion, volumesList.toolbar()), container.NewVBox(volumesList.selectionBar(bulkRemoveBtn), topRow), nil, nil, volumesList.table)
+	updateVolumesList(dockerService, volumesList)
+	onEvent(events.VolumeEventType, func(svc dashboard.DockerService, ev dashboard.Event) {
+		applyVolumeEvent(svc, ev, volumesList)
 	})
-	scrollableVolumesList := container.NewScroll(volumesList)
-	scrollableVolumesList.SetMinSize(fyne.NewSize(1000, 500))
-	topRow := container.NewHBox(refreshBtn, createBtn, removeBtn)
-	box := container.NewVBox(scrollableVolumesList, topRow)
-	updateVolumesList(&volumesData, volumesList, cli)
 	return box
 }
 
-func updateVolumesList(data *[]string, list *widget.List, cli *client.Client) {
-	volList, err := cli.VolumeList(context.Background(), volume.ListOptions{Filters: filters.NewArgs()})
+func formatVolumeRow(v dashboard.Volume) string {
+	return fmt.Sprintf("Name:%s | Driver:%s | Mountpoint:%s", v.Name, v.Driver, v.Mountpoint)
+}
+
+// updateVolumesList relists the volumes of svc with the tab's filters.
+func updateVolumesList(svc dashboard.DockerService, list *resourceTable[dashboard.Volume]) error {
+	volumes, err := svc.ListVolumes(context.Background(), volumeFilters.get())
 	if err != nil {
 		log.Println("Error fetching volumes:", err)
-		return
-	}
-	*data = make([]string, len(volList.Volumes))
-	for i, v := range volList.Volumes {
-		(*data)[i] = fmt.Sprintf("Name:%s | Driver:%s | Mountpoint:%s", v.Name, v.Driver, v.Mountpoint)
+		return err
 	}
-	list.Refresh()
+	list.setItems(volumes)
+	return nil
 }
 
-func showCreateVolumeDialog(cli *client.Client, data *[]string, list *widget.List) {
+func showCreateVolumeDialog(list *resourceTable[dashboard.Volume]) {
 	win := appInstance.NewWindow("Create Volume")
 	nameEntry := widget.NewEntry()
 	form := widget.NewForm(
@@ -838,12 +608,11 @@ func showCreateVolumeDialog(cli *client.Client, data *[]string, list *widget.Lis
 	)
 	form.OnSubmit = func() {
 		volName := nameEntry.Text
-		_, err := cli.VolumeCreate(context.Background(), volume.CreateOptions{Name: volName})
-		if err != nil {
+		if _, err := dockerService.CreateVolume(context.Background(), volName); err != nil {
 			dialog.ShowError(err, win)
 			return
 		}
-		updateVolumesList(data, list, cli)
+		updateVolumesList(dockerService, list)
 		win.Close()
 	}
 	win.SetContent(form)
@@ -851,70 +620,70 @@ func showCreateVolumeDialog(cli *client.Client, data *[]string, list *widget.Lis
 	win.Show()
 }
 
-func removeSelectedVolume(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
-		return
-	}
-	volList, err := cli.VolumeList(context.Background(), volume.ListOptions{Filters: filters.NewArgs()})
-	if err != nil || index >= len(volList.Volumes) {
+func removeSelectedVolume(name string, list *resourceTable[dashboard.Volume]) {
+	if name == "" {
 		return
 	}
-	volName := volList.Volumes[index].Name
-	if err = cli.VolumeRemove(context.Background(), volName, true); err != nil {
-		log.Println("Error removing volume:", err)
+	if err := dockerService.RemoveVolume(context.Background(), name); err != nil {
+		showActionError("Error removing volume:", err)
 		return
 	}
-	updateVolumesList(data, list, cli)
+	updateVolumesList(dockerService, list)
 }
 
 // =============================================================================
 // Networks Tab
 // =============================================================================
 
-func buildNetworksTab(cli *client.Client) fyne.CanvasObject {
-	var networksData []string
-	networksList := widget.NewList(
-		func() int { return len(networksData) },
-		func() fyne.CanvasObject { return widget.NewLabel("") },
-		func(i int, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(networksData[i])
-		},
-	)
-	networksList.OnSelected = func(id int) {
-		selectedNetworkIndex = id
-		fmt.Println("Selected network:", networksData[id])
-	}
-	refreshBtn := widget.NewButton("Refresh", func() {
-		updateNetworksList(&networksData, networksList, cli)
-	})
+func buildNetworksTab() fyne.CanvasObject {
+	networksList := newResourceTable("networks",
+		func(n dashboard.Network) string { return n.ID },
+		networkColumns(), defaultNetworkColumns)
+	networksList.onSelected = func(n dashboard.Network) {
+		selectedNetworkID = n.ID
+		fmt.Println("Selected network:", formatNetworkRow(n))
+	}
+	reload := func() error { return updateNetworksList(dockerService, networksList) }
+	refresh := func() { reload() }
+	filterBar := newFilterBar("networks", networkFilterFields, networkFilters, reload)
+	refreshBtn := widget.NewButton("Refresh", refresh)
 	createBtn := widget.NewButton("Create Network", func() {
-		showCreateNetworkDialog(cli, &networksData, networksList)
+		showCreateNetworkDialog(networksList)
 	})
 	removeBtn := widget.NewButton("Remove Network", func() {
-		removeSelectedNetwork(selectedNetworkIndex, cli, &networksData, networksList)
+		removeSelectedNetwork(selectedNetworkID, networksList)
+	})
+	inspectBtn := widget.NewButton("Inspect Network", func() {
+		showInspectWindow("network", selectedNetworkID)
+	})
+	bulkRemoveBtn := newBulkRemoveButton(networksList, "networks", func(n dashboard.Network) bulkTarget {
+		return bulkTarget{id: n.ID, name: n.Name}
+	}, func(ctx context.Context, id string) error { return dockerService.RemoveNetwork(ctx, id) }, refresh)
+	topRow := container.NewHBox(refreshBtn, createBtn, removeBtn, inspectBtn)
+	box := container.NewBorder(container.NewVBox(filterBar.accordion, networksList.toolbar()), container.NewVBox(networksList.selectionBar(bulkRemoveBtn), topRow), nil, nil, networksList.table)
+	updateNetworksList(dockerService, networksList)
+	onEvent(events.NetworkEventType, func(svc dashboard.DockerService, ev dashboard.Event) {
+		applyNetworkEvent(svc, ev, networksList)
 	})
-	scrollableNetworksList := container.NewScroll(networksList)
-	scrollableNetworksList.SetMinSize(fyne.NewSize(1000, 500))
-	topRow := container.NewHBox(refreshBtn, createBtn, removeBtn)
-	box := container.NewVBox(scrollableNetworksList, topRow)
-	updateNetworksList(&networksData, networksList, cli)
 	return box
 }
 
-func updateNetworksList(data *[]string, list *widget.List, cli *client.Client) {
-	nets, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
+func formatNetworkRow(n dashboard.Network) string {
+	return fmt.Sprintf("Name:%s | ID:%s | Scope:%s | Driver:%s", n.Name, n.ShortID(), n.Scope, n.Driver)
+}
+
+// updateNetworksList relists the networks of svc with the tab's filters.
+func updateNetworksList(svc dashboard.DockerService, list *resourceTable[dashboard.Network]) error {
+	nets, err := svc.ListNetworks(context.Background(), networkFilters.get())
 	if err != nil {
 		log.Println("Error fetching networks:", err)
-		return
+		return err
 	}
-	*data = make([]string, len(nets))
-	for i, net := range nets {
-		(*data)[i] = fmt.Sprintf("Name:%s | ID:%s | Scope:%s | Driver:%s", net.Name, net.ID[:12], net.Scope, net.Driver)
-	}
-	list.Refresh()
+	list.setItems(nets)
+	return nil
 }
 
-func showCreateNetworkDialog(cli *client.Client, data *[]string, list *widget.List) {
+func showCreateNetworkDialog(list *resourceTable[dashboard.Network]) {
 	win := appInstance.NewWindow("Create Network")
 	nameEntry := widget.NewEntry()
 	driverEntry := widget.NewEntry()
@@ -934,7 +703,8 @@ func showCreateNetworkDialog(cli *client.Client, data *[]string, list *widget.Li
 		if driver == "macvlan" && macvlanEntry.Text != "" {
 			options["parent"] = macvlanEntry.Text
 		}
-		resp, err := cli.NetworkCreate(context.Background(), netName, dockerNetwork.CreateOptions{
+		id, err := dockerService.CreateNetwork(context.Background(), dashboard.NetworkSpec{
+			Name:    netName,
 			Driver:  driver,
 			Options: options,
 		})
@@ -942,8 +712,8 @@ func showCreateNetworkDialog(cli *client.Client, data *[]string, list *widget.Li
 			dialog.ShowError(err, win)
 			return
 		}
-		fmt.Println("Created network:", resp.ID)
-		updateNetworksList(data, list, cli)
+		fmt.Println("Created network:", id)
+		updateNetworksList(dockerService, list)
 		win.Close()
 	}
 	form.OnCancel = func() { win.Close() }
@@ -952,18 +722,13 @@ func showCreateNetworkDialog(cli *client.Client, data *[]string, list *widget.Li
 	win.Show()
 }
 
-func removeSelectedNetwork(index int, cli *client.Client, data *[]string, list *widget.List) {
-	if index == -1 {
-		return
-	}
-	nets, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
-	if err != nil || index >= len(nets) {
+func removeSelectedNetwork(id string, list *resourceTable[dashboard.Network]) {
+	if id == "" {
 		return
 	}
-	netID := nets[index].ID
-	if err = cli.NetworkRemove(context.Background(), netID); err != nil {
-		log.Println("Error removing network:", err)
+	if err := dockerService.RemoveNetwork(context.Background(), id); err != nil {
+		showActionError("Error removing network:", err)
 		return
 	}
-	updateNetworksList(data, list, cli)
+	updateNetworksList(dockerService, list)
 }
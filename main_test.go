@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/docker/docker/api/types/filters"
+
+	"sprint/dashboard"
+)
+
+// fakeService is a DockerService holding containers in memory. Calls it
+// does not implement panic on the nil embedded service.
+type fakeService struct {
+	dashboard.DockerService
+	containers []dashboard.Container
+	lists      int // ListContainers calls
+}
+
+func (f *fakeService) ListContainers(context.Context, filters.Args) ([]dashboard.Container, error) {
+	f.lists++
+	return append([]dashboard.Container(nil), f.containers...), nil
+}
+
+func (f *fakeService) GetContainer(_ context.Context, id string) (dashboard.Container, error) {
+	for _, c := range f.containers {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return dashboard.Container{}, &dashboard.NotFoundError{Kind: "container", ID: id}
+}
+
+func TestMain(m *testing.M) {
+	appInstance = test.NewApp()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types/events"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Projects Tab (Docker Compose)
+// =============================================================================
+
+const prefProjectFiles = "projects.files"
+
+// composeEntry is a compose file opened in the Projects tab. project is nil
+// when the file failed to load.
+type composeEntry struct {
+	path    string
+	project *dashboard.ComposeProject
+	err     error
+}
+
+func (e *composeEntry) label() string {
+	if e.project == nil {
+		return filepath.Base(e.path) + " (error)"
+	}
+	return e.project.Name + "  " + e.path
+}
+
+// projectsView is the state behind the Projects tab. Container events and
+// project operations update it from their own goroutines while the lists
+// draw it, so the state below mu is only touched with mu held, and widgets
+// are only refreshed after releasing it.
+type projectsView struct {
+	projectList, serviceList *widget.List
+	errorLabel               *widget.Label
+	progress                 *widget.TextGrid
+	upBtn, downBtn           *widget.Button
+
+	mu            sync.Mutex
+	entries       []*composeEntry
+	selected      int
+	statuses      []dashboard.ServiceStatus
+	progressLines []string
+}
+
+func buildProjectsTab() fyne.CanvasObject {
+	v := &projectsView{selected: -1}
+	for _, path := range appInstance.Preferences().StringList(prefProjectFiles) {
+		v.entries = append(v.entries, loadComposeEntry(path))
+	}
+
+	v.projectList = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.entries)
+		},
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i int, obj fyne.CanvasObject) {
+			v.mu.Lock()
+			text := ""
+			if i < len(v.entries) {
+				text = v.entries[i].label()
+			}
+			v.mu.Unlock()
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	v.serviceList = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.statuses)
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i int, obj fyne.CanvasObject) {
+			v.mu.Lock()
+			text := ""
+			if i < len(v.statuses) {
+				text = formatServiceRow(v.statuses[i])
+			}
+			v.mu.Unlock()
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	v.errorLabel = widget.NewLabel("")
+	v.errorLabel.Wrapping = fyne.TextWrapWord
+	v.progress = widget.NewTextGrid()
+
+	v.projectList.OnSelected = func(i int) {
+		v.mu.Lock()
+		v.selected = i
+		v.mu.Unlock()
+		v.refreshStatus(dockerService)
+	}
+
+	openBtn := widget.NewButton("Open Compose File...", func() {
+		open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			if r == nil {
+				return
+			}
+			r.Close()
+			v.open(r.URI().Path())
+		}, mainWindow)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+		open.Show()
+	})
+	reloadBtn := widget.NewButton("Reload", func() {
+		e := v.current()
+		if e == nil {
+			return
+		}
+		reloaded := loadComposeEntry(e.path)
+		v.mu.Lock()
+		if i := slices.Index(v.entries, e); i >= 0 {
+			v.entries[i] = reloaded
+		}
+		v.mu.Unlock()
+		v.projectList.Refresh()
+		v.refreshStatus(dockerService)
+	})
+	closeBtn := widget.NewButton("Close Project", func() {
+		v.mu.Lock()
+		if v.selectedEntry() == nil {
+			v.mu.Unlock()
+			return
+		}
+		v.entries = slices.Delete(v.entries, v.selected, v.selected+1)
+		v.selected = -1
+		v.mu.Unlock()
+		v.savePaths()
+		v.projectList.UnselectAll()
+		v.projectList.Refresh()
+		v.refreshStatus(dockerService)
+	})
+
+	v.upBtn = widget.NewButton("Up", func() {
+		e := v.current()
+		if e == nil || e.project == nil {
+			return
+		}
+		v.run(func(progress func(string)) error {
+			return dockerService.ComposeUp(context.Background(), e.project, progress)
+		})
+	})
+	v.downBtn = widget.NewButton("Down", func() {
+		e := v.current()
+		if e == nil || e.project == nil {
+			return
+		}
+		volumesCheck := widget.NewCheck("Also remove the project's volumes", nil)
+		content := container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Stop and remove the containers and networks of %s?", e.project.Name)),
+			volumesCheck,
+		)
+		dialog.ShowCustomConfirm("Down", "Down", "Cancel", content, func(ok bool) {
+			if !ok {
+				return
+			}
+			v.run(func(progress func(string)) error {
+				return dockerService.ComposeDown(context.Background(), e.project.Name, volumesCheck.Checked, progress)
+			})
+		}, mainWindow)
+	})
+	refreshBtn := widget.NewButton("Refresh", func() { v.refreshStatus(dockerService) })
+
+	onEvent(events.ContainerEventType, func(svc dashboard.DockerService, _ dashboard.Event) {
+		if v.current() != nil {
+			v.refreshStatus(svc)
+		}
+	})
+
+	left := container.NewBorder(nil, container.NewHBox(openBtn, reloadBtn, closeBtn), nil, nil, v.projectList)
+	right := container.NewVSplit(
+		container.NewBorder(container.NewVBox(container.NewHBox(v.upBtn, v.downBtn, refreshBtn), v.errorLabel), nil, nil, nil, v.serviceList),
+		container.NewScroll(v.progress),
+	)
+	right.SetOffset(0.6)
+	split := container.NewHSplit(left, right)
+	split.SetOffset(0.35)
+	return split
+}
+
+// current returns the selected project, or nil.
+func (v *projectsView) current() *composeEntry {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.selectedEntry()
+}
+
+// selectedEntry is current for callers holding mu.
+func (v *projectsView) selectedEntry() *composeEntry {
+	if v.selected < 0 || v.selected >= len(v.entries) {
+		return nil
+	}
+	return v.entries[v.selected]
+}
+
+// open adds the compose file at path, or reloads it when already open, and
+// selects it.
+func (v *projectsView) open(path string) {
+	entry := loadComposeEntry(path)
+	v.mu.Lock()
+	i := slices.IndexFunc(v.entries, func(e *composeEntry) bool { return e.path == path })
+	if i >= 0 {
+		v.entries[i] = entry
+	} else {
+		v.entries = append(v.entries, entry)
+		i = len(v.entries) - 1
+	}
+	v.mu.Unlock()
+	v.savePaths()
+	v.projectList.Refresh()
+	v.projectList.Select(i)
+}
+
+func (v *projectsView) savePaths() {
+	v.mu.Lock()
+	paths := make([]string, len(v.entries))
+	for i, e := range v.entries {
+		paths[i] = e.path
+	}
+	v.mu.Unlock()
+	appInstance.Preferences().SetStringList(prefProjectFiles, paths)
+}
+
+// refreshStatus shows the state of the selected project's services as
+// svc reports them.
+func (v *projectsView) refreshStatus(svc dashboard.DockerService) {
+	e := v.current()
+	var statuses []dashboard.ServiceStatus
+	if e != nil && e.project != nil {
+		var err error
+		statuses, err = svc.ComposeStatus(context.Background(), e.project)
+		if err != nil {
+			log.Println("Error fetching project status:", err)
+		}
+	}
+	v.mu.Lock()
+	if v.selectedEntry() != e {
+		// Another project was selected meanwhile; its own refresh shows it.
+		v.mu.Unlock()
+		return
+	}
+	v.statuses = statuses
+	v.mu.Unlock()
+
+	errText := ""
+	if e != nil && e.err != nil {
+		errText = e.err.Error()
+	}
+	v.errorLabel.SetText(errText)
+	v.serviceList.Refresh()
+}
+
+// addProgress appends a line to the progress view, keeping the last 200.
+func (v *projectsView) addProgress(line string) {
+	v.mu.Lock()
+	v.progressLines = append(v.progressLines, line)
+	if len(v.progressLines) > 200 {
+		v.progressLines = v.progressLines[len(v.progressLines)-200:]
+	}
+	text := strings.Join(v.progressLines, "\n")
+	v.mu.Unlock()
+	v.progress.SetText(text)
+}
+
+// run performs a project operation in the background with the buttons
+// disabled, streaming its steps into the progress view.
+func (v *projectsView) run(op func(progress func(string)) error) {
+	v.upBtn.Disable()
+	v.downBtn.Disable()
+	v.mu.Lock()
+	v.progressLines = nil
+	v.mu.Unlock()
+	svc := dockerService
+	go func() {
+		err := op(v.addProgress)
+		if err != nil {
+			log.Println("Error running project:", err)
+			v.addProgress("Error: " + err.Error())
+			dialog.ShowError(err, mainWindow)
+		}
+		v.upBtn.Enable()
+		v.downBtn.Enable()
+		v.refreshStatus(svc)
+	}()
+}
+
+func loadComposeEntry(path string) *composeEntry {
+	p, err := dashboard.LoadComposeFile(path)
+	if err != nil {
+		log.Println("Error loading compose file:", err)
+	}
+	return &composeEntry{path: path, project: p, err: err}
+}
+
+func formatServiceRow(s dashboard.ServiceStatus) string {
+	row := fmt.Sprintf("%s | %s", s.Service, s.State)
+	if s.Health != "" {
+		row += " (" + s.Health + ")"
+	}
+	if s.Container != "" {
+		row += " | " + s.Container + " | " + s.Status
+	}
+	return row
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/go-units"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Image Pull
+// =============================================================================
+
+// pullRedrawInterval limits how often the progress display is redrawn; the
+// daemon sends many messages per second on a fast link.
+const pullRedrawInterval = 100 * time.Millisecond
+
+// pullPlatforms are the platforms offered by the pull window.
+var pullPlatforms = []string{"linux/amd64", "linux/arm64", "linux/arm/v7", "linux/386", "windows/amd64"}
+
+// pullLayerRow shows the progress of one layer.
+type pullLayerRow struct {
+	bar    *widget.ProgressBar
+	status *widget.Label
+}
+
+// showPullImageDialog pulls an image with per-layer progress, refreshing
+// the images list when done. The pull can be cancelled.
+func showPullImageDialog(list *resourceTable[dashboard.Image]) {
+	win := appInstance.NewWindow("Pull Image")
+	refEntry := widget.NewEntry()
+	refEntry.SetText("alpine")
+	platformEntry := widget.NewSelectEntry(pullPlatforms)
+	platformEntry.SetPlaceHolder("Optional: daemon default")
+	digestEntry := widget.NewEntry()
+	digestEntry.SetPlaceHolder("Optional: sha256:...")
+	searchBtn := widget.NewButton("Search...", func() {
+		showImageSearch(win, refEntry.SetText)
+	})
+	form := widget.NewForm(
+		widget.NewFormItem("Image Name (e.g. alpine:latest)", container.NewBorder(nil, nil, nil, searchBtn, refEntry)),
+		widget.NewFormItem("Platform", platformEntry),
+		widget.NewFormItem("Pin Digest", digestEntry),
+	)
+
+	overall := widget.NewProgressBar()
+	bytesLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+	layersBox := container.NewVBox()
+	pinnedLabel := widget.NewLabel("")
+	pinnedLabel.Wrapping = fyne.TextWrapBreak
+	copyPinnedBtn := widget.NewButton("Copy Pinned Reference", nil)
+	copyPinnedBtn.Hide()
+
+	pullBtn := widget.NewButton("Pull", nil)
+	pullBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("Cancel", nil)
+	cancelBtn.Disable()
+	closeBtn := widget.NewButton("Close", func() { win.Close() })
+
+	var cancel context.CancelFunc
+	win.SetOnClosed(func() {
+		// Closing the window abandons the pull.
+		if cancel != nil {
+			cancel()
+		}
+	})
+	cancelBtn.OnTapped = func() {
+		if cancel != nil {
+			cancel()
+		}
+	}
+
+	pullBtn.OnTapped = func() {
+		opts := dashboard.PullOptions{
+			Platform: strings.TrimSpace(platformEntry.Text),
+			Digest:   strings.TrimSpace(digestEntry.Text),
+		}
+		ref, err := dashboard.PullReference(refEntry.Text, opts)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		// The form cannot reach the entry inside the search row.
+		form.Disable()
+		refEntry.Disable()
+		searchBtn.Disable()
+		pullBtn.Disable()
+		cancelBtn.Enable()
+		copyPinnedBtn.Hide()
+		pinnedLabel.SetText("")
+		layersBox.RemoveAll()
+		overall.SetValue(0)
+		statusLabel.SetText("Pulling " + ref + "...")
+
+		rows := map[string]*pullLayerRow{}
+		var lastDraw time.Time
+		draw := func(p *dashboard.PullProgress) {
+			for _, l := range p.Layers {
+				row := rows[l.ID]
+				if row == nil {
+					row = &pullLayerRow{bar: widget.NewProgressBar(), status: widget.NewLabel("")}
+					rows[l.ID] = row
+					layersBox.Add(container.NewBorder(nil, nil, widget.NewLabel(l.ID), row.status, row.bar))
+				}
+				updateLayerRow(row, l)
+			}
+			downloaded, total, known := p.Bytes()
+			if total > 0 {
+				overall.SetValue(float64(downloaded) / float64(total))
+			}
+			bytesLabel.SetText(pullBytesText(p, downloaded, total, known))
+			if p.Status != "" {
+				statusLabel.SetText(p.Status)
+			}
+		}
+
+		go func() {
+			progress, err := dockerService.PullImageWithProgress(ctx, refEntry.Text, opts, func(p *dashboard.PullProgress) {
+				if now := time.Now(); now.Sub(lastDraw) >= pullRedrawInterval {
+					lastDraw = now
+					draw(p)
+				}
+			})
+			cancel()
+			if progress != nil {
+				draw(progress)
+			}
+			form.Enable()
+			refEntry.Enable()
+			searchBtn.Enable()
+			pullBtn.Enable()
+			cancelBtn.Disable()
+			switch {
+			case errors.Is(err, context.Canceled):
+				statusLabel.SetText("Pull of " + ref + " cancelled")
+				return
+			case err != nil:
+				log.Println("Error pulling image:", err)
+				statusLabel.SetText("Pull of " + ref + " failed")
+				dialog.ShowError(err, win)
+				return
+			}
+			overall.SetValue(1)
+			updateImagesList(dockerService, list)
+			if progress.Digest == "" {
+				return
+			}
+			pinned, err := dashboard.PullReference(ref, dashboard.PullOptions{Digest: progress.Digest})
+			if err != nil {
+				log.Println("Error pinning image reference:", err)
+				return
+			}
+			pinnedLabel.SetText("Pinned: " + pinned)
+			copyPinnedBtn.OnTapped = func() { win.Clipboard().SetContent(pinned) }
+			copyPinnedBtn.Show()
+		}()
+	}
+	refEntry.OnSubmitted = func(string) {
+		if !pullBtn.Disabled() {
+			pullBtn.OnTapped()
+		}
+	}
+
+	top := container.NewVBox(form, container.NewHBox(pullBtn, cancelBtn, closeBtn), overall, bytesLabel, statusLabel)
+	bottom := container.NewBorder(nil, nil, nil, copyPinnedBtn, pinnedLabel)
+	win.SetContent(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(layersBox)))
+	win.Resize(fyne.NewSize(650, 500))
+	win.Show()
+}
+
+func updateLayerRow(row *pullLayerRow, l *dashboard.LayerProgress) {
+	text := l.Status
+	switch {
+	case l.Total > 0:
+		row.bar.SetValue(float64(l.Current) / float64(l.Total))
+		text += fmt.Sprintf(" %s / %s", units.HumanSize(float64(l.Current)), units.HumanSize(float64(l.Total)))
+	case l.Done:
+		row.bar.SetValue(1)
+	}
+	row.status.SetText(text)
+}
+
+// pullBytesText summarises a pull's download, e.g. "12MB of 30MB, 8
+// seconds left". The total is marked with "+" while some layer sizes are
+// not known yet.
+func pullBytesText(p *dashboard.PullProgress, downloaded, total int64, known bool) string {
+	if total == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("%s of %s", units.HumanSize(float64(downloaded)), units.HumanSize(float64(total)))
+	if !known {
+		text += "+"
+	}
+	if eta, ok := p.ETA(time.Now()); ok && downloaded < total {
+		text += ", " + units.HumanDuration(eta) + " left"
+	}
+	return text
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Registries (Settings Tab)
+// =============================================================================
+
+// newRegistryAuth returns the credential sources attached to registry
+// calls: the dashboard's encrypted store in the app's storage directory,
+// then the Docker CLI's config.json.
+func newRegistryAuth() *dashboard.RegistryAuth {
+	return &dashboard.RegistryAuth{
+		Store:      dashboard.NewCredentialStore(appInstance.Storage().RootURI().Path()),
+		ConfigPath: dashboard.DockerConfigPath(),
+	}
+}
+
+// loadRegistryEntries lists the dashboard's credentials followed by the
+// registries config.json has logins for. A registry in both uses the
+// dashboard's credential, so its config.json entry is left out. An
+// unreadable store is logged and the config.json logins are still listed;
+// the first error is returned.
+func loadRegistryEntries() ([]dashboard.RegistryCredential, error) {
+	entries, storeErr := registryAuth.Store.Load()
+	if storeErr != nil {
+		log.Println("Error loading registry credentials:", storeErr)
+	}
+	cfg, err := dashboard.LoadDockerConfig(registryAuth.ConfigPath)
+	if err != nil {
+		return entries, errors.Join(storeErr, err)
+	}
+	for _, e := range cfg.Entries() {
+		if !slices.ContainsFunc(entries, func(c dashboard.RegistryCredential) bool { return c.Registry == e.Registry }) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, storeErr
+}
+
+// showStoreError reports a failed change to the credential store. When the
+// store cannot be read, e.g. because its key was lost, it offers to reset
+// the store and retry the change.
+func showStoreError(err error, retry func()) {
+	if !errors.Is(err, dashboard.ErrCredentialStoreUnreadable) {
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	dialog.ShowConfirm("Credential Store", err.Error()+"\n\nReset the store, deleting the credentials saved in the dashboard, and try again?", func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := registryAuth.Store.Reset(); err != nil {
+			log.Println("Error resetting registry credentials:", err)
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+		retry()
+	}, mainWindow)
+}
+
+func formatRegistryRow(c dashboard.RegistryCredential) string {
+	user := c.Username
+	if user == "" {
+		user = "-"
+	}
+	return fmt.Sprintf("%s | User:%s | From:%s", c.Registry, user, c.Source)
+}
+
+// buildRegistriesSection lists the known registry logins with buttons to
+// add, edit, remove and test them.
+func buildRegistriesSection() (list fyne.CanvasObject, buttons fyne.CanvasObject) {
+	var entries []dashboard.RegistryCredential
+	selected := -1
+	registryList := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatRegistryRow(entries[id]))
+		},
+	)
+	registryList.OnSelected = func(id widget.ListItemID) { selected = id }
+	reload := func() {
+		var err error
+		entries, err = loadRegistryEntries()
+		if err != nil {
+			dialog.ShowError(err, mainWindow)
+		}
+		selected = -1
+		registryList.UnselectAll()
+		registryList.Refresh()
+	}
+	current := func() (dashboard.RegistryCredential, bool) {
+		if selected < 0 || selected >= len(entries) {
+			return dashboard.RegistryCredential{}, false
+		}
+		return entries[selected], true
+	}
+
+	addBtn := widget.NewButton("Add...", func() {
+		showRegistryForm(dashboard.RegistryCredential{}, reload)
+	})
+	editBtn := widget.NewButton("Edit...", func() {
+		c, ok := current()
+		if !ok {
+			return
+		}
+		// Editing a config.json login saves a dashboard copy, which then
+		// takes precedence; the Docker CLI's files are never written.
+		if c.Source != "dashboard" {
+			c = dashboard.RegistryCredential{Registry: c.Registry, Username: c.Username}
+		}
+		showRegistryForm(c, reload)
+	})
+	removeBtn := widget.NewButton("Remove", func() {
+		c, ok := current()
+		if !ok {
+			return
+		}
+		if c.Source != "dashboard" {
+			dialog.ShowInformation("Remove", c.Registry+" is configured for the Docker CLI ("+c.Source+"); remove it with `docker logout`.", mainWindow)
+			return
+		}
+		dialog.ShowConfirm("Remove", "Remove the credentials for "+c.Registry+"?", func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := registryAuth.Store.Delete(c.Registry); err != nil {
+				log.Println("Error removing registry credentials:", err)
+				// Resetting an unreadable store removes the entry with the rest.
+				showStoreError(err, reload)
+			}
+			reload()
+		}, mainWindow)
+	})
+	resetBtn := widget.NewButton("Reset Store...", func() {
+		dialog.ShowConfirm("Reset Store", "Delete all credentials saved in the dashboard? Logins from the Docker CLI are kept.\n\nThe key is kept in "+registryAuth.Store.KeyLocation()+".", func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := registryAuth.Store.Reset(); err != nil {
+				log.Println("Error resetting registry credentials:", err)
+				dialog.ShowError(err, mainWindow)
+			}
+			reload()
+		}, mainWindow)
+	})
+	testBtn := widget.NewButton("Test Login", func() {
+		c, ok := current()
+		if !ok {
+			return
+		}
+		go testRegistryLogin(c.Registry)
+	})
+	reloadBtn := widget.NewButton("Reload", reload)
+	reload()
+	return registryList, container.NewHBox(addBtn, editBtn, removeBtn, testBtn, reloadBtn, resetBtn)
+}
+
+// showRegistryForm adds or edits a credential in the dashboard's store.
+func showRegistryForm(c dashboard.RegistryCredential, saved func()) {
+	registryEntry := widget.NewEntry()
+	registryEntry.SetPlaceHolder("docker.io, ghcr.io, registry.example.com:5000")
+	registryEntry.SetText(c.Registry)
+	userEntry := widget.NewEntry()
+	userEntry.SetText(c.Username)
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Password or access token")
+	passwordEntry.SetText(c.Password)
+	testCheck := widget.NewCheck("Test login after saving", nil)
+	testCheck.SetChecked(true)
+
+	title := "Add Registry"
+	if c.Registry != "" {
+		title = "Edit Registry"
+	}
+	d := dialog.NewForm(title, "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Registry", registryEntry),
+		widget.NewFormItem("Username", userEntry),
+		widget.NewFormItem("Password", passwordEntry),
+		widget.NewFormItem("", testCheck),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		cred := dashboard.RegistryCredential{
+			Registry: dashboard.RegistryHost(registryEntry.Text),
+			Username: strings.TrimSpace(userEntry.Text),
+			Password: passwordEntry.Text,
+		}
+		if cred.Username == "" || cred.Password == "" {
+			dialog.ShowError(fmt.Errorf("username and password are required"), mainWindow)
+			return
+		}
+		// Renaming the registry moves the credential; the old entry goes
+		// only once the new one is saved.
+		put := func() error {
+			if err := registryAuth.Store.Put(cred); err != nil {
+				log.Println("Error saving registry credentials:", err)
+				return err
+			}
+			if c.Registry != "" && c.Registry != cred.Registry {
+				if err := registryAuth.Store.Delete(c.Registry); err != nil {
+					log.Println("Error removing registry credentials:", err)
+				}
+			}
+			return nil
+		}
+		done := func() {
+			saved()
+			if testCheck.Checked {
+				go testRegistryLogin(cred.Registry)
+			}
+		}
+		if err := put(); err != nil {
+			showStoreError(err, func() {
+				if err := put(); err != nil {
+					dialog.ShowError(err, mainWindow)
+					return
+				}
+				done()
+			})
+			return
+		}
+		done()
+	}, mainWindow)
+	d.Resize(fyne.NewSize(500, 300))
+	d.Show()
+}
+
+// testRegistryLogin logs in to a registry through the daemon with the
+// credential registry calls would use, and reports the outcome.
+func testRegistryLogin(registry string) {
+	ctx := context.Background()
+	cred, ok, err := registryAuth.Lookup(ctx, registry)
+	if err != nil {
+		log.Println("Error reading registry credentials:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	if !ok {
+		dialog.ShowInformation("Test Login", "No credentials found for "+registry+".", mainWindow)
+		return
+	}
+	status, err := dockerService.RegistryLogin(ctx, cred)
+	if err != nil {
+		log.Println("Error testing registry login:", err)
+		dialog.ShowError(err, mainWindow)
+		return
+	}
+	dialog.ShowInformation("Test Login", fmt.Sprintf("%s (%s): %s", registry, cred.Source, status), mainWindow)
+}
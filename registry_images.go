@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types/registry"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Image Push and Search
+// =============================================================================
+
+// imageSearchLimit is how many results a registry search shows.
+const imageSearchLimit = 50
+
+// showPushImageDialog pushes one of the tags of an image to its registry,
+// streaming the steps into the window.
+func showPushImageDialog(img dashboard.Image) {
+	var tags []string
+	for _, t := range img.RepoTags {
+		if t != "<none>:<none>" {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) == 0 {
+		dialog.ShowInformation("Push Image", "Tag the image with a registry reference before pushing it.", mainWindow)
+		return
+	}
+	win := appInstance.NewWindow("Push Image")
+	tagSelect := widget.NewSelect(tags, nil)
+	tagSelect.SetSelectedIndex(0)
+	progress := widget.NewTextGrid()
+	var lines []string
+	addProgress := func(line string) {
+		lines = append(lines, line)
+		if len(lines) > 200 {
+			lines = lines[len(lines)-200:]
+		}
+		progress.SetText(strings.Join(lines, "\n"))
+	}
+
+	var cancel context.CancelFunc
+	win.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	var pushBtn *widget.Button
+	pushBtn = widget.NewButton("Push", func() {
+		ref := tagSelect.Selected
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		pushBtn.Disable()
+		tagSelect.Disable()
+		lines = nil
+		addProgress("Pushing " + ref + " to " + dashboard.ReferenceRegistry(ref) + "...")
+		go func() {
+			defer cancel()
+			err := dockerService.PushImage(ctx, ref, addProgress)
+			pushBtn.Enable()
+			tagSelect.Enable()
+			if err != nil {
+				log.Println("Error pushing image:", err)
+				addProgress("Error: " + err.Error())
+				dialog.ShowError(err, win)
+				return
+			}
+			addProgress("Pushed " + ref)
+		}()
+	})
+	pushBtn.Importance = widget.HighImportance
+	top := container.NewBorder(nil, nil, widget.NewLabel("Tag"), pushBtn, tagSelect)
+	win.SetContent(container.NewBorder(top, nil, nil, nil, container.NewScroll(progress)))
+	win.Resize(fyne.NewSize(650, 400))
+	win.Show()
+}
+
+func formatSearchRow(r registry.SearchResult) string {
+	name := r.Name
+	if r.IsOfficial {
+		name += " (official)"
+	}
+	return fmt.Sprintf("%s | Stars:%d | %s", name, r.StarCount, r.Description)
+}
+
+// showImageSearch searches a registry, Docker Hub unless the term names
+// another, and passes the chosen repository to choose.
+func showImageSearch(parent fyne.Window, choose func(name string)) {
+	var results []registry.SearchResult
+	selected := -1
+	termEntry := widget.NewEntry()
+	termEntry.SetPlaceHolder("e.g. nginx or registry.example.com/team")
+	resultList := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(formatSearchRow(results[id]))
+		},
+	)
+	resultList.OnSelected = func(id widget.ListItemID) { selected = id }
+	status := widget.NewLabel("")
+
+	search := func() {
+		term := strings.TrimSpace(termEntry.Text)
+		if term == "" {
+			return
+		}
+		status.SetText("Searching...")
+		go func() {
+			found, err := dockerService.SearchImages(context.Background(), term, imageSearchLimit)
+			if err != nil {
+				log.Println("Error searching images:", err)
+				status.SetText("")
+				dialog.ShowError(err, parent)
+				return
+			}
+			results, selected = found, -1
+			resultList.UnselectAll()
+			resultList.Refresh()
+			status.SetText(fmt.Sprintf("%d results", len(found)))
+		}()
+	}
+	termEntry.OnSubmitted = func(string) { search() }
+	searchBtn := widget.NewButton("Search", search)
+
+	top := container.NewBorder(nil, nil, nil, searchBtn, termEntry)
+	content := container.NewBorder(top, status, nil, nil, resultList)
+	d := dialog.NewCustomConfirm("Search Images", "Use", "Cancel", content, func(ok bool) {
+		if ok && selected >= 0 && selected < len(results) {
+			choose(results[selected].Name)
+		}
+	}, parent)
+	d.Resize(fyne.NewSize(650, 450))
+	d.Show()
+}
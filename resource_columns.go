@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Resource Table Columns
+// =============================================================================
+
+// usagePollInterval is how often the Containers tab samples CPU and memory
+// while those columns are shown.
+const usagePollInterval = 5 * time.Second
+
+func containerColumns(usage *containerUsage) []tableColumn[dashboard.Container] {
+	return []tableColumn[dashboard.Container]{
+		{title: "Name", width: 200, text: func(c dashboard.Container) string { return c.Name }},
+		{title: "ID", width: 130, text: func(c dashboard.Container) string { return c.ShortID() }},
+		{title: "Image", width: 200, text: func(c dashboard.Container) string { return c.Image }},
+		{title: "State", width: 90, text: func(c dashboard.Container) string { return c.State }},
+		{title: "Status", width: 190, text: func(c dashboard.Container) string { return c.Status }},
+		{title: "Ports", width: 200, text: func(c dashboard.Container) string { return formatPorts(c.Ports) }},
+		{title: "Created", width: 130, text: func(c dashboard.Container) string { return formatAge(c.Created) },
+			less: func(a, b dashboard.Container) bool { return a.Created.Before(b.Created) }},
+		{title: "CPU", width: 80,
+			text: func(c dashboard.Container) string {
+				if s, ok := usage.get(c.ID); ok {
+					return fmt.Sprintf("%.1f%%", s.CPUPercent)
+				}
+				return ""
+			},
+			less: func(a, b dashboard.Container) bool { return usage.cpu(a.ID) < usage.cpu(b.ID) }},
+		{title: "Memory", width: 100,
+			text: func(c dashboard.Container) string {
+				if s, ok := usage.get(c.ID); ok {
+					return units.BytesSize(float64(s.MemoryUsage))
+				}
+				return ""
+			},
+			less: func(a, b dashboard.Container) bool { return usage.memory(a.ID) < usage.memory(b.ID) }},
+		{title: "Command", width: 200, text: func(c dashboard.Container) string { return c.Command }},
+	}
+}
+
+var defaultContainerColumns = []string{"Name", "Image", "State", "Status", "Ports", "Created", "CPU", "Memory"}
+
+func imageColumns() []tableColumn[dashboard.Image] {
+	return []tableColumn[dashboard.Image]{
+		{title: "Repository", width: 260, text: func(img dashboard.Image) string { repo, _ := imageRepoTag(img); return repo }},
+		{title: "Tag", width: 140, text: func(img dashboard.Image) string { _, tag := imageRepoTag(img); return tag }},
+		{title: "ID", width: 130, text: func(img dashboard.Image) string { return img.ShortID() }},
+		{title: "Size", width: 100, text: func(img dashboard.Image) string { return units.HumanSize(float64(img.Size)) },
+			less: func(a, b dashboard.Image) bool { return a.Size < b.Size }},
+		{title: "Created", width: 130, text: func(img dashboard.Image) string { return formatAge(img.Created) },
+			less: func(a, b dashboard.Image) bool { return a.Created.Before(b.Created) }},
+		{title: "In Use", width: 80, text: func(img dashboard.Image) string { return formatCount(int(img.Containers)) },
+			less: func(a, b dashboard.Image) bool { return a.Containers < b.Containers }},
+	}
+}
+
+var defaultImageColumns = []string{"Repository", "Tag", "ID", "Size", "Created", "In Use"}
+
+func volumeColumns() []tableColumn[dashboard.Volume] {
+	created := func(v dashboard.Volume) time.Time {
+		t, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		return t
+	}
+	return []tableColumn[dashboard.Volume]{
+		{title: "Name", width: 280, text: func(v dashboard.Volume) string { return v.Name }},
+		{title: "Driver", width: 90, text: func(v dashboard.Volume) string { return v.Driver }},
+		{title: "Scope", width: 80, text: func(v dashboard.Volume) string { return v.Scope }},
+		{title: "Mountpoint", width: 300, text: func(v dashboard.Volume) string { return v.Mountpoint }},
+		{title: "Created", width: 130, text: func(v dashboard.Volume) string { return formatAge(created(v)) },
+			less: func(a, b dashboard.Volume) bool { return created(a).Before(created(b)) }},
+		{title: "In Use", width: 80, text: func(v dashboard.Volume) string { return formatCount(v.Containers) },
+			less: func(a, b dashboard.Volume) bool { return a.Containers < b.Containers }},
+	}
+}
+
+var defaultVolumeColumns = []string{"Name", "Driver", "Mountpoint", "Created", "In Use"}
+
+func networkColumns() []tableColumn[dashboard.Network] {
+	return []tableColumn[dashboard.Network]{
+		{title: "Name", width: 220, text: func(n dashboard.Network) string { return n.Name }},
+		{title: "ID", width: 130, text: func(n dashboard.Network) string { return n.ShortID() }},
+		{title: "Driver", width: 90, text: func(n dashboard.Network) string { return n.Driver }},
+		{title: "Scope", width: 80, text: func(n dashboard.Network) string { return n.Scope }},
+		{title: "Internal", width: 80, text: func(n dashboard.Network) string { return strconv.FormatBool(n.Internal) }},
+		{title: "Created", width: 130, text: func(n dashboard.Network) string { return formatAge(n.Created) },
+			less: func(a, b dashboard.Network) bool { return a.Created.Before(b.Created) }},
+		{title: "Containers", width: 100, text: func(n dashboard.Network) string { return formatCount(n.Containers) },
+			less: func(a, b dashboard.Network) bool { return a.Containers < b.Containers }},
+	}
+}
+
+var defaultNetworkColumns = []string{"Name", "ID", "Driver", "Scope", "Created", "Containers"}
+
+// formatPorts writes published ports as `docker ps` does, e.g.
+// "127.0.0.1:8080->80/tcp", and unpublished ones as "80/tcp". Wildcard
+// addresses are left out, which also merges the IPv4 and IPv6 bindings
+// the daemon reports for one port.
+func formatPorts(ports []types.Port) string {
+	seen := map[string]bool{}
+	var parts []string
+	for _, p := range ports {
+		text := fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+		if p.PublicPort != 0 {
+			text = fmt.Sprintf("%d->%s", p.PublicPort, text)
+			if p.IP != "" && p.IP != "0.0.0.0" && p.IP != "::" {
+				text = p.IP + ":" + text
+			}
+		}
+		if !seen[text] {
+			seen[text] = true
+			parts = append(parts, text)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// formatAge writes how long ago t was, e.g. "3 days ago".
+func formatAge(t time.Time) string {
+	if t.IsZero() || t.Unix() <= 0 {
+		return ""
+	}
+	return units.HumanDuration(time.Since(t)) + " ago"
+}
+
+// formatCount writes a container count, which is -1 when unknown.
+func formatCount(n int) string {
+	if n < 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// imageRepoTag splits an image's first tag into repository and tag. Other
+// tags are counted after the tag.
+func imageRepoTag(img dashboard.Image) (repo, tag string) {
+	if len(img.RepoTags) == 0 || img.RepoTags[0] == "<none>:<none>" {
+		return "<none>", "<none>"
+	}
+	ref := img.RepoTags[0]
+	// A registry port also has a colon, so look after the last slash.
+	i := strings.LastIndex(ref, ":")
+	if i < strings.LastIndex(ref, "/") || i < 0 {
+		repo, tag = ref, "<none>"
+	} else {
+		repo, tag = ref[:i], ref[i+1:]
+	}
+	if n := len(img.RepoTags) - 1; n > 0 {
+		tag += fmt.Sprintf(" (+%d)", n)
+	}
+	return repo, tag
+}
+
+// containerUsage holds the latest CPU and memory sample of each running
+// container. CPU needs two readings, so it shows from the second poll on.
+type containerUsage struct {
+	mu      sync.Mutex
+	samples map[string]dashboard.StatsSample
+	calcs   map[string]*dashboard.StatsCalculator
+}
+
+func newContainerUsage() *containerUsage {
+	return &containerUsage{
+		samples: map[string]dashboard.StatsSample{},
+		calcs:   map[string]*dashboard.StatsCalculator{},
+	}
+}
+
+func (u *containerUsage) get(id string) (dashboard.StatsSample, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s, ok := u.samples[id]
+	return s, ok
+}
+
+func (u *containerUsage) cpu(id string) float64 {
+	s, _ := u.get(id)
+	return s.CPUPercent
+}
+
+func (u *containerUsage) memory(id string) uint64 {
+	s, _ := u.get(id)
+	return s.MemoryUsage
+}
+
+// poll samples the containers in parallel, forgetting containers no longer
+// passed in. Nothing changes when ctx is cancelled meanwhile.
+func (u *containerUsage) poll(ctx context.Context, ids []string) {
+	stats := make([]types.StatsJSON, len(ids))
+	errs := dashboard.RunBulk(ctx, len(ids), dashboard.BulkWorkers, func(ctx context.Context, i int) error {
+		var err error
+		stats[i], err = dockerService.ContainerStatsOnce(ctx, ids[i])
+		return err
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	samples := map[string]dashboard.StatsSample{}
+	calcs := map[string]*dashboard.StatsCalculator{}
+	for i, id := range ids {
+		if errs[i] != nil {
+			log.Println("Error reading container stats:", errs[i])
+			continue
+		}
+		calc := u.calcs[id]
+		if calc == nil {
+			calc = &dashboard.StatsCalculator{}
+		}
+		calcs[id] = calc
+		samples[id] = calc.Next(stats[i])
+	}
+	u.samples, u.calcs = samples, calcs
+}
+
+// usagePoller samples the running containers of a table while it shows
+// the CPU or Memory column, redrawing the table after each poll.
+type usagePoller struct {
+	usage *containerUsage
+	table *resourceTable[dashboard.Container]
+	stop  context.CancelFunc // set while polling
+}
+
+// update starts or stops polling to match the table's columns. It is called
+// on the UI goroutine when the columns change.
+func (p *usagePoller) update() {
+	shown := p.table.isShown("CPU") || p.table.isShown("Memory")
+	switch {
+	case shown && p.stop == nil:
+		ctx, cancel := context.WithCancel(context.Background())
+		p.stop = cancel
+		go p.run(ctx)
+	case !shown && p.stop != nil:
+		p.stop()
+		p.stop = nil
+	}
+}
+
+func (p *usagePoller) run(ctx context.Context) {
+	ticker := time.NewTicker(usagePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var ids []string
+		for _, c := range p.table.snapshot() {
+			if c.State == "running" {
+				ids = append(ids, c.ID)
+			}
+		}
+		p.usage.poll(ctx, ids)
+		if ctx.Err() == nil {
+			p.table.columnsChanged("CPU", "Memory")
+		}
+	}
+}
@@ -0,0 +1,602 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =============================================================================
+// Resource Tables
+// =============================================================================
+
+// tableColumn is a column a resource table can show.
+type tableColumn[T any] struct {
+	title string
+	width float32
+	text  func(T) string
+	// less orders the column when sorting by it; nil compares the text.
+	less func(a, b T) bool
+}
+
+// checkColumnWidth is the width of the leading checkbox column.
+const checkColumnWidth = 40
+
+// resourceTable shows a tab's resources with a checkbox and the chosen
+// columns per row. Rows can be filtered by text and sorted by clicking a
+// header; columns are resized by dragging the header dividers. The chosen
+// columns, their widths and the sort order are kept in preferences under
+// "table.<name>".
+//
+// Clicking a row selects it for the single-item actions; the checked rows
+// are what bulk actions act on. Checks are kept by key, so they follow rows
+// that move when the items are refreshed.
+//
+// The table holds the tab's resources. The event watcher and the usage
+// poller update them from their own goroutines while the UI draws them, so
+// all of the state below mu is only touched with mu held, and widgets are
+// only refreshed after releasing it: refreshing calls back into the cell
+// functions, which take mu themselves.
+type resourceTable[T any] struct {
+	name     string
+	columns  []tableColumn[T]
+	defaults []string
+	key      func(T) string
+	// onSelected is called on the UI goroutine when the user selects a row.
+	onSelected func(T)
+	// onColumnsChanged, if set, is called when the user picks other columns.
+	onColumnsChanged func()
+
+	mu    sync.Mutex
+	items []T   // the tab's resources, as listed by Docker
+	rows  []T   // items passing the filter, sorted
+	shown []int // indexes into columns
+	// widths are the widths the user dragged columns to, by title. applied
+	// holds the width last given to each table column, so that a header
+	// laid out at another width shows a drag.
+	widths  map[string]float32
+	applied []float32
+	// applying is set while the applied widths are handed to the table, as
+	// headers are laid out at the old widths in between.
+	applying bool
+	sortCol  string
+	sortDesc bool
+	query    string
+	// selectedKey is the key of the selected resource. It is kept while the
+	// resource is gone, so the next action reports that it no longer exists
+	// instead of hitting another one.
+	selectedKey string
+	selectedRow int
+	checked     map[string]bool
+
+	count  *widget.Label
+	filter *widget.Entry
+	table  *widget.Table
+}
+
+func newResourceTable[T any](name string, key func(T) string, columns []tableColumn[T], defaults []string) *resourceTable[T] {
+	prefs := appInstance.Preferences()
+	t := &resourceTable[T]{
+		name:        name,
+		columns:     columns,
+		defaults:    defaults,
+		sortCol:     prefs.String(tablePref(name, "sort")),
+		sortDesc:    prefs.Bool(tablePref(name, "sortDesc")),
+		key:         key,
+		selectedRow: -1,
+		checked:     map[string]bool{},
+		count:       widget.NewLabel(""),
+		filter:      widget.NewEntry(),
+	}
+	t.setShown(prefs.StringListWithFallback(tablePref(name, "columns"), defaults))
+	t.widths = loadColumnWidths(name)
+
+	t.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return len(t.rows), len(t.shown) + 1
+		},
+		t.createCell,
+		t.updateCell,
+	)
+	t.table.ShowHeaderColumn = false
+	t.table.CreateHeader = func() fyne.CanvasObject {
+		b := widget.NewButton("", nil)
+		b.Alignment = widget.ButtonAlignLeading
+		b.Importance = widget.LowImportance
+		return b
+	}
+	t.table.UpdateHeader = t.updateHeader
+	// Refresh moves the selection to where the selected resource went; only
+	// a different resource is reported to onSelected, so that is only ever
+	// called for the user's clicks.
+	t.table.OnSelected = func(id widget.TableCellID) {
+		t.mu.Lock()
+		if id.Row < 0 || id.Row >= len(t.rows) {
+			t.mu.Unlock()
+			return
+		}
+		item := t.rows[id.Row]
+		key := t.key(item)
+		changed := key != t.selectedKey
+		t.selectedKey, t.selectedRow = key, id.Row
+		t.mu.Unlock()
+		if changed && t.onSelected != nil {
+			t.onSelected(item)
+		}
+	}
+	t.setColumnWidths()
+
+	t.filter.SetPlaceHolder("Filter")
+	t.filter.OnChanged = func(query string) {
+		t.mu.Lock()
+		t.query = strings.ToLower(strings.TrimSpace(query))
+		t.mu.Unlock()
+		t.Refresh()
+	}
+	t.updateCount()
+	return t
+}
+
+// tablePref returns the preference key of a table setting.
+func tablePref(table, setting string) string {
+	return "table." + table + "." + setting
+}
+
+// setShown picks the visible columns by title. Call it with mu held.
+func (t *resourceTable[T]) setShown(titles []string) {
+	t.shown = nil
+	for _, title := range titles {
+		if i := slices.IndexFunc(t.columns, func(c tableColumn[T]) bool { return c.title == title }); i >= 0 {
+			t.shown = append(t.shown, i)
+		}
+	}
+	if len(t.shown) == 0 {
+		t.setShown(t.defaults)
+	}
+}
+
+// isShown reports whether the column titled title is visible.
+func (t *resourceTable[T]) isShown(title string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.ContainsFunc(t.shown, func(i int) bool { return t.columns[i].title == title })
+}
+
+func (t *resourceTable[T]) setColumnWidths() {
+	t.mu.Lock()
+	t.applied = make([]float32, len(t.shown)+1)
+	t.applied[0] = checkColumnWidth
+	for i, c := range t.shown {
+		width, ok := t.widths[t.columns[c].title]
+		if !ok {
+			width = t.columns[c].width
+		}
+		t.applied[i+1] = width
+	}
+	widths := slices.Clone(t.applied)
+	t.applying = true
+	t.mu.Unlock()
+	for i, width := range widths {
+		t.table.SetColumnWidth(i, width)
+	}
+	t.mu.Lock()
+	t.applying = false
+	t.mu.Unlock()
+}
+
+// noteColumnWidth records the width a header was laid out at. The table
+// has no resize callback, but headers are laid out at their column's width
+// before being updated, so a width other than the one applied means the
+// user dragged the column.
+func (t *resourceTable[T]) noteColumnWidth(col int, width float32) {
+	t.mu.Lock()
+	if t.applying || width <= 0 || col <= 0 || col >= len(t.applied) || col > len(t.shown) || width == t.applied[col] {
+		t.mu.Unlock()
+		return
+	}
+	t.applied[col] = width
+	t.widths[t.columns[t.shown[col-1]].title] = width
+	widths := maps.Clone(t.widths)
+	t.mu.Unlock()
+	saveColumnWidths(t.name, widths)
+}
+
+func loadColumnWidths(table string) map[string]float32 {
+	widths := map[string]float32{}
+	if raw := appInstance.Preferences().String(tablePref(table, "widths")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &widths); err != nil {
+			log.Println("Error loading column widths:", err)
+		}
+	}
+	return widths
+}
+
+func saveColumnWidths(table string, widths map[string]float32) {
+	raw, err := json.Marshal(widths)
+	if err != nil {
+		log.Println("Error saving column widths:", err)
+		return
+	}
+	appInstance.Preferences().SetString(tablePref(table, "widths"), string(raw))
+}
+
+func (t *resourceTable[T]) createCell() fyne.CanvasObject {
+	label := widget.NewLabel("")
+	label.Truncation = fyne.TextTruncateEllipsis
+	return container.NewStack(widget.NewCheck("", nil), label)
+}
+
+func (t *resourceTable[T]) updateCell(id widget.TableCellID, obj fyne.CanvasObject) {
+	t.mu.Lock()
+	if id.Row >= len(t.rows) || id.Col > len(t.shown) {
+		t.mu.Unlock()
+		return
+	}
+	item := t.rows[id.Row]
+	var text string
+	if id.Col > 0 {
+		text = t.columns[t.shown[id.Col-1]].text(item)
+	}
+	key := t.key(item)
+	checked := t.checked[key]
+	t.mu.Unlock()
+
+	objects := obj.(*fyne.Container).Objects
+	check, label := objects[0].(*widget.Check), objects[1].(*widget.Label)
+	if id.Col > 0 {
+		check.Hide()
+		label.Show()
+		label.SetText(text)
+		return
+	}
+	label.Hide()
+	check.Show()
+	// Reused cells must not report the previous row's state.
+	check.OnChanged = nil
+	check.SetChecked(checked)
+	check.OnChanged = func(on bool) {
+		t.mu.Lock()
+		if on {
+			t.checked[key] = true
+		} else {
+			delete(t.checked, key)
+		}
+		t.mu.Unlock()
+		t.updateCount()
+	}
+}
+
+func (t *resourceTable[T]) updateHeader(id widget.TableCellID, obj fyne.CanvasObject) {
+	b := obj.(*widget.Button)
+	t.noteColumnWidth(id.Col, b.Size().Width)
+	t.mu.Lock()
+	if id.Col <= 0 || id.Col > len(t.shown) {
+		t.mu.Unlock()
+		b.SetText("")
+		b.OnTapped = nil
+		return
+	}
+	title := t.columns[t.shown[id.Col-1]].title
+	text := title
+	switch {
+	case title == t.sortCol && t.sortDesc:
+		text += " ▼"
+	case title == t.sortCol:
+		text += " ▲"
+	}
+	t.mu.Unlock()
+	b.SetText(text)
+	b.OnTapped = func() { t.sortBy(title) }
+}
+
+// sortBy sorts by the column titled title, reversing the order when it is
+// already the sort column.
+func (t *resourceTable[T]) sortBy(title string) {
+	t.mu.Lock()
+	if t.sortCol == title {
+		t.sortDesc = !t.sortDesc
+	} else {
+		t.sortCol, t.sortDesc = title, false
+	}
+	sortCol, sortDesc := t.sortCol, t.sortDesc
+	t.mu.Unlock()
+	prefs := appInstance.Preferences()
+	prefs.SetString(tablePref(t.name, "sort"), sortCol)
+	prefs.SetBool(tablePref(t.name, "sortDesc"), sortDesc)
+	t.Refresh()
+}
+
+// setItems replaces the tab's resources, forgetting checks on ones no
+// longer listed, and redraws the table.
+func (t *resourceTable[T]) setItems(items []T) {
+	t.mu.Lock()
+	t.items = items
+	t.prune()
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// upsert replaces the resource with item's key, or adds item first when it
+// is new so new resources show up first, as in `docker ps`.
+func (t *resourceTable[T]) upsert(item T) {
+	t.mu.Lock()
+	key := t.key(item)
+	if i := slices.IndexFunc(t.items, func(x T) bool { return t.key(x) == key }); i >= 0 {
+		t.items[i] = item
+	} else {
+		t.items = slices.Insert(t.items, 0, item)
+	}
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// remove drops the resource with key, if listed.
+func (t *resourceTable[T]) remove(key string) {
+	t.mu.Lock()
+	t.items = slices.DeleteFunc(t.items, func(x T) bool { return t.key(x) == key })
+	t.prune()
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// snapshot returns a copy of the tab's resources.
+func (t *resourceTable[T]) snapshot() []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.items)
+}
+
+// lookup returns the listed resource with key.
+func (t *resourceTable[T]) lookup(key string) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i := slices.IndexFunc(t.items, func(x T) bool { return t.key(x) == key }); i >= 0 {
+		return t.items[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+// selectKey selects the resource with key, such as one picked in another
+// view of the same resources.
+func (t *resourceTable[T]) selectKey(key string) {
+	t.mu.Lock()
+	t.selectedKey = key
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// Refresh rebuilds the rows from the items and redraws the table.
+func (t *resourceTable[T]) Refresh() {
+	t.mu.Lock()
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// rebuild filters and sorts the items into the rows. It returns the row
+// the selected resource moved to, or -1, and whether the table's selection
+// has to follow. Call it with mu held.
+func (t *resourceTable[T]) rebuild() (row int, move bool) {
+	t.rows = t.rows[:0]
+	for _, item := range t.items {
+		if t.query == "" || t.matches(item, t.query) {
+			t.rows = append(t.rows, item)
+		}
+	}
+	if i := slices.IndexFunc(t.columns, func(c tableColumn[T]) bool { return c.title == t.sortCol }); i >= 0 {
+		col := t.columns[i]
+		less := col.less
+		if less == nil {
+			less = func(a, b T) bool { return col.text(a) < col.text(b) }
+		}
+		sort.SliceStable(t.rows, func(a, b int) bool {
+			if t.sortDesc {
+				return less(t.rows[b], t.rows[a])
+			}
+			return less(t.rows[a], t.rows[b])
+		})
+	}
+
+	row = -1
+	if t.selectedKey != "" {
+		row = slices.IndexFunc(t.rows, func(item T) bool { return t.key(item) == t.selectedKey })
+	}
+	move = row < 0 || row != t.selectedRow
+	t.selectedRow = row
+	return row, move
+}
+
+// redrawRows redraws the table after a rebuild, highlighting the selected
+// resource again wherever it moved. Call it without mu held.
+func (t *resourceTable[T]) redrawRows(row int, move bool) {
+	t.table.Refresh()
+	t.updateCount()
+	switch {
+	case row < 0:
+		t.table.UnselectAll()
+	case move:
+		t.table.Select(widget.TableCellID{Row: row, Col: 1})
+	}
+}
+
+// columnsChanged redraws the table after the values of the columns titled
+// titles changed, such as resource usage, re-sorting the rows only when
+// they are sorted by one of them.
+func (t *resourceTable[T]) columnsChanged(titles ...string) {
+	t.mu.Lock()
+	if !slices.Contains(titles, t.sortCol) {
+		t.mu.Unlock()
+		t.table.Refresh()
+		return
+	}
+	row, move := t.rebuild()
+	t.mu.Unlock()
+	t.redrawRows(row, move)
+}
+
+// matches reports whether any shown column of item contains query. Call
+// it with mu held.
+func (t *resourceTable[T]) matches(item T, query string) bool {
+	for _, i := range t.shown {
+		if strings.Contains(strings.ToLower(t.columns[i].text(item)), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected returns the checked items, including any the filter hides.
+func (t *resourceTable[T]) selected() []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var items []T
+	for _, item := range t.items {
+		if t.checked[t.key(item)] {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkWhere replaces the checks with the rows matching pred.
+func (t *resourceTable[T]) checkWhere(pred func(T) bool) {
+	t.mu.Lock()
+	t.checked = map[string]bool{}
+	for _, item := range t.rows {
+		if pred(item) {
+			t.checked[t.key(item)] = true
+		}
+	}
+	t.mu.Unlock()
+	t.table.Refresh()
+	t.updateCount()
+}
+
+// prune forgets checks on items no longer listed. Call it with mu held.
+func (t *resourceTable[T]) prune() {
+	present := make(map[string]bool, len(t.items))
+	for _, item := range t.items {
+		present[t.key(item)] = true
+	}
+	for key := range t.checked {
+		if !present[key] {
+			delete(t.checked, key)
+		}
+	}
+}
+
+func (t *resourceTable[T]) updateCount() {
+	t.mu.Lock()
+	text := fmt.Sprintf("%d of %d shown, %d checked", len(t.rows), len(t.items), len(t.checked))
+	t.mu.Unlock()
+	t.count.SetText(text)
+}
+
+// toolbar returns the filter box and the Columns button.
+func (t *resourceTable[T]) toolbar() fyne.CanvasObject {
+	columnsBtn := widget.NewButton("Columns...", t.showColumnsDialog)
+	return container.NewBorder(nil, nil, nil, container.NewHBox(t.count, columnsBtn), t.filter)
+}
+
+// selectionBar returns the Check All/None/Filtered controls followed by
+// actions, the bulk action buttons. Filtered checks the rows the filter
+// box lets through; All checks every row, clearing the filter.
+func (t *resourceTable[T]) selectionBar(actions ...fyne.CanvasObject) fyne.CanvasObject {
+	allBtn := widget.NewButton("All", func() {
+		t.filter.SetText("")
+		t.checkWhere(func(T) bool { return true })
+	})
+	noneBtn := widget.NewButton("None", func() {
+		t.checkWhere(func(T) bool { return false })
+	})
+	filteredBtn := widget.NewButton("Filtered", func() {
+		t.checkWhere(func(T) bool { return true })
+	})
+	return container.NewHBox(append([]fyne.CanvasObject{widget.NewLabel("Check"), allBtn, noneBtn, filteredBtn, widget.NewSeparator()}, actions...)...)
+}
+
+// showColumnsDialog picks the visible columns.
+func (t *resourceTable[T]) showColumnsDialog() {
+	titles := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		titles[i] = c.title
+	}
+	var current []string
+	t.mu.Lock()
+	for _, i := range t.shown {
+		current = append(current, t.columns[i].title)
+	}
+	t.mu.Unlock()
+	showColumnPicker(mainWindow, titles, current, t.defaults, false, func(columns []string) {
+		appInstance.Preferences().SetStringList(tablePref(t.name, "columns"), columns)
+		t.mu.Lock()
+		t.setShown(columns)
+		t.mu.Unlock()
+		t.setColumnWidths()
+		t.Refresh()
+		if t.onColumnsChanged != nil {
+			t.onColumnsChanged()
+		}
+	})
+}
+
+// showColumnPicker lets the user choose a table's columns out of options,
+// starting from current. The chosen columns keep their current order, with
+// newly chosen ones appended; choosing none picks defaults. When custom is
+// set, names not among the options can be typed in, comma-separated.
+func showColumnPicker(parent fyne.Window, options, current, defaults []string, custom bool, apply func(columns []string)) {
+	group := widget.NewCheckGroup(options, nil)
+	group.SetSelected(current)
+	reset := widget.NewButton("Reset to defaults", func() { group.SetSelected(defaults) })
+	other := widget.NewEntry()
+	other.SetPlaceHolder("comma-separated, e.g. http.status")
+
+	bottom := fyne.CanvasObject(reset)
+	if custom {
+		bottom = container.NewVBox(widget.NewForm(widget.NewFormItem("Other fields", other)), reset)
+	}
+	content := container.NewBorder(nil, bottom, nil, nil, container.NewVScroll(group))
+	d := dialog.NewCustomConfirm("Columns", "Apply", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		var columns []string
+		for _, c := range current {
+			if slices.Contains(group.Selected, c) {
+				columns = append(columns, c)
+			}
+		}
+		chosen := slices.Clone(group.Selected)
+		if custom {
+			chosen = append(chosen, strings.Split(other.Text, ",")...)
+		}
+		for _, c := range chosen {
+			if c = strings.TrimSpace(c); c != "" && !slices.Contains(columns, c) {
+				columns = append(columns, c)
+			}
+		}
+		if len(columns) == 0 {
+			columns = slices.Clone(defaults)
+		}
+		apply(columns)
+	}, parent)
+	d.Resize(fyne.NewSize(400, 500))
+	d.Show()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"sprint/dashboard"
+)
+
+// newTestTable returns a container table with the default columns whose
+// preferences are kept under name, so tests do not share a sort order.
+func newTestTable(t *testing.T, name string) *resourceTable[dashboard.Container] {
+	t.Helper()
+	prefs := appInstance.Preferences()
+	t.Cleanup(func() {
+		prefs.RemoveValue(tablePref(name, "sort"))
+		prefs.RemoveValue(tablePref(name, "sortDesc"))
+	})
+	return newResourceTable(name,
+		func(c dashboard.Container) string { return c.ID },
+		containerColumns(newContainerUsage()), defaultContainerColumns)
+}
+
+func rowNames(list *resourceTable[dashboard.Container]) []string {
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	var names []string
+	for _, c := range list.rows {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestResourceTableFilterAndSort(t *testing.T) {
+	list := newTestTable(t, "test-sort")
+	now := time.Now()
+	list.setItems([]dashboard.Container{
+		{ID: "1", Name: "web", Image: "nginx", State: "running", Created: now.Add(-time.Hour)},
+		{ID: "2", Name: "db", Image: "postgres", State: "exited", Created: now.Add(-3 * time.Hour)},
+		{ID: "3", Name: "cache", Image: "redis", State: "running", Created: now.Add(-2 * time.Hour)},
+	})
+	if got := rowNames(list); !slices.Equal(got, []string{"web", "db", "cache"}) {
+		t.Errorf("unsorted rows = %v, want the listed order", got)
+	}
+
+	list.sortBy("Name")
+	if got := rowNames(list); !slices.Equal(got, []string{"cache", "db", "web"}) {
+		t.Errorf("by name = %v", got)
+	}
+	list.sortBy("Name")
+	if got := rowNames(list); !slices.Equal(got, []string{"web", "db", "cache"}) {
+		t.Errorf("by name, descending = %v", got)
+	}
+	// Created sorts by time, not by the "x hours ago" text.
+	list.sortBy("Created")
+	if got := rowNames(list); !slices.Equal(got, []string{"db", "cache", "web"}) {
+		t.Errorf("by created = %v", got)
+	}
+	if sortCol := appInstance.Preferences().String(tablePref("test-sort", "sort")); sortCol != "Created" {
+		t.Errorf("saved sort column = %q", sortCol)
+	}
+
+	// The filter looks at every shown column, ignoring case.
+	list.filter.SetText("RUNNING")
+	if got := rowNames(list); !slices.Equal(got, []string{"cache", "web"}) {
+		t.Errorf("filtered = %v", got)
+	}
+	list.filter.SetText("redis")
+	if got := rowNames(list); !slices.Equal(got, []string{"cache"}) {
+		t.Errorf("filtered by image = %v", got)
+	}
+	if got := list.count.Text; got != "1 of 3 shown, 0 checked" {
+		t.Errorf("count = %q", got)
+	}
+}
+
+func TestResourceTableUpdates(t *testing.T) {
+	list := newTestTable(t, "test-updates")
+	list.setItems([]dashboard.Container{{ID: "1", Name: "web"}, {ID: "2", Name: "db"}})
+
+	// New resources are listed first; known ones are replaced in place.
+	list.upsert(dashboard.Container{ID: "3", Name: "cache"})
+	list.upsert(dashboard.Container{ID: "1", Name: "web", State: "exited"})
+	if got := containerNames(list); !slices.Equal(got, []string{"cache", "web", "db"}) {
+		t.Errorf("after upsert = %v", got)
+	}
+	if c, ok := list.lookup("1"); !ok || c.State != "exited" {
+		t.Errorf("lookup = %+v, %v", c, ok)
+	}
+	list.remove("3")
+	list.remove("missing")
+	if got := containerNames(list); !slices.Equal(got, []string{"web", "db"}) {
+		t.Errorf("after remove = %v", got)
+	}
+}
+
+func TestResourceTableChecks(t *testing.T) {
+	list := newTestTable(t, "test-checks")
+	list.setItems([]dashboard.Container{
+		{ID: "1", Name: "web", State: "running"},
+		{ID: "2", Name: "db", State: "exited"},
+		{ID: "3", Name: "old", State: "exited"},
+	})
+	list.checkWhere(func(c dashboard.Container) bool { return c.State == "exited" })
+
+	// Checks stay on rows the filter hides.
+	list.filter.SetText("web")
+	if got := list.selected(); len(got) != 2 {
+		t.Errorf("checked = %v, want db and old", got)
+	}
+	// They are forgotten once the resource is gone.
+	list.remove("3")
+	list.setItems([]dashboard.Container{{ID: "1", Name: "web"}, {ID: "2", Name: "db"}})
+	if got := list.selected(); len(got) != 1 || got[0].ID != "2" {
+		t.Errorf("checked after removal = %v, want db", got)
+	}
+}
+
+func TestResourceTableSelection(t *testing.T) {
+	list := newTestTable(t, "test-selection")
+	list.setItems([]dashboard.Container{{ID: "1", Name: "web"}, {ID: "2", Name: "db"}})
+	list.selectKey("2")
+	list.mu.Lock()
+	row := list.selectedRow
+	list.mu.Unlock()
+	if row != 1 {
+		t.Errorf("selected row = %d, want 1", row)
+	}
+	// The selection follows the resource when rows move.
+	list.upsert(dashboard.Container{ID: "3", Name: "cache"})
+	list.mu.Lock()
+	row, key := list.selectedRow, list.selectedKey
+	list.mu.Unlock()
+	if row != 2 || key != "2" {
+		t.Errorf("after insert: row %d, key %q; want 2, \"2\"", row, key)
+	}
+	// A removed resource stays selected by key, but has no row.
+	list.remove("2")
+	list.mu.Lock()
+	row, key = list.selectedRow, list.selectedKey
+	list.mu.Unlock()
+	if row != -1 || key != "2" {
+		t.Errorf("after remove: row %d, key %q; want -1, \"2\"", row, key)
+	}
+}
+
+func TestFormatPorts(t *testing.T) {
+	tests := []struct {
+		ports []types.Port
+		want  string
+	}{
+		{nil, ""},
+		{[]types.Port{{PrivatePort: 80, Type: "tcp"}}, "80/tcp"},
+		// The IPv4 and IPv6 bindings of a port are shown once.
+		{[]types.Port{
+			{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			{IP: "::", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			{IP: "127.0.0.1", PrivatePort: 53, PublicPort: 5353, Type: "udp"},
+		}, "127.0.0.1:5353->53/udp, 8080->80/tcp"},
+	}
+	for _, tt := range tests {
+		if got := formatPorts(tt.ports); got != tt.want {
+			t.Errorf("formatPorts(%v) = %q, want %q", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestImageRepoTag(t *testing.T) {
+	tests := []struct {
+		tags      []string
+		repo, tag string
+	}{
+		{nil, "<none>", "<none>"},
+		{[]string{"<none>:<none>"}, "<none>", "<none>"},
+		{[]string{"nginx:1.27"}, "nginx", "1.27"},
+		{[]string{"localhost:5000/app"}, "localhost:5000/app", "<none>"},
+		{[]string{"localhost:5000/app:dev", "app:dev", "app:latest"}, "localhost:5000/app", "dev (+2)"},
+	}
+	for _, tt := range tests {
+		repo, tag := imageRepoTag(dashboard.Image{RepoTags: tt.tags})
+		if repo != tt.repo || tag != tt.tag {
+			t.Errorf("imageRepoTag(%v) = %q, %q; want %q, %q", tt.tags, repo, tag, tt.repo, tt.tag)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
+
+	"sprint/dashboard"
+)
+
+// =============================================================================
+// Container Stats (Streaming)
+// =============================================================================
+
+// statsHistory is how much history a stats window keeps, which is also the
+// largest selectable time window.
+const statsHistory = time.Hour
+
+var statsWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"1 min", time.Minute},
+	{"5 min", 5 * time.Minute},
+	{"15 min", 15 * time.Minute},
+	{"1 hour", time.Hour},
+}
+
+var (
+	chartBlue   = color.RGBA{0, 122, 255, 255}
+	chartOrange = color.RGBA{255, 149, 0, 255}
+)
+
+// statsView is the state behind one live stats window.
+type statsView struct {
+	mu      sync.Mutex
+	calc    dashboard.StatsCalculator
+	primed  bool
+	samples []dashboard.StatsSample
+	window  time.Duration
+	// gen identifies the current stream; samples from an older, cancelled
+	// stream are dropped.
+	gen int
+	// memLimited is set when the container has a memory limit, which the
+	// memory chart then draws.
+	memLimited bool
+
+	cpuLabel, memLabel, netLabel, blockLabel, pidsLabel *widget.Label
+	cpuChart, memChart, netChart, blockChart, pidsChart *timeSeriesChart
+}
+
+func showContainerStats(id string) {
+	if id == "" {
+		return
+	}
+	info, err := dockerService.InspectContainer(context.Background(), id)
+	if err != nil {
+		showActionError("Error fetching container stats:", err)
+		return
+	}
+	cpuLimit := 0.0
+	memSeries := []chartSeries{{"usage", chartBlue, func(s dashboard.StatsSample) float64 { return float64(s.MemoryUsage) }}}
+	if info.HostConfig != nil {
+		cpuLimit = dashboard.CPULimitCores(info.HostConfig.Resources)
+		// Without a limit the daemon reports the host's memory, which would
+		// flatten the usage line, so the limit is only drawn when set.
+		if info.HostConfig.Memory > 0 {
+			memSeries = append(memSeries, chartSeries{"limit", chartOrange, func(s dashboard.StatsSample) float64 { return float64(s.MemoryLimit) }})
+		}
+	}
+
+	v := &statsView{
+		window:     time.Minute,
+		cpuLabel:   widget.NewLabel("CPU"),
+		memLabel:   widget.NewLabel("Memory"),
+		netLabel:   widget.NewLabel("Network"),
+		blockLabel: widget.NewLabel("Block I/O"),
+		pidsLabel:  widget.NewLabel("PIDs"),
+		cpuChart: newTimeSeriesChart(formatPercent,
+			chartSeries{"limit", chartBlue, func(s dashboard.StatsSample) float64 { return s.CPULimitPercent }},
+			chartSeries{"host", chartOrange, func(s dashboard.StatsSample) float64 { return s.CPUHostPercent }}),
+		memChart: newTimeSeriesChart(formatBytes, memSeries...),
+		netChart: newTimeSeriesChart(formatRate,
+			chartSeries{"rx", chartBlue, func(s dashboard.StatsSample) float64 { return s.NetRxRate }},
+			chartSeries{"tx", chartOrange, func(s dashboard.StatsSample) float64 { return s.NetTxRate }}),
+		blockChart: newTimeSeriesChart(formatRate,
+			chartSeries{"read", chartBlue, func(s dashboard.StatsSample) float64 { return s.BlockReadRate }},
+			chartSeries{"write", chartOrange, func(s dashboard.StatsSample) float64 { return s.BlockWriteRate }}),
+		pidsChart: newTimeSeriesChart(func(f float64) string { return fmt.Sprintf("%.0f", f) },
+			chartSeries{"pids", chartBlue, func(s dashboard.StatsSample) float64 { return float64(s.PIDs) }}),
+	}
+	v.calc.CPULimit = cpuLimit
+	v.memLimited = len(memSeries) > 1
+
+	win := appInstance.NewWindow("Container Stats: " + trimName(info.Name))
+	status := widget.NewLabel("Streaming...")
+
+	var stop context.CancelFunc
+	start := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		stop = cancel
+		gen := v.restart()
+		status.SetText("Streaming...")
+		go func() {
+			err := dockerService.StreamStats(ctx, id, func(raw types.StatsJSON) {
+				v.add(gen, raw)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				status.SetText("Stream stopped: " + err.Error())
+			} else {
+				status.SetText("Stream ended (container stopped)")
+			}
+		}()
+	}
+
+	var pauseBtn *widget.Button
+	pauseBtn = widget.NewButton("Pause", func() {
+		if stop != nil {
+			stop()
+			stop = nil
+			pauseBtn.SetText("Resume")
+			status.SetText("Paused")
+			return
+		}
+		pauseBtn.SetText("Pause")
+		start()
+	})
+
+	windowLabels := make([]string, len(statsWindows))
+	for i, w := range statsWindows {
+		windowLabels[i] = w.label
+	}
+	windowSelect := widget.NewSelect(windowLabels, func(label string) {
+		for _, w := range statsWindows {
+			if w.label == label {
+				v.setWindow(w.window)
+			}
+		}
+	})
+	windowSelect.SetSelected(statsWindows[0].label)
+
+	charts := container.NewGridWithColumns(1,
+		container.NewBorder(v.cpuLabel, nil, nil, nil, v.cpuChart),
+		container.NewBorder(v.memLabel, nil, nil, nil, v.memChart),
+		container.NewBorder(v.netLabel, nil, nil, nil, v.netChart),
+		container.NewBorder(v.blockLabel, nil, nil, nil, v.blockChart),
+		container.NewBorder(v.pidsLabel, nil, nil, nil, v.pidsChart),
+	)
+	controls := container.NewHBox(pauseBtn, widget.NewLabel("Window:"), windowSelect, status)
+	win.SetContent(container.NewBorder(controls, nil, nil, nil, container.NewVScroll(charts)))
+	win.SetOnClosed(func() {
+		if stop != nil {
+			stop()
+		}
+	})
+	win.Resize(fyne.NewSize(800, 900))
+	win.Show()
+	start()
+}
+
+// restart begins a new stream generation. Rates restart from scratch since
+// the gap would otherwise be averaged into the first sample.
+func (v *statsView) restart() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.gen++
+	v.calc.Reset()
+	v.primed = false
+	return v.gen
+}
+
+func (v *statsView) add(gen int, raw types.StatsJSON) {
+	v.mu.Lock()
+	if gen != v.gen {
+		v.mu.Unlock()
+		return
+	}
+	sample := v.calc.Next(raw)
+	if !v.primed {
+		// The first reading has no rates yet; don't plot it as a dip to zero.
+		v.primed = true
+		v.mu.Unlock()
+		return
+	}
+	v.samples = append(v.samples, sample)
+	cutoff := sample.Time.Add(-statsHistory)
+	drop := 0
+	for drop < len(v.samples) && v.samples[drop].Time.Before(cutoff) {
+		drop++
+	}
+	v.samples = v.samples[drop:]
+	v.mu.Unlock()
+
+	limit := "no limit"
+	if v.calc.CPULimit > 0 {
+		limit = fmt.Sprintf("limit %.2f CPUs", v.calc.CPULimit)
+	}
+	v.cpuLabel.SetText(fmt.Sprintf("CPU: %.2f cores, %s of %s (blue), %s of host (orange)",
+		sample.CPUCores, formatPercent(sample.CPULimitPercent), limit, formatPercent(sample.CPUHostPercent)))
+	memLimit := "of host"
+	if v.memLimited {
+		memLimit = "limit (orange)"
+	}
+	v.memLabel.SetText(fmt.Sprintf("Memory: %s (blue) / %s %s (%s)",
+		formatBytes(float64(sample.MemoryUsage)), formatBytes(float64(sample.MemoryLimit)), memLimit, formatPercent(sample.MemoryPercent)))
+	v.netLabel.SetText(fmt.Sprintf("Network: rx %s, tx %s", formatRate(sample.NetRxRate), formatRate(sample.NetTxRate)))
+	v.blockLabel.SetText(fmt.Sprintf("Block I/O: read %s, write %s", formatRate(sample.BlockReadRate), formatRate(sample.BlockWriteRate)))
+	v.pidsLabel.SetText(fmt.Sprintf("PIDs: %d", sample.PIDs))
+	v.redraw()
+}
+
+func (v *statsView) setWindow(window time.Duration) {
+	v.mu.Lock()
+	v.window = window
+	v.mu.Unlock()
+	v.redraw()
+}
+
+func (v *statsView) redraw() {
+	v.mu.Lock()
+	samples := append([]dashboard.StatsSample(nil), v.samples...)
+	window := v.window
+	v.mu.Unlock()
+	for _, c := range []*timeSeriesChart{v.cpuChart, v.memChart, v.netChart, v.blockChart, v.pidsChart} {
+		c.SetData(samples, window)
+	}
+}
+
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.2f%%", f)
+}
+
+func formatBytes(f float64) string {
+	return units.BytesSize(f)
+}
+
+func formatRate(f float64) string {
+	return units.BytesSize(f) + "/s"
+}
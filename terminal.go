@@ -0,0 +1,876 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =============================================================================
+// Terminal Emulator
+// =============================================================================
+
+// terminalRefreshInterval batches redraws while a process writes quickly.
+const terminalRefreshInterval = 30 * time.Millisecond
+
+// maxCSIParams bounds the parameter bytes of a control sequence, as xterm
+// bounds their number. A longer sequence is read up to its final byte and
+// ignored, so runaway output cannot grow the buffer.
+const maxCSIParams = 256
+
+// Parser states.
+const (
+	termGround = iota
+	termEscape
+	termCharset // ESC ( and friends: one designator byte follows
+	termCSI
+	termCSIIgnore // a CSI sequence past maxCSIParams
+	termOSC
+	termOSCEscape
+)
+
+type termStyle struct {
+	fg, bg        color.Color // nil means the theme default
+	bold, reverse bool
+}
+
+type termCell struct {
+	r     rune
+	style termStyle
+}
+
+// terminal is a small VT100/xterm emulator drawn on a TextGrid. It covers
+// what shells, editors and pagers commonly use: cursor movement, erasing,
+// insert/delete, scroll regions, the alternate screen and 16, 256 and
+// 24-bit colours. Output is fed in through Write; keystrokes go to the
+// writer set with SetInput.
+type terminal struct {
+	widget.BaseWidget
+	grid *widget.TextGrid
+
+	mu            sync.Mutex
+	rows, cols    int
+	cells         [][]termCell
+	mainScreen    [][]termCell // saved while the alternate screen is shown
+	row, col      int
+	wrapPending   bool
+	style         termStyle
+	saved         [2]int
+	savedStyle    termStyle
+	top, bottom   int // scroll region, inclusive
+	cursorVisible bool
+	appCursor     bool
+	focused       bool
+
+	state   int
+	params  []byte
+	partial []byte // incomplete UTF-8 sequence
+
+	input    io.Writer
+	onResize func(rows, cols int)
+	dirty    atomic.Bool
+	done     chan struct{}
+}
+
+func newTerminal() *terminal {
+	t := &terminal{
+		grid:          widget.NewTextGrid(),
+		cursorVisible: true,
+		done:          make(chan struct{}),
+	}
+	t.ExtendBaseWidget(t)
+	t.resizeBuffer(24, 80)
+	t.dirty.Store(true)
+
+	go func() {
+		ticker := time.NewTicker(terminalRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-ticker.C:
+				if t.dirty.Swap(false) {
+					t.redraw()
+				}
+			}
+		}
+	}()
+	return t
+}
+
+// SetInput sets where keystrokes are sent.
+func (t *terminal) SetInput(w io.Writer) {
+	t.mu.Lock()
+	t.input = w
+	t.mu.Unlock()
+}
+
+// SetOnResize sets a callback for size changes in cells.
+func (t *terminal) SetOnResize(f func(rows, cols int)) {
+	t.mu.Lock()
+	t.onResize = f
+	t.mu.Unlock()
+}
+
+// Dimensions returns the current size in cells.
+func (t *terminal) Dimensions() (rows, cols int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rows, t.cols
+}
+
+// Close stops the redraw loop.
+func (t *terminal) Close() {
+	close(t.done)
+}
+
+// -----------------------------------------------------------------------------
+// Widget
+// -----------------------------------------------------------------------------
+
+func (t *terminal) CreateRenderer() fyne.WidgetRenderer {
+	return &terminalRenderer{t: t}
+}
+
+type terminalRenderer struct {
+	t *terminal
+}
+
+func (r *terminalRenderer) Layout(size fyne.Size)        { r.t.grid.Resize(size) }
+func (r *terminalRenderer) Refresh()                     { r.t.grid.Refresh() }
+func (r *terminalRenderer) Objects() []fyne.CanvasObject { return []fyne.CanvasObject{r.t.grid} }
+func (r *terminalRenderer) Destroy()                     {}
+
+// MinSize is kept small so the window can shrink; the grid follows the size.
+func (r *terminalRenderer) MinSize() fyne.Size {
+	cell := terminalCellSize()
+	return fyne.NewSize(cell.Width*20, cell.Height*5)
+}
+
+func terminalCellSize() fyne.Size {
+	size := fyne.MeasureText("M", theme.TextSize(), fyne.TextStyle{Monospace: true})
+	return fyne.NewSize(float32(int(size.Width+0.5)), float32(int(size.Height+0.5)))
+}
+
+func (t *terminal) Resize(size fyne.Size) {
+	t.BaseWidget.Resize(size)
+	cell := terminalCellSize()
+	rows, cols := int(size.Height/cell.Height), int(size.Width/cell.Width)
+	if rows < 1 || cols < 1 {
+		return
+	}
+	t.mu.Lock()
+	changed := rows != t.rows || cols != t.cols
+	if changed {
+		t.resizeBuffer(rows, cols)
+	}
+	onResize := t.onResize
+	t.mu.Unlock()
+	if changed {
+		t.dirty.Store(true)
+		if onResize != nil {
+			onResize(rows, cols)
+		}
+	}
+}
+
+func (t *terminal) Tapped(*fyne.PointEvent) {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(t); c != nil {
+		c.Focus(t)
+	}
+}
+
+func (t *terminal) FocusGained() {
+	t.mu.Lock()
+	t.focused = true
+	t.mu.Unlock()
+	t.dirty.Store(true)
+}
+
+func (t *terminal) FocusLost() {
+	t.mu.Lock()
+	t.focused = false
+	t.mu.Unlock()
+	t.dirty.Store(true)
+}
+
+// AcceptsTab keeps Tab for completion instead of moving the focus.
+func (t *terminal) AcceptsTab() bool { return true }
+
+func (t *terminal) TypedRune(r rune) {
+	t.send(string(r))
+}
+
+func (t *terminal) TypedKey(ev *fyne.KeyEvent) {
+	t.mu.Lock()
+	app := t.appCursor
+	t.mu.Unlock()
+	cursor := func(final string) string {
+		if app {
+			return "\x1bO" + final
+		}
+		return "\x1b[" + final
+	}
+	switch ev.Name {
+	case fyne.KeyReturn, fyne.KeyEnter:
+		t.send("\r")
+	case fyne.KeyBackspace:
+		t.send("\x7f")
+	case fyne.KeyTab:
+		t.send("\t")
+	case fyne.KeyEscape:
+		t.send("\x1b")
+	case fyne.KeyUp:
+		t.send(cursor("A"))
+	case fyne.KeyDown:
+		t.send(cursor("B"))
+	case fyne.KeyRight:
+		t.send(cursor("C"))
+	case fyne.KeyLeft:
+		t.send(cursor("D"))
+	case fyne.KeyHome:
+		t.send(cursor("H"))
+	case fyne.KeyEnd:
+		t.send(cursor("F"))
+	case fyne.KeyInsert:
+		t.send("\x1b[2~")
+	case fyne.KeyDelete:
+		t.send("\x1b[3~")
+	case fyne.KeyPageUp:
+		t.send("\x1b[5~")
+	case fyne.KeyPageDown:
+		t.send("\x1b[6~")
+	case fyne.KeyF1:
+		t.send("\x1bOP")
+	case fyne.KeyF2:
+		t.send("\x1bOQ")
+	case fyne.KeyF3:
+		t.send("\x1bOR")
+	case fyne.KeyF4:
+		t.send("\x1bOS")
+	}
+}
+
+// TypedShortcut turns Ctrl+key into control characters. Ctrl+V pastes from
+// the clipboard.
+func (t *terminal) TypedShortcut(s fyne.Shortcut) {
+	switch s := s.(type) {
+	case *fyne.ShortcutPaste:
+		t.send(s.Clipboard.Content())
+	case *fyne.ShortcutCopy:
+		t.send("\x03")
+	case *fyne.ShortcutCut:
+		t.send("\x18")
+	case *fyne.ShortcutSelectAll:
+		t.send("\x01")
+	case *fyne.ShortcutUndo:
+		t.send("\x1a")
+	case *fyne.ShortcutRedo:
+		t.send("\x19")
+	case *desktop.CustomShortcut:
+		if s.Modifier&fyne.KeyModifierControl == 0 {
+			return
+		}
+		if b, ok := controlByte(s.KeyName); ok {
+			t.send(string([]byte{b}))
+		}
+	}
+}
+
+// controlByte maps a key pressed with Ctrl to its control character.
+func controlByte(key fyne.KeyName) (byte, bool) {
+	if len(key) == 1 && key[0] >= 'A' && key[0] <= 'Z' {
+		return key[0] - 'A' + 1, true
+	}
+	switch key {
+	case fyne.KeyLeftBracket:
+		return 0x1b, true
+	case fyne.KeyBackslash:
+		return 0x1c, true
+	case fyne.KeyRightBracket:
+		return 0x1d, true
+	case fyne.KeySpace:
+		return 0, true
+	}
+	return 0, false
+}
+
+func (t *terminal) send(s string) {
+	t.mu.Lock()
+	w := t.input
+	t.mu.Unlock()
+	if w == nil || s == "" {
+		return
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		t.dirty.Store(true)
+	}
+}
+
+// redraw copies the screen into the grid.
+func (t *terminal) redraw() {
+	t.mu.Lock()
+	rows := make([]widget.TextGridRow, t.rows)
+	for r, line := range t.cells {
+		cells := make([]widget.TextGridCell, len(line))
+		for c, cell := range line {
+			style := cell.style
+			if t.cursorVisible && r == t.row && c == t.col {
+				style.reverse = !style.reverse
+				if !t.focused {
+					// An unfocused terminal shows a dimmed cursor.
+					style.bg = themeColor(theme.ColorNameDisabled)
+					style.reverse = cell.style.reverse
+				}
+			}
+			cells[c] = widget.TextGridCell{Rune: cell.r, Style: gridStyle(style)}
+		}
+		rows[r] = widget.TextGridRow{Cells: cells}
+	}
+	t.mu.Unlock()
+	t.grid.Rows = rows
+	t.grid.Refresh()
+}
+
+func gridStyle(s termStyle) widget.TextGridStyle {
+	fg, bg := s.fg, s.bg
+	if s.reverse {
+		fg, bg = bg, fg
+		if fg == nil {
+			fg = themeColor(theme.ColorNameBackground)
+		}
+		if bg == nil {
+			bg = themeColor(theme.ColorNameForeground)
+		}
+	}
+	if fg == nil && bg == nil && !s.bold {
+		return nil
+	}
+	return &widget.CustomTextGridStyle{FGColor: fg, BGColor: bg, TextStyle: fyne.TextStyle{Bold: s.bold, Monospace: true}}
+}
+
+func themeColor(name fyne.ThemeColorName) color.Color {
+	settings := fyne.CurrentApp().Settings()
+	return settings.Theme().Color(name, settings.ThemeVariant())
+}
+
+// -----------------------------------------------------------------------------
+// Emulation
+// -----------------------------------------------------------------------------
+
+// Write feeds process output to the emulator.
+func (t *terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	data := p
+	if len(t.partial) > 0 {
+		data = append(t.partial, p...)
+		t.partial = nil
+	}
+	var replies []string
+	for len(data) > 0 {
+		b := data[0]
+		if t.state == termGround && b >= utf8.RuneSelf {
+			if !utf8.FullRune(data) {
+				t.partial = append([]byte(nil), data...)
+				break
+			}
+			r, size := utf8.DecodeRune(data)
+			t.put(r)
+			data = data[size:]
+			continue
+		}
+		if reply := t.feed(b); reply != "" {
+			replies = append(replies, reply)
+		}
+		data = data[1:]
+	}
+	input := t.input
+	t.mu.Unlock()
+	t.dirty.Store(true)
+
+	if input != nil {
+		for _, reply := range replies {
+			io.WriteString(input, reply)
+		}
+	}
+	return len(p), nil
+}
+
+// feed handles one byte outside a UTF-8 sequence and returns any reply the
+// process asked for.
+func (t *terminal) feed(b byte) string {
+	switch t.state {
+	case termEscape:
+		t.state = termGround
+		return t.escape(b)
+	case termCharset:
+		t.state = termGround
+		return ""
+	case termCSI:
+		switch {
+		case b >= 0x40 && b <= 0x7e:
+			t.state = termGround
+			return t.csi(b)
+		case b == 0x1b:
+			t.state = termEscape
+		case b == 0x18 || b == 0x1a:
+			t.state = termGround
+		case len(t.params) >= maxCSIParams:
+			t.state = termCSIIgnore
+		default:
+			t.params = append(t.params, b)
+		}
+		return ""
+	case termCSIIgnore:
+		switch {
+		case b >= 0x40 && b <= 0x7e, b == 0x18, b == 0x1a:
+			t.state = termGround
+		case b == 0x1b:
+			t.state = termEscape
+		}
+		return ""
+	case termOSC:
+		switch b {
+		case 0x07:
+			t.state = termGround
+		case 0x1b:
+			t.state = termOSCEscape
+		}
+		return ""
+	case termOSCEscape:
+		t.state = termGround
+		if b != '\\' {
+			return t.escape(b)
+		}
+		return ""
+	}
+
+	switch b {
+	case 0x1b:
+		t.state = termEscape
+	case '\r':
+		t.col, t.wrapPending = 0, false
+	case '\n', 0x0b, 0x0c:
+		t.lineFeed()
+	case '\b':
+		if t.col > 0 {
+			t.col--
+		}
+		t.wrapPending = false
+	case '\t':
+		t.col = min((t.col/8+1)*8, t.cols-1)
+		t.wrapPending = false
+	case 0x07, 0x0e, 0x0f, 0x00:
+	default:
+		if b >= 0x20 && b != 0x7f {
+			t.put(rune(b))
+		}
+	}
+	return ""
+}
+
+func (t *terminal) escape(b byte) string {
+	switch b {
+	case '[':
+		t.state = termCSI
+		t.params = t.params[:0]
+	case ']':
+		t.state = termOSC
+	case '(', ')', '*', '+', '#':
+		t.state = termCharset
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.col = 0
+		t.lineFeed()
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	}
+	return ""
+}
+
+func (t *terminal) csi(final byte) string {
+	raw := string(t.params)
+	private := ""
+	if raw != "" && strings.IndexByte("?>=", raw[0]) >= 0 {
+		private, raw = raw[:1], raw[1:]
+	}
+	var args []int
+	if raw != "" {
+		for _, field := range strings.Split(raw, ";") {
+			n, _ := strconv.Atoi(field)
+			args = append(args, n)
+		}
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	if private != "" {
+		switch final {
+		case 'h', 'l':
+			for _, mode := range args {
+				t.setPrivateMode(mode, final == 'h')
+			}
+		case 'c':
+			if private == ">" {
+				return "\x1b[>0;0;0c"
+			}
+		}
+		return ""
+	}
+
+	t.wrapPending = false
+	switch final {
+	case 'A':
+		t.row = max(t.row-arg(0, 1), 0)
+	case 'B':
+		t.row = min(t.row+arg(0, 1), t.rows-1)
+	case 'C':
+		t.col = min(t.col+arg(0, 1), t.cols-1)
+	case 'D':
+		t.col = max(t.col-arg(0, 1), 0)
+	case 'E':
+		t.row, t.col = min(t.row+arg(0, 1), t.rows-1), 0
+	case 'F':
+		t.row, t.col = max(t.row-arg(0, 1), 0), 0
+	case 'G', '`':
+		t.col = clamp(arg(0, 1)-1, 0, t.cols-1)
+	case 'd':
+		t.row = clamp(arg(0, 1)-1, 0, t.rows-1)
+	case 'H', 'f':
+		t.row = clamp(arg(0, 1)-1, 0, t.rows-1)
+		t.col = clamp(arg(1, 1)-1, 0, t.cols-1)
+	case 'J':
+		t.eraseDisplay(arg(0, 0))
+	case 'K':
+		t.eraseLine(arg(0, 0))
+	case 'L':
+		if t.row >= t.top && t.row <= t.bottom {
+			t.scrollDownFrom(t.row, arg(0, 1))
+		}
+	case 'M':
+		if t.row >= t.top && t.row <= t.bottom {
+			t.scrollUpFrom(t.row, arg(0, 1))
+		}
+	case '@':
+		n := min(arg(0, 1), t.cols-t.col)
+		line := t.cells[t.row]
+		copy(line[t.col+n:], line[t.col:])
+		t.blank(line[t.col : t.col+n])
+	case 'P':
+		n := min(arg(0, 1), t.cols-t.col)
+		line := t.cells[t.row]
+		copy(line[t.col:], line[t.col+n:])
+		t.blank(line[t.cols-n:])
+	case 'X':
+		n := min(arg(0, 1), t.cols-t.col)
+		t.blank(t.cells[t.row][t.col : t.col+n])
+	case 'S':
+		t.scrollUpFrom(t.top, arg(0, 1))
+	case 'T':
+		t.scrollDownFrom(t.top, arg(0, 1))
+	case 'm':
+		t.sgr(args)
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, t.rows)-1
+		if top < bottom && bottom < t.rows {
+			t.top, t.bottom = top, bottom
+			t.row, t.col = 0, 0
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	case 'n':
+		switch arg(0, 0) {
+		case 5:
+			return "\x1b[0n"
+		case 6:
+			return fmt.Sprintf("\x1b[%d;%dR", t.row+1, t.col+1)
+		}
+	case 'c':
+		return "\x1b[?1;2c"
+	}
+	return ""
+}
+
+func (t *terminal) setPrivateMode(mode int, on bool) {
+	switch mode {
+	case 1:
+		t.appCursor = on
+	case 25:
+		t.cursorVisible = on
+	case 47, 1047, 1049:
+		if mode == 1049 && on {
+			t.saveCursor()
+		}
+		if on && t.mainScreen == nil {
+			t.mainScreen = t.cells
+			t.cells = t.blankScreen(t.rows, t.cols)
+		} else if !on && t.mainScreen != nil {
+			t.cells = t.mainScreen
+			t.mainScreen = nil
+		}
+		if mode == 1049 && !on {
+			t.restoreCursor()
+		}
+	}
+}
+
+func (t *terminal) sgr(args []int) {
+	if len(args) == 0 {
+		args = []int{0}
+	}
+	for i := 0; i < len(args); i++ {
+		switch n := args[i]; {
+		case n == 0:
+			t.style = termStyle{}
+		case n == 1:
+			t.style.bold = true
+		case n == 22:
+			t.style.bold = false
+		case n == 7:
+			t.style.reverse = true
+		case n == 27:
+			t.style.reverse = false
+		case n >= 30 && n <= 37:
+			t.style.fg = ansiColor(n - 30)
+		case n >= 90 && n <= 97:
+			t.style.fg = ansiColor(n - 90 + 8)
+		case n == 39:
+			t.style.fg = nil
+		case n >= 40 && n <= 47:
+			t.style.bg = ansiColor(n - 40)
+		case n >= 100 && n <= 107:
+			t.style.bg = ansiColor(n - 100 + 8)
+		case n == 49:
+			t.style.bg = nil
+		case n == 38 || n == 48:
+			c, used := extendedColor(args[i+1:])
+			i += used
+			if c != nil {
+				if n == 38 {
+					t.style.fg = c
+				} else {
+					t.style.bg = c
+				}
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments after 38 or 48: "5;n" or "2;r;g;b".
+func extendedColor(args []int) (color.Color, int) {
+	if len(args) >= 2 && args[0] == 5 {
+		return ansiColor(args[1]), 2
+	}
+	if len(args) >= 4 && args[0] == 2 {
+		return color.RGBA{uint8(args[1]), uint8(args[2]), uint8(args[3]), 255}, 4
+	}
+	return nil, len(args)
+}
+
+var ansiPalette = [16]color.RGBA{
+	{0, 0, 0, 255}, {205, 49, 49, 255}, {13, 188, 121, 255}, {229, 229, 16, 255},
+	{36, 114, 200, 255}, {188, 63, 188, 255}, {17, 168, 205, 255}, {229, 229, 229, 255},
+	{102, 102, 102, 255}, {241, 76, 76, 255}, {35, 209, 139, 255}, {245, 245, 67, 255},
+	{59, 142, 234, 255}, {214, 112, 214, 255}, {41, 184, 219, 255}, {255, 255, 255, 255},
+}
+
+// ansiColor returns colour n of the xterm 256-colour palette.
+func ansiColor(n int) color.Color {
+	switch {
+	case n < 0 || n > 255:
+		return nil
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 255}
+	default:
+		v := uint8(8 + (n-232)*10)
+		return color.RGBA{v, v, v, 255}
+	}
+}
+
+func (t *terminal) put(r rune) {
+	if t.wrapPending {
+		t.col = 0
+		t.lineFeed()
+	}
+	t.cells[t.row][t.col] = termCell{r: r, style: t.style}
+	if t.col == t.cols-1 {
+		t.wrapPending = true
+	} else {
+		t.col++
+	}
+}
+
+func (t *terminal) lineFeed() {
+	t.wrapPending = false
+	if t.row == t.bottom {
+		t.scrollUpFrom(t.top, 1)
+	} else if t.row < t.rows-1 {
+		t.row++
+	}
+}
+
+func (t *terminal) reverseIndex() {
+	t.wrapPending = false
+	if t.row == t.top {
+		t.scrollDownFrom(t.top, 1)
+	} else if t.row > 0 {
+		t.row--
+	}
+}
+
+// scrollUpFrom moves the lines from row to the bottom of the scroll region
+// up by n, blanking the lines that appear at the bottom.
+func (t *terminal) scrollUpFrom(row, n int) {
+	n = min(n, t.bottom-row+1)
+	region := t.cells[row : t.bottom+1]
+	removed := append([][]termCell(nil), region[:n]...)
+	copy(region, region[n:])
+	for i, line := range removed {
+		t.blank(line)
+		region[len(region)-n+i] = line
+	}
+}
+
+// scrollDownFrom moves the lines from row to the bottom of the scroll region
+// down by n, blanking the lines that open up at row.
+func (t *terminal) scrollDownFrom(row, n int) {
+	n = min(n, t.bottom-row+1)
+	region := t.cells[row : t.bottom+1]
+	removed := append([][]termCell(nil), region[len(region)-n:]...)
+	copy(region[n:], region)
+	for i, line := range removed {
+		t.blank(line)
+		region[i] = line
+	}
+}
+
+func (t *terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.blank(t.cells[t.row][t.col:])
+		for _, line := range t.cells[t.row+1:] {
+			t.blank(line)
+		}
+	case 1:
+		for _, line := range t.cells[:t.row] {
+			t.blank(line)
+		}
+		t.blank(t.cells[t.row][:t.col+1])
+	default:
+		for _, line := range t.cells {
+			t.blank(line)
+		}
+	}
+}
+
+func (t *terminal) eraseLine(mode int) {
+	line := t.cells[t.row]
+	switch mode {
+	case 0:
+		t.blank(line[t.col:])
+	case 1:
+		t.blank(line[:t.col+1])
+	default:
+		t.blank(line)
+	}
+}
+
+// blank clears cells, keeping the current background colour as xterm does.
+func (t *terminal) blank(cells []termCell) {
+	for i := range cells {
+		cells[i] = termCell{r: ' ', style: termStyle{bg: t.style.bg}}
+	}
+}
+
+func (t *terminal) blankScreen(rows, cols int) [][]termCell {
+	screen := make([][]termCell, rows)
+	for r := range screen {
+		screen[r] = make([]termCell, cols)
+		for c := range screen[r] {
+			screen[r][c] = termCell{r: ' '}
+		}
+	}
+	return screen
+}
+
+func (t *terminal) saveCursor() {
+	t.saved = [2]int{t.row, t.col}
+	t.savedStyle = t.style
+}
+
+func (t *terminal) restoreCursor() {
+	t.row = clamp(t.saved[0], 0, t.rows-1)
+	t.col = clamp(t.saved[1], 0, t.cols-1)
+	t.style = t.savedStyle
+	t.wrapPending = false
+}
+
+func (t *terminal) reset() {
+	t.cells = t.blankScreen(t.rows, t.cols)
+	t.mainScreen = nil
+	t.row, t.col, t.wrapPending = 0, 0, false
+	t.style = termStyle{}
+	t.top, t.bottom = 0, t.rows-1
+	t.cursorVisible, t.appCursor = true, false
+}
+
+// resizeBuffer changes the screen size, keeping the text around the cursor.
+// The caller holds t.mu (or is the constructor).
+func (t *terminal) resizeBuffer(rows, cols int) {
+	resize := func(old [][]termCell, keepFrom int) [][]termCell {
+		screen := t.blankScreen(rows, cols)
+		for r := 0; r < rows && keepFrom+r < len(old); r++ {
+			copy(screen[r], old[keepFrom+r])
+		}
+		return screen
+	}
+	// Drop lines from the top if the cursor would fall off the bottom.
+	drop := max(t.row-rows+1, 0)
+	t.cells = resize(t.cells, drop)
+	if t.mainScreen != nil {
+		t.mainScreen = resize(t.mainScreen, drop)
+	}
+	t.rows, t.cols = rows, cols
+	t.row = clamp(t.row-drop, 0, rows-1)
+	t.col = clamp(t.col, 0, cols-1)
+	t.top, t.bottom = 0, rows-1
+	t.wrapPending = false
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
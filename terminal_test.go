@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// newTestTerminal returns a rows x cols terminal fed with input.
+func newTestTerminal(t *testing.T, rows, cols int, input string) *terminal {
+	t.Helper()
+	term := newTerminal()
+	t.Cleanup(term.Close)
+	term.mu.Lock()
+	term.resizeBuffer(rows, cols)
+	term.mu.Unlock()
+	term.Write([]byte(input))
+	return term
+}
+
+// screenLines returns the screen's text with trailing blanks and blank
+// lines removed.
+func screenLines(term *terminal) []string {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	lines := make([]string, len(term.cells))
+	for r, line := range term.cells {
+		var b strings.Builder
+		for _, cell := range line {
+			b.WriteRune(cell.r)
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func TestTerminalScreen(t *testing.T) {
+	const lines4 = "a\r\nb\r\nc\r\nd"
+	tests := []struct {
+		name       string
+		rows, cols int
+		input      string
+		want       []string
+		row, col   int
+	}{
+		{"text", 5, 10, "ab\r\ncd", []string{"ab", "cd"}, 1, 2},
+		{"wrap", 5, 3, "abcd", []string{"abc", "d"}, 1, 1},
+		{"pending wrap at the margin", 5, 3, "abc", []string{"abc"}, 0, 2},
+		{"scroll at the bottom", 3, 5, "1\r\n2\r\n3\r\n4", []string{"2", "3", "4"}, 2, 1},
+		{"tab", 5, 20, "a\tb", []string{"a       b"}, 0, 9},
+		{"utf-8", 5, 10, "h\xc3\xa9\xe2\x94\x80", []string{"hé─"}, 0, 3},
+
+		// Cursor movement.
+		{"cup", 5, 10, "\x1b[3;5HX", []string{"", "", "    X"}, 2, 5},
+		{"cup default", 5, 10, "ab\x1b[HX", []string{"Xb"}, 0, 1},
+		{"cuu cud cuf cub", 5, 10, "\x1b[3;5HX\x1b[AY\x1b[2DZ\x1b[2BW\x1b[3CV", []string{"", "    ZY", "    X", "     W   V"}, 3, 9},
+		{"moves stop at the edges", 5, 10, "\x1b[99A\x1b[99DA\x1b[99B\x1b[99CB", []string{"A", "", "", "", "         B"}, 4, 9},
+		{"cha vpa", 5, 10, "\x1b[4GA\x1b[3dB", []string{"   A", "", "    B"}, 2, 5},
+		{"cnl cpl", 5, 10, "ab\x1b[2EX\x1b[FY", []string{"ab", "Y", "X"}, 1, 1},
+		{"save restore", 5, 10, "ab\x1b7\x1b[4;4HX\x1b8Y", []string{"abY", "", "", "   X"}, 0, 3},
+		{"cr bs", 5, 10, "abc\rX\bY", []string{"Ybc"}, 0, 1},
+
+		// Erasing.
+		{"el to end", 5, 10, "abcdef\x1b[3D\x1b[K", []string{"abc"}, 0, 3},
+		{"el to start", 5, 10, "abcdef\x1b[3D\x1b[1K", []string{"    ef"}, 0, 3},
+		{"el line", 5, 10, "abc\r\ndef\x1b[A\x1b[2K", []string{"", "def"}, 0, 3},
+		{"ed to end", 5, 10, "ab\r\ncd\r\nef\x1b[2;2H\x1b[J", []string{"ab", "c"}, 1, 1},
+		{"ed to start", 5, 10, "ab\r\ncd\r\nef\x1b[2;1H\x1b[1J", []string{"", " d", "ef"}, 1, 0},
+		{"ed all", 5, 10, "ab\r\ncd\x1b[2J", nil, 1, 2},
+		{"ech", 5, 10, "abcdef\x1b[2G\x1b[3X", []string{"a   ef"}, 0, 1},
+		{"ich", 5, 10, "abc\x1b[1G\x1b[2@", []string{"  abc"}, 0, 0},
+		{"dch", 5, 10, "abcde\x1b[2G\x1b[2P", []string{"ade"}, 0, 1},
+
+		// Scroll regions.
+		{"lf at the region bottom", 4, 5, lines4 + "\x1b[2;3r\x1b[3;1H\nX", []string{"a", "c", "X", "d"}, 2, 1},
+		{"ri at the region top", 4, 5, lines4 + "\x1b[2;3r\x1b[2;1H\x1bM", []string{"a", "", "b", "d"}, 1, 0},
+		{"region homes the cursor", 4, 5, lines4 + "\x1b[2;3rX", []string{"X", "b", "c", "d"}, 0, 1},
+		{"su sd", 4, 5, lines4 + "\x1b[2;3r\x1b[S", []string{"a", "c", "", "d"}, 0, 0},
+		{"il", 4, 5, lines4 + "\x1b[1;3r\x1b[2;1H\x1b[L", []string{"a", "", "b", "d"}, 1, 0},
+		{"dl", 4, 5, lines4 + "\x1b[1;3r\x1b[1;1H\x1b[M", []string{"b", "c", "", "d"}, 0, 0},
+		{"invalid region is ignored", 4, 5, lines4 + "\x1b[3;2r\n", []string{"b", "c", "d"}, 3, 1},
+		{"reset", 4, 5, lines4 + "\x1b[2;3r\x1b[31m\x1bc\n\n\n\nX", []string{"", "", "", "X"}, 3, 1},
+
+		// The alternate screen.
+		{"alt screen", 5, 10, "main\x1b[?1049h\x1b[HALT", []string{"ALT"}, 0, 3},
+		{"alt screen restores", 5, 10, "main\x1b[?1049h\x1b[HALT\r\nmore\x1b[?1049lX", []string{"mainX"}, 0, 5},
+		{"alt screen 47", 5, 10, "main\x1b[?47hALT\x1b[?47l", []string{"main"}, 0, 7},
+
+		// Malformed and oversized sequences.
+		{"cancelled sequence", 5, 10, "a\x1b[3\x18b", []string{"ab"}, 0, 2},
+		{"osc title", 5, 10, "\x1b]0;title\x07a\x1b]2;t\x1b\\b", []string{"ab"}, 0, 2},
+		{"oversized params", 5, 10, "\x1b[" + strings.Repeat("1;", 1000) + "HX\x1b[2;2HY", []string{"X", " Y"}, 1, 2},
+	}
+	for _, tt := range tests {
+		term := newTestTerminal(t, tt.rows, tt.cols, tt.input)
+		if got := screenLines(term); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: screen = %q, want %q", tt.name, got, tt.want)
+		}
+		if term.row != tt.row || term.col != tt.col {
+			t.Errorf("%s: cursor = %d,%d, want %d,%d", tt.name, term.row, term.col, tt.row, tt.col)
+		}
+		if len(term.params) > maxCSIParams {
+			t.Errorf("%s: %d parameter bytes kept", tt.name, len(term.params))
+		}
+	}
+}
+
+func TestTerminalSGR(t *testing.T) {
+	red := ansiPalette[1]
+	tests := []struct {
+		input  string
+		fg, bg color.Color
+		bold   bool
+	}{
+		{"X", nil, nil, false},
+		{"\x1b[1;31mX", red, nil, true},
+		{"\x1b[91;44mX", ansiPalette[9], ansiPalette[4], false},
+		{"\x1b[102mX", nil, ansiPalette[10], false},
+		{"\x1b[1;31;42m\x1b[mX", nil, nil, false},
+		{"\x1b[1;31;42m\x1b[22;39mX", nil, ansiPalette[2], false},
+		{"\x1b[41m\x1b[49mX", nil, nil, false},
+		// 256 colours: the 16 base colours, the 6x6x6 cube and the greys.
+		{"\x1b[38;5;9mX", ansiPalette[9], nil, false},
+		{"\x1b[38;5;196mX", color.RGBA{255, 0, 0, 255}, nil, false},
+		{"\x1b[38;5;20mX", color.RGBA{0, 0, 215, 255}, nil, false},
+		{"\x1b[48;5;110mX", nil, color.RGBA{135, 175, 215, 255}, false},
+		{"\x1b[38;5;232mX", color.RGBA{8, 8, 8, 255}, nil, false},
+		{"\x1b[48;5;255mX", nil, color.RGBA{238, 238, 238, 255}, false},
+		{"\x1b[38;5;256mX", nil, nil, false},
+		// Truecolor, and attributes following it.
+		{"\x1b[38;2;1;2;3mX", color.RGBA{1, 2, 3, 255}, nil, false},
+		{"\x1b[38;2;1;2;3;48;2;250;251;252;1mX", color.RGBA{1, 2, 3, 255}, color.RGBA{250, 251, 252, 255}, true},
+		{"\x1b[38;5;1;1mX", red, nil, true},
+		// Incomplete extended colours are ignored.
+		{"\x1b[38;2;1mX", nil, nil, false},
+		{"\x1b[38mX", nil, nil, false},
+	}
+	for _, tt := range tests {
+		term := newTestTerminal(t, 2, 5, tt.input)
+		style := term.cells[0][0].style
+		if style.fg != tt.fg || style.bg != tt.bg || style.bold != tt.bold {
+			t.Errorf("%q: style = %+v, want fg %v bg %v bold %v", tt.input, style, tt.fg, tt.bg, tt.bold)
+		}
+	}
+
+	// Erasing keeps the current background, as xterm does.
+	term := newTestTerminal(t, 2, 5, "\x1b[44m\x1b[2J")
+	if bg := term.cells[1][4].style.bg; bg != ansiPalette[4] {
+		t.Errorf("erased cell background = %v", bg)
+	}
+	// Reverse video is kept as an attribute and applied when drawn.
+	term = newTestTerminal(t, 2, 5, "\x1b[7mX\x1b[27mY")
+	if !term.cells[0][0].style.reverse || term.cells[0][1].style.reverse {
+		t.Error("reverse video is not set and cleared")
+	}
+}
+
+func TestTerminalResize(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		rows, cols int
+		after      string // fed after the resize
+		want       []string
+		row, col   int
+	}{
+		// Lines drop off the top to keep the cursor on screen.
+		{"shrink", "1\r\n2\r\n3\r\n4\r\n5", 3, 10, "", []string{"3", "4", "5"}, 2, 1},
+		{"shrink above the cursor", "1\r\n2\x1b[H", 3, 10, "", []string{"1", "2"}, 0, 0},
+		{"narrow", "abcdefgh", 5, 4, "", []string{"abcd"}, 0, 3},
+		{"grow", "ab\r\ncd", 8, 20, "", []string{"ab", "cd"}, 1, 2},
+		// The scroll region is reset to the new screen.
+		{"region", "\x1b[2;3r", 4, 10, "", nil, 0, 0},
+		// Both screens follow the size.
+		{"alt screen", "1\r\n2\r\n3\r\n4\r\n5\x1b[?1049h\x1b[HALT\r\n\r\n\r\n\r\nX", 3, 10, "", []string{"", "", "X"}, 2, 1},
+		{"alt screen left", "1\r\n2\r\n3\r\n4\r\n5\x1b[?1049hALT\x1b[H\r\n\r\n\r\n\r\n", 3, 10, "\x1b[?1049l", []string{"3", "4", "5"}, 2, 1},
+	}
+	for _, tt := range tests {
+		term := newTestTerminal(t, 5, 10, tt.input)
+		term.mu.Lock()
+		term.resizeBuffer(tt.rows, tt.cols)
+		term.mu.Unlock()
+		term.Write([]byte(tt.after))
+		if got := screenLines(term); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: screen = %q, want %q", tt.name, got, tt.want)
+		}
+		if term.row != tt.row || term.col != tt.col {
+			t.Errorf("%s: cursor = %d,%d, want %d,%d", tt.name, term.row, term.col, tt.row, tt.col)
+		}
+		if term.top != 0 || term.bottom != tt.rows-1 {
+			t.Errorf("%s: scroll region = %d-%d", tt.name, term.top, term.bottom)
+		}
+		// Output after the resize scrolls the whole new screen.
+		term.Write([]byte(strings.Repeat("\n", tt.rows)))
+		if term.row != tt.rows-1 {
+			t.Errorf("%s: cursor row after scrolling = %d", tt.name, term.row)
+		}
+	}
+}
+
+func TestTerminalReplies(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"\x1b[2;3H\x1b[6n", "\x1b[2;3R"},
+		{"\x1b[5n", "\x1b[0n"},
+		{"\x1b[c", "\x1b[?1;2c"},
+		{"\x1b[>c", "\x1b[>0;0;0c"},
+		{"\x1b[31m", ""},
+	}
+	for _, tt := range tests {
+		term := newTestTerminal(t, 5, 10, "")
+		var out bytes.Buffer
+		term.SetInput(&out)
+		term.Write([]byte(tt.input))
+		if out.String() != tt.want {
+			t.Errorf("%q: reply %q, want %q", tt.input, out.String(), tt.want)
+		}
+	}
+}